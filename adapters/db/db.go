@@ -1,9 +1,13 @@
 package db
 
 import (
+	"errors"
+	"fmt"
 	"log"
 
 	"github.com/charmbracelet/charm/kv"
+	"github.com/dgraph-io/badger/v3"
+	"github.com/rogerscuall/aws-router/ports"
 )
 
 type Adapter struct {
@@ -28,8 +32,12 @@ func (da Adapter) CloseDbConnection() {
 	}
 }
 
+// GetVal returns the value of key, the error wraps ports.ErrKeyNotFound if key is not stored.
 func (da Adapter) GetVal(key string) ([]byte, error) {
 	val, err := da.db.Get([]byte(key))
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return []byte{}, fmt.Errorf("%w: %s", ports.ErrKeyNotFound, key)
+	}
 	if err != nil {
 		return []byte{}, err
 	}
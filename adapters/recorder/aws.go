@@ -0,0 +1,192 @@
+package recorder
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/directconnect"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// The methods of ports.AWSRouter for the Recorder and the Replayer.
+
+func (r *Recorder) DescribeTransitGateways(ctx context.Context, params *ec2.DescribeTransitGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewaysOutput, error) {
+	output, err := r.api.DescribeTransitGateways(ctx, params, optFns...)
+	r.record("DescribeTransitGateways", params, output, err)
+	return output, err
+}
+
+func (r *Recorder) DescribeTransitGatewayRouteTables(ctx context.Context, params *ec2.DescribeTransitGatewayRouteTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayRouteTablesOutput, error) {
+	output, err := r.api.DescribeTransitGatewayRouteTables(ctx, params, optFns...)
+	r.record("DescribeTransitGatewayRouteTables", params, output, err)
+	return output, err
+}
+
+func (r *Recorder) SearchTransitGatewayRoutes(ctx context.Context, params *ec2.SearchTransitGatewayRoutesInput, optFns ...func(*ec2.Options)) (*ec2.SearchTransitGatewayRoutesOutput, error) {
+	output, err := r.api.SearchTransitGatewayRoutes(ctx, params, optFns...)
+	r.record("SearchTransitGatewayRoutes", params, output, err)
+	return output, err
+}
+
+func (r *Recorder) GetTransitGatewayRouteTableAssociations(ctx context.Context, params *ec2.GetTransitGatewayRouteTableAssociationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayRouteTableAssociationsOutput, error) {
+	output, err := r.api.GetTransitGatewayRouteTableAssociations(ctx, params, optFns...)
+	r.record("GetTransitGatewayRouteTableAssociations", params, output, err)
+	return output, err
+}
+
+func (r *Recorder) DescribeTransitGatewayAttachments(ctx context.Context, params *ec2.DescribeTransitGatewayAttachmentsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayAttachmentsOutput, error) {
+	output, err := r.api.DescribeTransitGatewayAttachments(ctx, params, optFns...)
+	r.record("DescribeTransitGatewayAttachments", params, output, err)
+	return output, err
+}
+
+func (r *Recorder) GetTransitGatewayRouteTablePropagations(ctx context.Context, params *ec2.GetTransitGatewayRouteTablePropagationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayRouteTablePropagationsOutput, error) {
+	output, err := r.api.GetTransitGatewayRouteTablePropagations(ctx, params, optFns...)
+	r.record("GetTransitGatewayRouteTablePropagations", params, output, err)
+	return output, err
+}
+
+func (r *Recorder) DescribeManagedPrefixLists(ctx context.Context, params *ec2.DescribeManagedPrefixListsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeManagedPrefixListsOutput, error) {
+	output, err := r.api.DescribeManagedPrefixLists(ctx, params, optFns...)
+	r.record("DescribeManagedPrefixLists", params, output, err)
+	return output, err
+}
+
+func (r *Recorder) DescribeVpnConnections(ctx context.Context, params *ec2.DescribeVpnConnectionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpnConnectionsOutput, error) {
+	output, err := r.api.DescribeVpnConnections(ctx, params, optFns...)
+	r.record("DescribeVpnConnections", params, output, err)
+	return output, err
+}
+
+func (r *Recorder) DescribeDirectConnectGatewayAssociations(ctx context.Context, params *directconnect.DescribeDirectConnectGatewayAssociationsInput, optFns ...func(*directconnect.Options)) (*directconnect.DescribeDirectConnectGatewayAssociationsOutput, error) {
+	output, err := r.api.DescribeDirectConnectGatewayAssociations(ctx, params, optFns...)
+	r.record("DescribeDirectConnectGatewayAssociations", params, output, err)
+	return output, err
+}
+
+func (r *Recorder) DescribeTransitGatewayConnects(ctx context.Context, params *ec2.DescribeTransitGatewayConnectsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayConnectsOutput, error) {
+	output, err := r.api.DescribeTransitGatewayConnects(ctx, params, optFns...)
+	r.record("DescribeTransitGatewayConnects", params, output, err)
+	return output, err
+}
+
+func (r *Recorder) DescribeTransitGatewayConnectPeers(ctx context.Context, params *ec2.DescribeTransitGatewayConnectPeersInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayConnectPeersOutput, error) {
+	output, err := r.api.DescribeTransitGatewayConnectPeers(ctx, params, optFns...)
+	r.record("DescribeTransitGatewayConnectPeers", params, output, err)
+	return output, err
+}
+
+func (r *Recorder) DescribeTransitGatewayPolicyTables(ctx context.Context, params *ec2.DescribeTransitGatewayPolicyTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayPolicyTablesOutput, error) {
+	output, err := r.api.DescribeTransitGatewayPolicyTables(ctx, params, optFns...)
+	r.record("DescribeTransitGatewayPolicyTables", params, output, err)
+	return output, err
+}
+
+func (r *Recorder) GetTransitGatewayPolicyTableAssociations(ctx context.Context, params *ec2.GetTransitGatewayPolicyTableAssociationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayPolicyTableAssociationsOutput, error) {
+	output, err := r.api.GetTransitGatewayPolicyTableAssociations(ctx, params, optFns...)
+	r.record("GetTransitGatewayPolicyTableAssociations", params, output, err)
+	return output, err
+}
+
+func (r *Replayer) DescribeTransitGateways(ctx context.Context, params *ec2.DescribeTransitGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewaysOutput, error) {
+	output := &ec2.DescribeTransitGatewaysOutput{}
+	if err := r.replay("DescribeTransitGateways", params, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (r *Replayer) DescribeTransitGatewayRouteTables(ctx context.Context, params *ec2.DescribeTransitGatewayRouteTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayRouteTablesOutput, error) {
+	output := &ec2.DescribeTransitGatewayRouteTablesOutput{}
+	if err := r.replay("DescribeTransitGatewayRouteTables", params, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (r *Replayer) SearchTransitGatewayRoutes(ctx context.Context, params *ec2.SearchTransitGatewayRoutesInput, optFns ...func(*ec2.Options)) (*ec2.SearchTransitGatewayRoutesOutput, error) {
+	output := &ec2.SearchTransitGatewayRoutesOutput{}
+	if err := r.replay("SearchTransitGatewayRoutes", params, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (r *Replayer) GetTransitGatewayRouteTableAssociations(ctx context.Context, params *ec2.GetTransitGatewayRouteTableAssociationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayRouteTableAssociationsOutput, error) {
+	output := &ec2.GetTransitGatewayRouteTableAssociationsOutput{}
+	if err := r.replay("GetTransitGatewayRouteTableAssociations", params, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (r *Replayer) DescribeTransitGatewayAttachments(ctx context.Context, params *ec2.DescribeTransitGatewayAttachmentsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayAttachmentsOutput, error) {
+	output := &ec2.DescribeTransitGatewayAttachmentsOutput{}
+	if err := r.replay("DescribeTransitGatewayAttachments", params, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (r *Replayer) GetTransitGatewayRouteTablePropagations(ctx context.Context, params *ec2.GetTransitGatewayRouteTablePropagationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayRouteTablePropagationsOutput, error) {
+	output := &ec2.GetTransitGatewayRouteTablePropagationsOutput{}
+	if err := r.replay("GetTransitGatewayRouteTablePropagations", params, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (r *Replayer) DescribeManagedPrefixLists(ctx context.Context, params *ec2.DescribeManagedPrefixListsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeManagedPrefixListsOutput, error) {
+	output := &ec2.DescribeManagedPrefixListsOutput{}
+	if err := r.replay("DescribeManagedPrefixLists", params, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (r *Replayer) DescribeVpnConnections(ctx context.Context, params *ec2.DescribeVpnConnectionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpnConnectionsOutput, error) {
+	output := &ec2.DescribeVpnConnectionsOutput{}
+	if err := r.replay("DescribeVpnConnections", params, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (r *Replayer) DescribeDirectConnectGatewayAssociations(ctx context.Context, params *directconnect.DescribeDirectConnectGatewayAssociationsInput, optFns ...func(*directconnect.Options)) (*directconnect.DescribeDirectConnectGatewayAssociationsOutput, error) {
+	output := &directconnect.DescribeDirectConnectGatewayAssociationsOutput{}
+	if err := r.replay("DescribeDirectConnectGatewayAssociations", params, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (r *Replayer) DescribeTransitGatewayConnects(ctx context.Context, params *ec2.DescribeTransitGatewayConnectsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayConnectsOutput, error) {
+	output := &ec2.DescribeTransitGatewayConnectsOutput{}
+	if err := r.replay("DescribeTransitGatewayConnects", params, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (r *Replayer) DescribeTransitGatewayConnectPeers(ctx context.Context, params *ec2.DescribeTransitGatewayConnectPeersInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayConnectPeersOutput, error) {
+	output := &ec2.DescribeTransitGatewayConnectPeersOutput{}
+	if err := r.replay("DescribeTransitGatewayConnectPeers", params, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (r *Replayer) DescribeTransitGatewayPolicyTables(ctx context.Context, params *ec2.DescribeTransitGatewayPolicyTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayPolicyTablesOutput, error) {
+	output := &ec2.DescribeTransitGatewayPolicyTablesOutput{}
+	if err := r.replay("DescribeTransitGatewayPolicyTables", params, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (r *Replayer) GetTransitGatewayPolicyTableAssociations(ctx context.Context, params *ec2.GetTransitGatewayPolicyTableAssociationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayPolicyTableAssociationsOutput, error) {
+	output := &ec2.GetTransitGatewayPolicyTableAssociationsOutput{}
+	if err := r.replay("GetTransitGatewayPolicyTableAssociations", params, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
@@ -0,0 +1,136 @@
+/*
+Package recorder records the responses of the AWS API to a fixture file and replays them.
+
+A Recorder is a ports.AWSRouter that calls the real API and keeps every request and response,
+a Replayer is a ports.AWSRouter that answers from a fixture without calling AWS.
+A fixture recorded with a Scrubber has the account IDs and IP addresses replaced, so it can be shared in a bug report.
+*/
+package recorder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/rogerscuall/aws-router/ports"
+)
+
+// ErrNoRecording is returned by a Replayer for a request that is not in the fixture.
+var ErrNoRecording = errors.New("recorder: no recorded response for the request")
+
+// Interaction is a request to the AWS API and its response.
+// Error is the message of the error returned by the API, Output is empty in that case.
+type Interaction struct {
+	Operation string          `json:"operation"`
+	Input     json.RawMessage `json:"input"`
+	Output    json.RawMessage `json:"output,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// Fixture is the content of a fixture file.
+type Fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// ReadFixture reads a fixture written by Recorder.Write.
+func ReadFixture(r io.Reader) (*Fixture, error) {
+	var f Fixture
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("error reading the fixture: %w", err)
+	}
+	return &f, nil
+}
+
+// Recorder calls api and records every request and response, it is safe to use from multiple goroutines.
+type Recorder struct {
+	api   ports.AWSRouter
+	scrub *Scrubber
+
+	mu           sync.Mutex
+	interactions []Interaction
+	err          error
+}
+
+// NewRecorder returns a Recorder of the calls to api. If scrub is not nil the recorded requests and responses are scrubbed,
+// the responses returned to the caller are not.
+func NewRecorder(api ports.AWSRouter, scrub *Scrubber) *Recorder {
+	return &Recorder{api: api, scrub: scrub}
+}
+
+// record keeps a request and its response.
+func (r *Recorder) record(operation string, input, output interface{}, callErr error) {
+	in, err := json.Marshal(input)
+	if err != nil {
+		r.setErr(fmt.Errorf("error recording the input of %s: %w", operation, err))
+		return
+	}
+	i := Interaction{Operation: operation, Input: r.scrubbed(in)}
+	if callErr != nil {
+		i.Error = string(r.scrubbed([]byte(callErr.Error())))
+	} else {
+		out, err := json.Marshal(output)
+		if err != nil {
+			r.setErr(fmt.Errorf("error recording the output of %s: %w", operation, err))
+			return
+		}
+		i.Output = r.scrubbed(out)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, i)
+}
+
+func (r *Recorder) scrubbed(b []byte) []byte {
+	if r.scrub == nil {
+		return b
+	}
+	return r.scrub.Scrub(b)
+}
+
+func (r *Recorder) setErr(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// Write writes the recorded interactions as a fixture in w.
+// The interactions are sorted by operation and request, the responses to the same request keep the order of the calls,
+// so the same calls always produce the same file.
+func (r *Recorder) Write(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	f := Fixture{Interactions: make([]Interaction, len(r.interactions))}
+	copy(f.Interactions, r.interactions)
+	sort.SliceStable(f.Interactions, func(i, j int) bool {
+		a, b := f.Interactions[i], f.Interactions[j]
+		if a.Operation != b.Operation {
+			return a.Operation < b.Operation
+		}
+		return string(a.Input) < string(b.Input)
+	})
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(f)
+}
+
+// Save writes the recorded interactions as a fixture in the file fileName.
+func (r *Recorder) Save(fileName string) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating the fixture: %w", err)
+	}
+	if err := r.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,245 @@
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/go-cmp/cmp"
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/internal/application"
+	"github.com/rogerscuall/aws-router/internal/output"
+	"github.com/rogerscuall/aws-router/ports"
+)
+
+// upstream returns a Replayer of testdata/upstream.json, a recording without scrubbing of one TGW owned by
+// the account 123456789012, with two route tables, IPv4 and IPv6 routes, and two VPC attachments. It is the AWS API of the tests
+// that record: the attachments tgw-attach-0a and tgw-attach-0b described together are throttled the first time.
+func upstream(t *testing.T) *Replayer {
+	t.Helper()
+	replayer, err := LoadReplayer("testdata/upstream.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return replayer
+}
+
+// updateRouting returns the schema of the routing read from api.
+func updateRouting(t *testing.T, api ports.AWSRouter) []output.Tgw {
+	t.Helper()
+	app := &application.Application{RouterClient: api, InfoLog: log.New(io.Discard, "", 0), ErrorLog: log.New(io.Discard, "", 0)}
+	tgws, err := app.UpdateRouting(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return output.NewTgws(tgws)
+}
+
+// recordAndReplay records the calls of UpdateRouting to the fake and returns a Replayer of the fixture written.
+func recordAndReplay(t *testing.T, scrub *Scrubber) (*Replayer, string) {
+	t.Helper()
+	rec := NewRecorder(upstream(t), scrub)
+	updateRouting(t, rec)
+	var b bytes.Buffer
+	if err := rec.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	fixture := b.String()
+	f, err := ReadFixture(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayer, err := NewReplayer(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return replayer, fixture
+}
+
+func TestRecordReplay(t *testing.T) {
+	want := updateRouting(t, upstream(t))
+	replayer, _ := recordAndReplay(t, nil)
+	if diff := cmp.Diff(want, updateRouting(t, replayer)); diff != "" {
+		t.Errorf("replayed routing mismatch (-want +got):\n%s", diff)
+	}
+	// A request that was not recorded.
+	_, err := replayer.SearchTransitGatewayRoutes(context.TODO(), ports.TgwSearchRoutesInputFilter("tgw-rtb-0z"))
+	if !errors.Is(err, ErrNoRecording) {
+		t.Errorf("error = %v, want %v", err, ErrNoRecording)
+	}
+}
+
+func TestRecordDeterministic(t *testing.T) {
+	_, first := recordAndReplay(t, nil)
+	_, second := recordAndReplay(t, nil)
+	if first != second {
+		t.Errorf("two recordings of the same calls are different:\n%s\n%s", first, second)
+	}
+}
+
+func TestReplayErrorsAndOrder(t *testing.T) {
+	rec := NewRecorder(upstream(t), nil)
+	input := &ec2.DescribeTransitGatewayAttachmentsInput{TransitGatewayAttachmentIds: []string{"tgw-attach-0a", "tgw-attach-0b"}}
+	rec.DescribeTransitGatewayAttachments(context.TODO(), input)
+	rec.DescribeTransitGatewayAttachments(context.TODO(), input)
+
+	var b bytes.Buffer
+	if err := rec.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	f, err := ReadFixture(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayer, err := NewReplayer(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The responses are returned in the order they were recorded and the last one is repeated.
+	if _, err := replayer.DescribeTransitGatewayAttachments(context.TODO(), input); err == nil || !strings.Contains(err.Error(), "Throttling") {
+		t.Errorf("first replay error = %v, want the throttling error", err)
+	}
+	for i := 0; i < 2; i++ {
+		out, err := replayer.DescribeTransitGatewayAttachments(context.TODO(), input)
+		if err != nil || aws.StringValue(out.TransitGatewayAttachments[0].ResourceOwnerId) != "123456789012" {
+			t.Errorf("replay %d = %v, %v, want the attachment", i+2, out, err)
+		}
+	}
+}
+
+func TestScrubbedRecording(t *testing.T) {
+	scrub := newScrubberWithKey(true, true, []byte("test key"))
+	replayer, fixture := recordAndReplay(t, scrub)
+	for _, secret := range []string{"123456789012", "10.0.0.0", "10.1.0.0", "10.1.2.0", "2001:db8"} {
+		if strings.Contains(fixture, secret) {
+			t.Errorf("fixture contains %q:\n%s", secret, fixture)
+		}
+	}
+	if !strings.Contains(fixture, "arn:aws:ec2:us-east-1:100000000001:transit-gateway/tgw-0a") {
+		t.Errorf("fixture does not contain the scrubbed ARN:\n%s", fixture)
+	}
+
+	// The scrubbed routing gives the same lookups with the scrubbed addresses.
+	app := &application.Application{RouterClient: replayer, InfoLog: log.New(io.Discard, "", 0), ErrorLog: log.New(io.Discard, "", 0)}
+	tgws, err := app.UpdateRouting(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "10.1.2.3", want: "10.1.2.0/24"},
+		{ip: "10.1.3.3", want: "10.1.0.0/16"},
+		{ip: "10.0.5.5", want: "10.0.0.0/16"},
+		{ip: "2001:db8:1::5", want: "2001:db8:1::/48"},
+		{ip: "2001:db8:2::5", want: "2001:db8::/32"},
+	}
+	for _, tt := range tests {
+		ip := string(scrub.Scrub([]byte(tt.ip)))
+		route, err := tgws[0].RouteTables[0].BestRouteToIP(net.ParseIP(ip))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := aws.StringValue(route.DestinationCidrBlock), string(scrub.Scrub([]byte(tt.want))); got != want {
+			t.Errorf("best route to %s (%s) = %s, want %s (%s)", ip, tt.ip, got, want, tt.want)
+		}
+	}
+}
+
+func TestScrubber(t *testing.T) {
+	scrub := newScrubberWithKey(true, true, []byte("test key"))
+	got := string(scrub.Scrub([]byte(`{"OwnerId":"123456789012","Other":"210987654321","Arn":"arn:aws:iam::123456789012:role/x","Cidr":"0.0.0.0/0","Id":"vpc-0123456789abcdef0"}`)))
+	want := `{"OwnerId":"100000000001","Other":"100000000002","Arn":"arn:aws:iam::100000000001:role/x","Cidr":"0.0.0.0/0","Id":"vpc-0123456789abcdef0"}`
+	if got != want {
+		t.Errorf("Scrub() = %s, want %s", got, want)
+	}
+
+	// The prefixes are kept and the host bits of the CIDRs are zero.
+	_, wide, err := net.ParseCIDR(string(scrub.Scrub([]byte("192.168.0.0/16"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	narrow := string(scrub.Scrub([]byte("192.168.10.0/24")))
+	ip, _, err := net.ParseCIDR(narrow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wide.Contains(ip) || strings.HasPrefix(narrow, "192.168.") {
+		t.Errorf("scrubbed 192.168.10.0/24 = %s, want a new prefix inside %s", narrow, wide)
+	}
+	if !strings.HasSuffix(narrow, ".0/24") {
+		t.Errorf("scrubbed 192.168.10.0/24 = %s, want the host bits at zero", narrow)
+	}
+
+	// The same for IPv6. The times are not addresses and the IPv4-mapped addresses are scrubbed as IPv4.
+	_, wide, err = net.ParseCIDR(string(scrub.Scrub([]byte("2001:db8::/32"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	narrow = string(scrub.Scrub([]byte("2001:db8:10::/48")))
+	ip, narrowNet, err := net.ParseCIDR(narrow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wide.Contains(ip) || strings.HasPrefix(narrow, "2001:db8:") || !ip.Equal(narrowNet.IP) {
+		t.Errorf("scrubbed 2001:db8:10::/48 = %s, want a new prefix inside %s with the host bits at zero", narrow, wide)
+	}
+	if got := string(scrub.Scrub([]byte("2023-05-01T10:20:30.000Z"))); got != "2023-05-01T10:20:30.000Z" {
+		t.Errorf("Scrub(2023-05-01T10:20:30.000Z) = %s, want it unchanged", got)
+	}
+	if got, want := string(scrub.Scrub([]byte("::ffff:10.0.0.1"))), "::ffff:"+string(scrub.Scrub([]byte("10.0.0.1"))); got != want {
+		t.Errorf("Scrub(::ffff:10.0.0.1) = %s, want %s", got, want)
+	}
+}
+
+// TestReplayFixture is an example of a regression test from a fixture attached to a bug report.
+func TestReplayFixture(t *testing.T) {
+	replayer, err := LoadReplayer("testdata/core.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &application.Application{RouterClient: replayer, InfoLog: log.New(io.Discard, "", 0), ErrorLog: log.New(io.Discard, "", 0)}
+	tgws, err := app.UpdateRouting(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tgws) != 1 || tgws[0].Name != "core" || aws.StringValue(tgws[0].Data.OwnerId) != "100000000001" {
+		t.Fatalf("tgws = %+v, want the TGW core with the scrubbed owner", tgws)
+	}
+	// The fixture was recorded without the propagations, they are unknown and not empty.
+	for _, rt := range tgws[0].RouteTables {
+		if rt.Propagations != nil {
+			t.Errorf("propagations of %s = %v, want nil", rt.ID, rt.Propagations)
+		}
+	}
+	tests := []struct {
+		name     string
+		src, dst string
+		want     string
+		wantErr  error
+	}{
+		{name: "Reachable", src: "10.1.0.1", dst: "10.0.0.1", want: "tgw-attach-0b -> tgw-attach-0a"},
+		{name: "Same Attachment", src: "10.1.0.1", dst: "10.1.2.1", want: "tgw-attach-0b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attPath := awsrouter.NewAttPath()
+			attPath.Tgw = tgws[0]
+			err := attPath.Walk(context.TODO(), nil, net.ParseIP(tt.src), net.ParseIP(tt.dst))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Walk() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := attPath.String(); got != tt.want {
+				t.Errorf("Walk() path = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Replayer answers the requests with the responses of a fixture, it is safe to use from multiple goroutines.
+// A request is matched by its operation and its parameters. When the same request was recorded more than once
+// the responses are returned in the order they were recorded, and the last one is repeated after that.
+type Replayer struct {
+	mu        sync.Mutex
+	responses map[string][]Interaction
+	next      map[string]int
+}
+
+// NewReplayer returns a Replayer of the interactions in f.
+func NewReplayer(f *Fixture) (*Replayer, error) {
+	r := &Replayer{responses: make(map[string][]Interaction), next: make(map[string]int)}
+	for _, i := range f.Interactions {
+		key, err := replayKey(i.Operation, i.Input)
+		if err != nil {
+			return nil, fmt.Errorf("error reading the input of %s: %w", i.Operation, err)
+		}
+		r.responses[key] = append(r.responses[key], i)
+	}
+	return r, nil
+}
+
+// LoadReplayer returns a Replayer of the fixture in the file fileName.
+func LoadReplayer(fileName string) (*Replayer, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("error opening the fixture: %w", err)
+	}
+	defer file.Close()
+	f, err := ReadFixture(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(f)
+}
+
+// replayKey returns the key of a request, the input is compacted so the indentation of the fixture does not matter.
+func replayKey(operation string, input []byte) (string, error) {
+	var b bytes.Buffer
+	if err := json.Compact(&b, input); err != nil {
+		return "", err
+	}
+	return operation + " " + b.String(), nil
+}
+
+// replay decodes in output the recorded response to the input of operation.
+func (r *Replayer) replay(operation string, input, output interface{}) error {
+	in, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	key, err := replayKey(operation, in)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	responses := r.responses[key]
+	n := r.next[key]
+	if n < len(responses)-1 {
+		r.next[key]++
+	}
+	r.mu.Unlock()
+	if len(responses) == 0 {
+		return fmt.Errorf("%w: %s %s", ErrNoRecording, operation, in)
+	}
+	i := responses[n]
+	if i.Error != "" {
+		return errors.New(i.Error)
+	}
+	if err := json.Unmarshal(i.Output, output); err != nil {
+		return fmt.Errorf("error decoding the recorded output of %s: %w", operation, err)
+	}
+	return nil
+}
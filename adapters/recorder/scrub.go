@@ -0,0 +1,151 @@
+package recorder
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+var (
+	// accountIDRe matches an AWS account ID, alone or inside an ARN.
+	accountIDRe = regexp.MustCompile(`\b\d{12}\b`)
+	// ipv4Re matches an IPv4 address with an optional prefix length.
+	ipv4Re = regexp.MustCompile(`\b(\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3})(/\d{1,2})?\b`)
+	// ipv6Re matches the candidates to an IPv6 address, with at least two colons and an optional prefix length.
+	// The matches that net.ParseIP rejects, like the times, are left as they are.
+	ipv6Re = regexp.MustCompile(`([0-9A-Fa-f.]*:[0-9A-Fa-f.]*:[0-9A-Fa-f:.]*)(/\d{1,3})?`)
+)
+
+// Scrubber replaces the account IDs and the IPv4 and IPv6 addresses in the recorded requests and responses.
+//
+// Each account ID is replaced by a fake one, always the same for the same account.
+// The IP addresses are replaced keeping the prefixes: two addresses that share the first n bits still share
+// the first n bits after the scrub, so the routes still overlap in the same way and lookups give the same result.
+// The host bits of a CIDR are kept at zero.
+type Scrubber struct {
+	accountIDs bool
+	ips        bool
+	key        []byte
+
+	mu       sync.Mutex
+	accounts map[string]string
+}
+
+// NewScrubber returns a Scrubber of account IDs, of IP addresses or of both.
+// The IPs are scrubbed with a random key, so the original addresses can not be recovered from the fixture.
+func NewScrubber(accountIDs, ips bool) (*Scrubber, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("error creating the scrub key: %w", err)
+	}
+	return newScrubberWithKey(accountIDs, ips, key), nil
+}
+
+// newScrubberWithKey returns a Scrubber that uses key for the IPs, used by the tests.
+func newScrubberWithKey(accountIDs, ips bool, key []byte) *Scrubber {
+	return &Scrubber{accountIDs: accountIDs, ips: ips, key: key, accounts: make(map[string]string)}
+}
+
+// Scrub returns b with the account IDs and IP addresses replaced.
+func (s *Scrubber) Scrub(b []byte) []byte {
+	if s.accountIDs {
+		b = accountIDRe.ReplaceAllFunc(b, func(id []byte) []byte {
+			return []byte(s.accountID(string(id)))
+		})
+	}
+	if s.ips {
+		b = ipv4Re.ReplaceAllFunc(b, func(match []byte) []byte {
+			parts := ipv4Re.FindSubmatch(match)
+			ip := net.ParseIP(string(parts[1])).To4()
+			if ip == nil {
+				return match
+			}
+			length := 32
+			if len(parts[2]) > 0 {
+				length, _ = strconv.Atoi(string(parts[2][1:]))
+				if length > 32 {
+					return match
+				}
+			}
+			scrubbed := make(net.IP, 4)
+			binary.BigEndian.PutUint32(scrubbed, s.ipv4(binary.BigEndian.Uint32(ip)))
+			scrubbed = scrubbed.Mask(net.CIDRMask(length, 32))
+			return append([]byte(scrubbed.String()), parts[2]...)
+		})
+		b = ipv6Re.ReplaceAllFunc(b, func(match []byte) []byte {
+			parts := ipv6Re.FindSubmatch(match)
+			ip := net.ParseIP(string(parts[1]))
+			// The IPv4-mapped addresses were scrubbed as IPv4.
+			if ip == nil || ip.To4() != nil {
+				return match
+			}
+			length := 128
+			if len(parts[2]) > 0 {
+				length, _ = strconv.Atoi(string(parts[2][1:]))
+				if length > 128 {
+					return match
+				}
+			}
+			scrubbed := s.ipv6(ip).Mask(net.CIDRMask(length, 128))
+			return append([]byte(scrubbed.String()), parts[2]...)
+		})
+	}
+	return b
+}
+
+// accountID returns the fake account ID of id.
+func (s *Scrubber) accountID(id string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fake, ok := s.accounts[id]
+	if !ok {
+		fake = fmt.Sprintf("%012d", 100000000000+len(s.accounts)+1)
+		s.accounts[id] = fake
+	}
+	return fake
+}
+
+// ipv4 returns the scrubbed address of ip. Each bit is flipped or not depending on the bits before it,
+// so the addresses with a common prefix keep a common prefix of the same length.
+func (s *Scrubber) ipv4(ip uint32) uint32 {
+	var result uint32
+	msg := make([]byte, 5)
+	for i := 0; i < 32; i++ {
+		var prefix uint32
+		if i > 0 {
+			prefix = ip >> (32 - i)
+		}
+		msg[0] = byte(i)
+		binary.BigEndian.PutUint32(msg[1:], prefix)
+		mac := hmac.New(sha256.New, s.key)
+		mac.Write(msg)
+		flip := uint32(mac.Sum(nil)[0] & 1)
+		bit := (ip >> (31 - i)) & 1
+		result = result<<1 | (bit ^ flip)
+	}
+	return result
+}
+
+// ipv6 returns the scrubbed address of ip like ipv4 does, with the same key.
+// The messages of the HMAC are longer than the ones of ipv4, so the flips of both are independent.
+func (s *Scrubber) ipv6(ip net.IP) net.IP {
+	ip = ip.To16()
+	result := make(net.IP, net.IPv6len)
+	msg := make([]byte, 1+net.IPv6len)
+	for i := 0; i < 128; i++ {
+		msg[0] = byte(i)
+		copy(msg[1:], ip.Mask(net.CIDRMask(i, 128)))
+		mac := hmac.New(sha256.New, s.key)
+		mac.Write(msg)
+		flip := mac.Sum(nil)[0] & 1
+		bit := ip[i/8] >> (7 - i%8) & 1
+		result[i/8] |= (bit ^ flip) << (7 - i%8)
+	}
+	return result
+}
@@ -1,9 +1,81 @@
 package awsrouter
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 	ErrTgwRouteTableNotFound      = errors.New("awsrouter: transit gateway route table not found")
 	ErrTgwRouteTableRouteNotFound = errors.New("awsrouter: transit gateway route table route not found")
+	ErrTgwRouteBlackhole          = errors.New("awsrouter: transit gateway route is a blackhole")
+	ErrTgwVpnDown                 = errors.New("awsrouter: the tunnels of the vpn are down")
+	ErrTgwCloudWan                = errors.New("awsrouter: leaves to Cloud WAN core network")
 	ErrTgwAttachmetInPath         = errors.New("awsrouter: attachmet is already in the path")
 )
+
+// RouteTableError is the error retrieving the routes or the associations of a route table,
+// the route table keeps what was retrieved before the error.
+type RouteTableError struct {
+	TgwID        string
+	RouteTableID string
+	Err          error
+}
+
+func (e *RouteTableError) Error() string {
+	return fmt.Sprintf("route table %s: %v", e.RouteTableID, e.Err)
+}
+
+func (e *RouteTableError) Unwrap() error {
+	return e.Err
+}
+
+// PartialRoutingError is returned when some route tables are incomplete, the rest of the routing is complete.
+type PartialRoutingError struct {
+	RouteTables []*RouteTableError
+}
+
+func (e *PartialRoutingError) Error() string {
+	msgs := make([]string, 0, len(e.RouteTables))
+	for _, rt := range e.RouteTables {
+		msgs = append(msgs, rt.Error())
+	}
+	return fmt.Sprintf("awsrouter: %d route tables are incomplete: %s", len(e.RouteTables), strings.Join(msgs, "; "))
+}
+
+// Incomplete reports if the route table with the ID id is incomplete, a nil error has no incomplete route tables.
+func (e *PartialRoutingError) Incomplete(id string) bool {
+	if e == nil {
+		return false
+	}
+	for _, rt := range e.RouteTables {
+		if rt.RouteTableID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge adds the route tables of err to e if err is a *PartialRoutingError, and reports if it was.
+func (e *PartialRoutingError) Merge(err error) bool {
+	var partial *PartialRoutingError
+	if !errors.As(err, &partial) {
+		return false
+	}
+	e.RouteTables = append(e.RouteTables, partial.RouteTables...)
+	return true
+}
+
+// add adds the error err of the route table rtID of the Tgw tgwID.
+func (e *PartialRoutingError) add(tgwID, rtID string, err error) {
+	e.RouteTables = append(e.RouteTables, &RouteTableError{TgwID: tgwID, RouteTableID: rtID, Err: err})
+}
+
+// orNil returns e, or nil if it has no route tables, so it can be returned as an error.
+func (e *PartialRoutingError) orNil() error {
+	if e == nil || len(e.RouteTables) == 0 {
+		return nil
+	}
+	return e
+}
@@ -6,6 +6,8 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/service/directconnect"
+	dxtypes "github.com/aws/aws-sdk-go-v2/service/directconnect/types"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go/aws"
@@ -147,6 +149,160 @@ var listGetTransitGatewayRouteTableAssociationsOutput *ec2.GetTransitGatewayRout
 	},
 }
 
+// listGetTransitGatewayRouteTablePropagations are the pages of a disabled and an enabled propagation, by NextToken.
+var listGetTransitGatewayRouteTablePropagations = map[string]*ec2.GetTransitGatewayRouteTablePropagationsOutput{
+	"": {
+		TransitGatewayRouteTablePropagations: []types.TransitGatewayRouteTablePropagation{{
+			ResourceId:                 aws.String("tgw-04408890ef44df3e3"),
+			ResourceType:               "peering",
+			State:                      types.TransitGatewayPropagationStateDisabled,
+			TransitGatewayAttachmentId: aws.String("tgw-attach-080f3014bd52ec96f"),
+		}},
+		NextToken: aws.String("page-2"),
+	},
+	"page-2": {
+		TransitGatewayRouteTablePropagations: []types.TransitGatewayRouteTablePropagation{{
+			ResourceId:                 aws.String("vpc-0af25be733475a425"),
+			ResourceType:               "vpc",
+			State:                      types.TransitGatewayPropagationStateEnabled,
+			TransitGatewayAttachmentId: aws.String("tgw-attach-080f3014bd52ec95f"),
+		}},
+	},
+}
+
+// listManagedPrefixLists are the prefix lists by ID.
+var listManagedPrefixLists = map[string]types.ManagedPrefixList{
+	"pl-0a": {PrefixListId: aws.String("pl-0a"), PrefixListName: aws.String("offices"), MaxEntries: aws.Int32(25)},
+}
+
+// listDescribeVpnConnectionsOutput has a VPN with one tunnel up and a VPN with both tunnels down.
+var listDescribeVpnConnectionsOutput = &ec2.DescribeVpnConnectionsOutput{
+	VpnConnections: []types.VpnConnection{
+		{
+			VpnConnectionId: aws.String("vpn-0d"),
+			State:           types.VpnStateAvailable,
+			VgwTelemetry: []types.VgwTelemetry{
+				{OutsideIpAddress: aws.String("198.51.100.2"), Status: types.TelemetryStatusDown, StatusMessage: aws.String("IPSEC IS DOWN")},
+				{OutsideIpAddress: aws.String("198.51.100.1"), Status: types.TelemetryStatusDown, StatusMessage: aws.String("IPSEC IS DOWN")},
+			},
+		},
+		{
+			VpnConnectionId: aws.String("vpn-0a"),
+			State:           types.VpnStateAvailable,
+			VgwTelemetry: []types.VgwTelemetry{
+				{OutsideIpAddress: aws.String("203.0.113.1"), Status: types.TelemetryStatusUp, AcceptedRouteCount: aws.Int32(12)},
+				{OutsideIpAddress: aws.String("203.0.113.2"), Status: types.TelemetryStatusDown, AcceptedRouteCount: aws.Int32(0)},
+			},
+		},
+	},
+}
+
+// listDirectConnectGatewayAssociations are the pages of the associations of the Direct Connect gateways, by NextToken.
+var listDirectConnectGatewayAssociations = map[string]*directconnect.DescribeDirectConnectGatewayAssociationsOutput{
+	"": {
+		DirectConnectGatewayAssociations: []dxtypes.DirectConnectGatewayAssociation{{
+			DirectConnectGatewayId: aws.String("dxgw-0b"),
+			AssociationState:       dxtypes.DirectConnectGatewayAssociationStateAssociated,
+			AllowedPrefixesToDirectConnectGateway: []dxtypes.RouteFilterPrefix{
+				{Cidr: aws.String("10.1.0.0/16")},
+				{Cidr: aws.String("10.0.0.0/16")},
+			},
+		}},
+		NextToken: aws.String("page-2"),
+	},
+	"page-2": {
+		DirectConnectGatewayAssociations: []dxtypes.DirectConnectGatewayAssociation{{
+			DirectConnectGatewayId: aws.String("dxgw-0a"),
+			AssociationState:       dxtypes.DirectConnectGatewayAssociationStateAssociating,
+		}},
+	},
+}
+
+// listDescribeTransitGatewayConnectsOutput has a Connect attachment over a VPC attachment.
+var listDescribeTransitGatewayConnectsOutput = &ec2.DescribeTransitGatewayConnectsOutput{
+	TransitGatewayConnects: []types.TransitGatewayConnect{{
+		TransitGatewayAttachmentId:          aws.String("tgw-attach-0c"),
+		TransportTransitGatewayAttachmentId: aws.String("tgw-attach-0t"),
+		State:                               types.TransitGatewayAttachmentStateAvailable,
+		Options:                             &types.TransitGatewayConnectOptions{Protocol: types.ProtocolValueGre},
+	}},
+}
+
+// listDescribeTransitGatewayConnectPeersOutput has a peer with a BGP session up, a peer with the BGP sessions down
+// and a peer of a Connect attachment of another Transit Gateway.
+var listDescribeTransitGatewayConnectPeersOutput = &ec2.DescribeTransitGatewayConnectPeersOutput{
+	TransitGatewayConnectPeers: []types.TransitGatewayConnectPeer{
+		{
+			TransitGatewayConnectPeerId: aws.String("tgw-connect-peer-0b"),
+			TransitGatewayAttachmentId:  aws.String("tgw-attach-0c"),
+			State:                       types.TransitGatewayConnectPeerStateAvailable,
+			ConnectPeerConfiguration: &types.TransitGatewayConnectPeerConfiguration{
+				PeerAddress:           aws.String("10.2.0.11"),
+				TransitGatewayAddress: aws.String("192.0.2.2"),
+				InsideCidrBlocks:      []string{"169.254.6.0/29"},
+				BgpConfigurations: []types.TransitGatewayAttachmentBgpConfiguration{
+					{PeerAddress: aws.String("169.254.6.1"), PeerAsn: aws.Int64(65001), TransitGatewayAddress: aws.String("169.254.6.3"), TransitGatewayAsn: aws.Int64(64512), BgpStatus: types.BgpStatusDown},
+					{PeerAddress: aws.String("169.254.6.1"), PeerAsn: aws.Int64(65001), TransitGatewayAddress: aws.String("169.254.6.2"), TransitGatewayAsn: aws.Int64(64512), BgpStatus: types.BgpStatusDown},
+				},
+			},
+		},
+		{
+			TransitGatewayConnectPeerId: aws.String("tgw-connect-peer-0a"),
+			TransitGatewayAttachmentId:  aws.String("tgw-attach-0c"),
+			State:                       types.TransitGatewayConnectPeerStateAvailable,
+			ConnectPeerConfiguration: &types.TransitGatewayConnectPeerConfiguration{
+				PeerAddress:           aws.String("10.2.0.10"),
+				TransitGatewayAddress: aws.String("192.0.2.1"),
+				InsideCidrBlocks:      []string{"169.254.5.0/29"},
+				BgpConfigurations: []types.TransitGatewayAttachmentBgpConfiguration{
+					{PeerAddress: aws.String("169.254.5.1"), PeerAsn: aws.Int64(65001), TransitGatewayAddress: aws.String("169.254.5.2"), TransitGatewayAsn: aws.Int64(64512), BgpStatus: types.BgpStatusUp},
+				},
+			},
+		},
+		{
+			TransitGatewayConnectPeerId: aws.String("tgw-connect-peer-0z"),
+			TransitGatewayAttachmentId:  aws.String("tgw-attach-0z"),
+			State:                       types.TransitGatewayConnectPeerStateAvailable,
+		},
+	},
+}
+
+// listDescribeTransitGatewayPolicyTables are the pages of a policy table without a name and one with it, by NextToken.
+var listDescribeTransitGatewayPolicyTables = map[string]*ec2.DescribeTransitGatewayPolicyTablesOutput{
+	"": {
+		TransitGatewayPolicyTables: []types.TransitGatewayPolicyTable{{
+			TransitGatewayPolicyTableId: aws.String("tgw-ptb-0b"),
+			TransitGatewayId:            aws.String("tgw-0a"),
+			State:                       types.TransitGatewayPolicyTableStateAvailable,
+		}},
+		NextToken: aws.String("page-2"),
+	},
+	"page-2": {
+		TransitGatewayPolicyTables: []types.TransitGatewayPolicyTable{{
+			TransitGatewayPolicyTableId: aws.String("tgw-ptb-0a"),
+			TransitGatewayId:            aws.String("tgw-0a"),
+			State:                       types.TransitGatewayPolicyTableStateAvailable,
+			Tags:                        []types.Tag{{Key: aws.String("Name"), Value: aws.String("cloudwan")}},
+		}},
+	},
+}
+
+// listGetTransitGatewayPolicyTableAssociations are the associations of each policy table.
+var listGetTransitGatewayPolicyTableAssociations = map[string]*ec2.GetTransitGatewayPolicyTableAssociationsOutput{
+	"tgw-ptb-0a": {
+		Associations: []types.TransitGatewayPolicyTableAssociation{
+			{
+				TransitGatewayPolicyTableId: aws.String("tgw-ptb-0a"),
+				TransitGatewayAttachmentId:  aws.String("tgw-attach-0w"),
+				ResourceId:                  aws.String("core-network-0a"),
+				ResourceType:                types.TransitGatewayAttachmentResourceTypeTgwPeering,
+				State:                       types.TransitGatewayAssociationStateAssociated,
+			},
+		},
+	},
+	"tgw-ptb-0b": {},
+}
+
 var listTgwAttachments []types.TransitGatewayRouteAttachment = []types.TransitGatewayRouteAttachment{
 	{
 		ResourceId:                 aws.String("vpc-0af25be733475a425"),
@@ -271,6 +427,49 @@ func (t TgwDescriberImpl) DescribeTransitGatewayAttachments(ctx context.Context,
 	return nil, nil
 }
 
+func (t TgwDescriberImpl) GetTransitGatewayRouteTablePropagations(ctx context.Context, params *ec2.GetTransitGatewayRouteTablePropagationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayRouteTablePropagationsOutput, error) {
+	return listGetTransitGatewayRouteTablePropagations[aws.StringValue(params.NextToken)], nil
+}
+
+func (t TgwDescriberImpl) DescribeManagedPrefixLists(ctx context.Context, params *ec2.DescribeManagedPrefixListsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeManagedPrefixListsOutput, error) {
+	var result []types.ManagedPrefixList
+	for _, id := range params.PrefixListIds {
+		if pl, ok := listManagedPrefixLists[id]; ok {
+			result = append(result, pl)
+		}
+	}
+	return &ec2.DescribeManagedPrefixListsOutput{PrefixLists: result}, nil
+}
+
+func (t TgwDescriberImpl) DescribeVpnConnections(ctx context.Context, params *ec2.DescribeVpnConnectionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpnConnectionsOutput, error) {
+	return listDescribeVpnConnectionsOutput, nil
+}
+
+func (t TgwDescriberImpl) DescribeDirectConnectGatewayAssociations(ctx context.Context, params *directconnect.DescribeDirectConnectGatewayAssociationsInput, optFns ...func(*directconnect.Options)) (*directconnect.DescribeDirectConnectGatewayAssociationsOutput, error) {
+	return listDirectConnectGatewayAssociations[aws.StringValue(params.NextToken)], nil
+}
+
+func (t TgwDescriberImpl) DescribeTransitGatewayConnects(ctx context.Context, params *ec2.DescribeTransitGatewayConnectsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayConnectsOutput, error) {
+	return listDescribeTransitGatewayConnectsOutput, nil
+}
+
+func (t TgwDescriberImpl) DescribeTransitGatewayConnectPeers(ctx context.Context, params *ec2.DescribeTransitGatewayConnectPeersInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayConnectPeersOutput, error) {
+	// The peers are returned in two pages, the first one with only the first peer.
+	peers := listDescribeTransitGatewayConnectPeersOutput.TransitGatewayConnectPeers
+	if aws.StringValue(params.NextToken) == "" {
+		return &ec2.DescribeTransitGatewayConnectPeersOutput{TransitGatewayConnectPeers: peers[:1], NextToken: aws.String("page-2")}, nil
+	}
+	return &ec2.DescribeTransitGatewayConnectPeersOutput{TransitGatewayConnectPeers: peers[1:]}, nil
+}
+
+func (t TgwDescriberImpl) DescribeTransitGatewayPolicyTables(ctx context.Context, params *ec2.DescribeTransitGatewayPolicyTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayPolicyTablesOutput, error) {
+	return listDescribeTransitGatewayPolicyTables[aws.StringValue(params.NextToken)], nil
+}
+
+func (t TgwDescriberImpl) GetTransitGatewayPolicyTableAssociations(ctx context.Context, params *ec2.GetTransitGatewayPolicyTableAssociationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayPolicyTableAssociationsOutput, error) {
+	return listGetTransitGatewayPolicyTableAssociations[aws.StringValue(params.TransitGatewayPolicyTableId)], nil
+}
+
 func TestTgwInputFilter(t *testing.T) {
 	type args struct {
 		tgwIDs []string
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"os"
+	"sort"
 	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/rogerscuall/aws-router/ports"
 )
 
@@ -19,6 +22,21 @@ type Tgw struct {
 	Name        string
 	RouteTables []*TgwRouteTable
 	Data        types.TransitGateway
+
+	// PrefixLists are the prefix lists referenced by the routes, nil when they were not collected.
+	PrefixLists []*PrefixList
+
+	// VpnConnections are the VPN connections attached to the Tgw, nil when they were not collected.
+	VpnConnections []*VpnConnection
+
+	// DxGateways are the Direct Connect gateways associated to the Tgw, nil when they were not collected.
+	DxGateways []*DxGateway
+
+	// Connects are the Connect attachments of the Tgw with their peers, nil when they were not collected.
+	Connects []*TgwConnect
+
+	// PolicyTables are the policy tables of the Tgw with their associations, nil when they were not collected.
+	PolicyTables []*TgwPolicyTable
 }
 
 // Build a Tgw from a aws TGW.
@@ -47,6 +65,20 @@ func (t *Tgw) Bytes() []byte {
 	return b
 }
 
+// NewTgwFromBytes builds a Tgw from the JSON created by Bytes, with the route index of each route table.
+func NewTgwFromBytes(b []byte) (*Tgw, error) {
+	t := &Tgw{}
+	if err := json.Unmarshal(b, t); err != nil {
+		return nil, fmt.Errorf("error decoding the Transit Gateway: %w", err)
+	}
+	for _, rt := range t.RouteTables {
+		if err := rt.BuildRouteIndex(); err != nil {
+			return nil, fmt.Errorf("error building the route index of %s: %w", rt.ID, err)
+		}
+	}
+	return t, nil
+}
+
 // UpdateRouteTables updates the field TgwRouteTables on a Tgw.
 // An error will stop the processing returning the error wrapped.
 func (t *Tgw) UpdateRouteTables(ctx context.Context, api ports.AWSRouter) error {
@@ -63,71 +95,134 @@ func (t *Tgw) UpdateRouteTables(ctx context.Context, api ports.AWSRouter) error
 }
 
 // UpdateTgwRoutes updates the routes of a route table.
-//
-// TODO: Add some sentinel error message to notify if a the calls to UpdateTgwRoutes fail.
-// TODO: add testing and include race condition detection.
-//
 // Each Tgw has a list of TgwRouteTables, each RouteTable gets is own goroutine.
+// The route tables where the routes can not be retrieved are returned in a *PartialRoutingError.
 func (t *Tgw) UpdateTgwRoutes(ctx context.Context, api ports.AWSRouter) error {
 	var wg sync.WaitGroup
-	var err error
+	var mu sync.Mutex
+	partial := &PartialRoutingError{}
 	for _, tgwRouteTable := range t.RouteTables {
 		wg.Add(1)
 		go func(routeTable *TgwRouteTable) {
 			defer wg.Done()
 			inputTgwSearchRoutes := ports.TgwSearchRoutesInputFilter(routeTable.ID)
-			resultTgwSearchRoutes, err := ports.GetTgwRoutes(context.TODO(), api, inputTgwSearchRoutes)
+			resultTgwSearchRoutes, err := ports.GetTgwRoutes(ctx, api, inputTgwSearchRoutes)
 			if err != nil {
-				err = fmt.Errorf("error retrieve the table %s %w", routeTable.ID, err)
+				mu.Lock()
+				partial.add(t.ID, routeTable.ID, fmt.Errorf("error retrieving the routes: %w", err))
+				mu.Unlock()
+				return
 			}
 			routeTable.Routes = resultTgwSearchRoutes.Routes
+			// A route table with an invalid CIDR has no index, the lookups on it will report the error.
+			routeTable.BuildRouteIndex()
 		}(tgwRouteTable)
 	}
 	wg.Wait()
-	return err
+	return partial.orNil()
 }
 
 // UpdateTgwRouteTablesAttachments updates the Attachments of a TgwRouteTable.
+// The name and owner of each attachment are taken from DescribeTransitGatewayAttachments.
+// The route tables where the associations can not be retrieved are returned in a *PartialRoutingError
+// after trying the rest.
 func (t *Tgw) UpdateTgwRouteTablesAttachments(ctx context.Context, api ports.AWSRouter) error {
-	tempAttachment := make(map[string]string)
-	var name string
+	tempAttachment := make(map[string]types.TransitGatewayAttachment)
+	partial := &PartialRoutingError{}
 	for _, tgwRouteTable := range t.RouteTables {
 		input := ports.TgwRouteTableAssociationInputFilter(tgwRouteTable.ID)
 		result, err := ports.GetTgwRouteTableAssociations(ctx, api, input)
 		if err != nil {
-			return fmt.Errorf("error retrieving Transit Gateway Route Table Associations: %w", err)
+			partial.add(t.ID, tgwRouteTable.ID, fmt.Errorf("error retrieving Transit Gateway Route Table Associations: %w", err))
+			continue
 		}
 		err = tgwRouteTable.UpdateAttachments(ctx, result)
 		if err != nil {
-			return fmt.Errorf("error updating the route table %s %w", tgwRouteTable.ID, err)
+			partial.add(t.ID, tgwRouteTable.ID, fmt.Errorf("error updating the route table %s %w", tgwRouteTable.ID, err))
+			continue
 		}
 
-		// Update attachment names
+		// Update attachment names and owners
 		for _, att := range tgwRouteTable.Attachments {
 			if _, ok := tempAttachment[att.ID]; !ok {
 				attInput := ec2.DescribeTransitGatewayAttachmentsInput{}
 				attInput.TransitGatewayAttachmentIds = []string{att.ID}
 				attOutput, err := ports.GetTgwAttachments(ctx, api, &attInput)
 				if err != nil {
-					return fmt.Errorf("error retrieving Transit Gateway Attachments: %w", err)
-				}
-				if len(attOutput.TransitGatewayAttachments) != 1 {
-					fmt.Print("there is more than one attachment with the same ID")
+					partial.add(t.ID, tgwRouteTable.ID, fmt.Errorf("error retrieving Transit Gateway Attachments: %w", err))
+					break
 				}
-				tags := attOutput.TransitGatewayAttachments[0].Tags
-				if len(tags) == 0 {
+				if len(attOutput.TransitGatewayAttachments) == 0 {
+					// The attachment can be deleted after the associations were retrieved.
+					fmt.Fprintf(os.Stderr, "attachment %s not found\n", att.ID)
 					continue
 				}
-				if name, err = GetNamesFromTags(tags); err != nil {
-					fmt.Println("error getting the name from the tags")
-					continue
+				if len(attOutput.TransitGatewayAttachments) > 1 {
+					fmt.Fprintf(os.Stderr, "there is more than one attachment with the ID %s\n", att.ID)
 				}
-				tempAttachment[att.ID] = name
+				tempAttachment[att.ID] = attOutput.TransitGatewayAttachments[0]
+			}
+			described := tempAttachment[att.ID]
+			if described.ResourceOwnerId != nil {
+				att.OwnerID = *described.ResourceOwnerId
 			}
-			att.Name = tempAttachment[att.ID]
+			if len(described.Tags) == 0 {
+				continue
+			}
+			name, err := GetNamesFromTags(described.Tags)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "error getting the name from the tags")
+				continue
+			}
+			att.Name = name
+		}
+	}
+	return partial.orNil()
+}
+
+// UpdateTgwRouteTablesPropagations updates the Propagations of each TgwRouteTable.
+// The name and owner of each attachment are taken from the attachments already loaded by UpdateTgwRouteTablesAttachments.
+// A route table where the propagations can not be retrieved keeps them as unknown (nil) and the rest are updated,
+// the route tables that failed are returned in a *PartialRoutingError.
+func (t *Tgw) UpdateTgwRouteTablesPropagations(ctx context.Context, api ports.AWSRouter) error {
+	known := make(map[string]*TgwAttachment)
+	for _, rt := range t.RouteTables {
+		for _, att := range rt.Attachments {
+			known[att.ID] = att
+		}
+	}
+	partial := &PartialRoutingError{}
+	for _, rt := range t.RouteTables {
+		propagations, err := getTgwRouteTablePropagations(ctx, api, rt.ID)
+		if err != nil {
+			partial.add(t.ID, rt.ID, fmt.Errorf("error retrieving the propagations of the route table %s: %w", rt.ID, err))
+			continue
+		}
+		rt.UpdatePropagations(propagations)
+		for _, att := range rt.Propagations {
+			if k, ok := known[att.ID]; ok {
+				att.Name, att.OwnerID = k.Name, k.OwnerID
+			}
+		}
+	}
+	return partial.orNil()
+}
+
+// getTgwRouteTablePropagations returns the propagations of all the pages of the route table rtID.
+func getTgwRouteTablePropagations(ctx context.Context, api ports.AWSRouter, rtID string) (*ec2.GetTransitGatewayRouteTablePropagationsOutput, error) {
+	propagations := &ec2.GetTransitGatewayRouteTablePropagationsOutput{}
+	nextToken := ""
+	for {
+		result, err := ports.GetTgwRouteTablePropagations(ctx, api, ports.TgwRouteTablePropagationInputFilter(rtID, nextToken))
+		if err != nil {
+			return nil, err
+		}
+		propagations.TransitGatewayRouteTablePropagations = append(propagations.TransitGatewayRouteTablePropagations, result.TransitGatewayRouteTablePropagations...)
+		nextToken = aws.StringValue(result.NextToken)
+		if nextToken == "" {
+			return propagations, nil
 		}
 	}
-	return nil
 }
 
 // GetAllTgws returns a list of all the Transit Gateways in the account for specific region
@@ -147,23 +242,117 @@ func GetAllTgws(ctx context.Context, api ports.AWSRouter) ([]*Tgw, error) {
 // UpdateRouting this functions is a helper that will update all routing information from AWS, returning a list of Tgw.
 // The function will try to gather all the Route Tables and all the routes in the Route Tables.
 // The function will return an error if it fails to gather a Transit Gateway or a Route Table, but it will continue
-// if it fails to gather a route.
+// if it fails to gather the routes of a Route Table, returning the Tgws with a *PartialRoutingError.
 func UpdateRouting(ctx context.Context, api ports.AWSRouter) ([]*Tgw, error) {
 	tgws, err := GetAllTgws(ctx, api)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving Transit Gateways: %w", err)
 	}
 	for _, tgw := range tgws {
-		if tgw.UpdateRouteTables(context.TODO(), api); err != nil {
+		if err := tgw.UpdateRouteTables(ctx, api); err != nil {
 			return nil, fmt.Errorf("error retrieving Transit Gateway Route Tables: %w", err)
 		}
 	}
 	// Get all routes from all route tables
+	partial := &PartialRoutingError{}
 	for _, tgw := range tgws {
-		tgw.UpdateTgwRoutes(context.TODO(), api)
+		partial.Merge(tgw.UpdateTgwRoutes(ctx, api))
 	}
 
-	return tgws, nil
+	return tgws, partial.orNil()
+}
+
+// GetAttachmentName returns the name of the attachment that has the given ID in any of the route tables of the Tgw.
+func (t *Tgw) GetAttachmentName(attachmentID string) string {
+	for _, rt := range t.RouteTables {
+		if name := rt.GetAttachmentName(attachmentID); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// Attachments returns all the attachments of the Tgw sorted by ID.
+// It includes the attachments associated to a route table or a policy table, the attachments that propagate to a route table
+// and the attachments that are the next hop of a route, the later only have the information available in the route.
+func (t *Tgw) Attachments() []*TgwAttachment {
+	attachments := make(map[string]*TgwAttachment)
+	for _, rt := range t.RouteTables {
+		for _, att := range rt.Attachments {
+			attachments[att.ID] = att
+		}
+	}
+	for _, rt := range t.RouteTables {
+		for _, att := range rt.Propagations {
+			if _, ok := attachments[att.ID]; !ok {
+				attachments[att.ID] = att
+			}
+		}
+	}
+	for _, pt := range t.PolicyTables {
+		for _, att := range pt.Attachments {
+			if _, ok := attachments[att.ID]; !ok {
+				attachments[att.ID] = att
+			}
+		}
+	}
+	for _, rt := range t.RouteTables {
+		for _, route := range rt.Routes {
+			for _, att := range route.TransitGatewayAttachments {
+				if att.TransitGatewayAttachmentId == nil {
+					continue
+				}
+				if _, ok := attachments[*att.TransitGatewayAttachmentId]; ok {
+					continue
+				}
+				attachments[*att.TransitGatewayAttachmentId] = &TgwAttachment{
+					ID:         *att.TransitGatewayAttachmentId,
+					ResourceID: aws.StringValue(att.ResourceId),
+					Type:       fmt.Sprint(att.ResourceType),
+				}
+			}
+		}
+	}
+	result := make([]*TgwAttachment, 0, len(attachments))
+	for _, att := range attachments {
+		result = append(result, att)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// AssociatedRouteTable returns the route table where the attachment is associated, or nil if it is not associated.
+func (t *Tgw) AssociatedRouteTable(attachmentID string) *TgwRouteTable {
+	for _, rt := range t.RouteTables {
+		for _, att := range rt.Attachments {
+			if att.ID == attachmentID {
+				return rt
+			}
+		}
+	}
+	return nil
+}
+
+// PropagatingRouteTables returns the route tables where the attachment propagates routes.
+// The Propagations of each route table are used, for a route table without them the propagation is inferred
+// from the propagated routes that use the attachment as next hop.
+func (t *Tgw) PropagatingRouteTables(attachmentID string) []*TgwRouteTable {
+	var result []*TgwRouteTable
+	for _, rt := range t.RouteTables {
+		if propagates, known := rt.HasPropagation(attachmentID); known {
+			if propagates {
+				result = append(result, rt)
+			}
+			continue
+		}
+		for _, route := range rt.Routes {
+			if route.Type == types.TransitGatewayRouteTypePropagated && routeUses(route, attachmentID) {
+				result = append(result, rt)
+				break
+			}
+		}
+	}
+	return result
 }
 
 func (t *Tgw) GetTgwRouteTableByID(id string) (*TgwRouteTable, error) {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"os"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go/aws"
@@ -24,6 +25,9 @@ type TgwAttachment struct {
 
 	// The name of the TGW Attachment.
 	Name string
+
+	// The ID of the AWS account that owns the resource.
+	OwnerID string
 }
 
 // newTgwAttach builds a TgwAttachment from a aws TransitGatewayRouteAttachment type.
@@ -61,14 +65,39 @@ func getDirectlyConnectedAttachmentFromTgwRoute(rts []TgwRouteTable) []*TgwAttac
 		case "propagated":
 			return getAttachmentsFromTgwRoute(r)
 		case "static":
-			fmt.Println("Static route not implemented")
+			fmt.Fprintln(os.Stderr, "Static route not implemented")
 		default:
-			fmt.Println("Default case not implemented")
+			fmt.Fprintln(os.Stderr, "Default case not implemented")
 		}
 	}
 	return results
 }
 
+// PathHop is a route table consulted during a Walk and the result of the lookup.
+type PathHop struct {
+	// The route table where the lookup was done.
+	RouteTableID   string
+	RouteTableName string
+
+	// The prefix of the best route, empty if no route was found.
+	Prefix string
+
+	// The next hops of the best route, more than one is ECMP. Walk follows the first one.
+	NextHops []*TgwAttachment
+
+	// True if the best route is a blackhole, traffic is dropped.
+	Blackhole bool
+
+	// True if the next hop followed is a VPN with all its tunnels down, traffic is dropped.
+	VpnDown bool
+}
+
+// Dropped returns true if the traffic is dropped in this hop, because there is no route, the route is a blackhole
+// or the next hop is a VPN that is down.
+func (h PathHop) Dropped() bool {
+	return h.Blackhole || len(h.NextHops) == 0 || h.VpnDown
+}
+
 // AttPath is a list of TgwAttachments that represent the path from a source to a destination.
 // The first element is the source attachment, the last element is the destination attachment.
 // There can be 2 or more attachments in the path, but 2 or 3 are common values.
@@ -88,6 +117,9 @@ type AttPath struct {
 
 	// The Transit Gateway of this path.
 	Tgw *Tgw
+
+	// The route tables consulted during the Walk, in order.
+	Hops []PathHop
 }
 
 // NewAttPath builds a AttPath.
@@ -119,8 +151,39 @@ func (attPath *AttPath) addAttachmentToPath(att *TgwAttachment) error {
 	return nil
 }
 
+// associatedRouteTableID returns the ID of the route table associated to the attachment att.
+// The association is read from AWS, if api is nil it is taken from the route tables of tgw.
+func associatedRouteTableID(ctx context.Context, api ports.AWSRouter, tgw *Tgw, att *TgwAttachment) (string, error) {
+	if api == nil {
+		rt := tgw.AssociatedRouteTable(att.ID)
+		if rt == nil {
+			return "", ErrTgwRouteTableNotFound
+		}
+		return rt.ID, nil
+	}
+	// Find the route table associated to the attachment
+	// Create a filter for TgwAttachmentInputFilter
+	filter := types.Filter{
+		Name:   aws.String("resource-id"),
+		Values: []string{att.ResourceID},
+	}
+	// Create a filter of type TgwAttachmentInputFilter
+	input := ports.TgwAttachmentInputFilter(filter)
+	// Get the list of TgwRouteTable that match the filter
+	output, err := ports.GetTgwAttachments(ctx, api, input)
+	if err != nil {
+		return "", err
+	}
+	if len(output.TransitGatewayAttachments) != 1 || output.TransitGatewayAttachments[0].Association == nil {
+		return "", ErrTgwRouteTableNotFound
+	}
+	return aws.StringValue(output.TransitGatewayAttachments[0].Association.TransitGatewayRouteTableId), nil
+}
+
 // Walk will do a packet walk from the src to dst and updates the field Path.
 // The function will walk from one attachment to the next, until it reaches the dst.
+// The associations of the attachments are read from AWS with api, if api is nil the associations
+// already loaded in the route tables of the Tgw are used, so the walk works offline.
 // There is a limit of 10 hops. If the limit is reached, the function will return an error.
 // TODO: allow the option to increase the depth of the walk, right now is 10.
 func (attPath *AttPath) Walk(ctx context.Context, api ports.AWSRouter, src, dst net.IP) error {
@@ -128,6 +191,7 @@ func (attPath *AttPath) Walk(ctx context.Context, api ports.AWSRouter, src, dst
 	if err != nil {
 		return err
 	}
+	srcAtts[0].Name = attPath.Tgw.GetAttachmentName(srcAtts[0].ID)
 	attPath.addAttachmentToPath(srcAtts[0])
 	attPath.SrcRouteTable = srcRt
 	tgwRt := &srcRt
@@ -136,10 +200,36 @@ func (attPath *AttPath) Walk(ctx context.Context, api ports.AWSRouter, src, dst
 		if err != nil {
 			return err
 		}
+		hop := PathHop{
+			RouteTableID:   tgwRt.ID,
+			RouteTableName: tgwRt.Name,
+			Prefix:         aws.StringValue(route.DestinationCidrBlock),
+			NextHops:       getAttachmentsFromTgwRoute(route),
+			Blackhole:      route.State == types.TransitGatewayRouteStateBlackhole,
+		}
+		for _, att := range hop.NextHops {
+			att.Name = attPath.Tgw.GetAttachmentName(att.ID)
+		}
+		if !hop.Blackhole && len(hop.NextHops) > 0 {
+			if vpn := attPath.Tgw.VpnConnection(hop.NextHops[0]); vpn != nil && vpn.Down() {
+				hop.VpnDown = true
+			}
+		}
+		attPath.Hops = append(attPath.Hops, hop)
 		if route.DestinationCidrBlock == nil {
 			return ErrTgwRouteTableRouteNotFound
 		}
-		nextHopAtt := newTgwAttachment(route.TransitGatewayAttachments[0])
+		if hop.VpnDown {
+			// The traffic leaves the Tgw to the VPN, where it is dropped.
+			if !attPath.isAttachmentInPath(hop.NextHops[0].ID) {
+				attPath.addAttachmentToPath(hop.NextHops[0])
+			}
+			return fmt.Errorf("%w: %s", ErrTgwVpnDown, hop.NextHops[0].ResourceID)
+		}
+		if hop.Dropped() {
+			return ErrTgwRouteBlackhole
+		}
+		nextHopAtt := hop.NextHops[0]
 
 		// Check if the next hop is already the last attachment in the path.
 		// If the nextHopAtt is the last attachment in the path, then we have reached the destination.
@@ -158,29 +248,24 @@ func (attPath *AttPath) Walk(ctx context.Context, api ports.AWSRouter, src, dst
 			return fmt.Errorf("attachment %s is already in the path", nextHopAtt.ID)
 		}
 
-		// Find the route table associated to the attachment
-		// Create a filter for TgwAttachmentInputFilter
-		filter := types.Filter{
-			Name:   aws.String("resource-id"),
-			Values: []string{nextHopAtt.ResourceID},
+		// The routing of the traffic sent to a Cloud WAN core network continues in the core network.
+		if err := attPath.Tgw.leavesToCloudWan(nextHopAtt); err != nil {
+			return err
 		}
-		// Create a filter of type TgwAttachmentInputFilter
-		input := ports.TgwAttachmentInputFilter(filter)
-		// Get the list of TgwRouteTable that match the filter
-		output, err := ports.GetTgwAttachments(ctx, api, input)
+
+		routeTableID, err := associatedRouteTableID(ctx, api, attPath.Tgw, nextHopAtt)
 		if err != nil {
 			return err
 		}
-		if len(output.TransitGatewayAttachments) != 1 {
-			return ErrTgwRouteTableNotFound
-		}
-		routeTableID := *output.TransitGatewayAttachments[0].Association.TransitGatewayRouteTableId
 		if routeTableID == tgwRt.ID {
 			// We reach the destination attachment
 
 			break
 		}
 		tgwRt, err = attPath.Tgw.GetTgwRouteTableByID(routeTableID)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -2,11 +2,13 @@ package awsrouter
 
 import (
 	"context"
+	"errors"
 	"net"
 	"reflect"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/rogerscuall/aws-router/ports"
 )
 
@@ -241,3 +243,112 @@ func TestAttPath_String(t *testing.T) {
 		})
 	}
 }
+
+func TestAttPath_WalkDrop(t *testing.T) {
+	vpcA := types.TransitGatewayRouteAttachment{
+		TransitGatewayAttachmentId: aws.String("tgw-attach-0a"),
+		ResourceId:                 aws.String("vpc-0a"),
+		ResourceType:               "vpc",
+	}
+	tgw := &Tgw{
+		ID:   "tgw-0a",
+		Name: "walk",
+		RouteTables: []*TgwRouteTable{
+			{
+				ID:          "tgw-rtb-0a",
+				Name:        "prod",
+				Attachments: []*TgwAttachment{{ID: "tgw-attach-0a", Name: "vpc-a"}},
+				Routes: []types.TransitGatewayRoute{
+					{
+						DestinationCidrBlock:      aws.String("10.0.0.0/16"),
+						State:                     "active",
+						Type:                      "propagated",
+						TransitGatewayAttachments: []types.TransitGatewayRouteAttachment{vpcA},
+					},
+					{
+						DestinationCidrBlock: aws.String("10.1.0.0/16"),
+						State:                "blackhole",
+						Type:                 "static",
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		dst     string
+		wantErr error
+		want    []PathHop
+	}{
+		{
+			name:    "Blackhole",
+			dst:     "10.1.0.1",
+			wantErr: ErrTgwRouteBlackhole,
+			want:    []PathHop{{RouteTableID: "tgw-rtb-0a", RouteTableName: "prod", Prefix: "10.1.0.0/16", Blackhole: true}},
+		},
+		{
+			name:    "NoRoute",
+			dst:     "192.168.0.1",
+			wantErr: ErrTgwRouteTableRouteNotFound,
+			want:    []PathHop{{RouteTableID: "tgw-rtb-0a", RouteTableName: "prod"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attPath := NewAttPath()
+			attPath.Tgw = tgw
+			err := attPath.Walk(context.TODO(), TgwDescriberImpl{}, net.ParseIP("10.0.0.1"), net.ParseIP(tt.dst))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AttPath.Walk() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(attPath.Hops, tt.want) {
+				t.Errorf("AttPath.Walk() hops = %+v, want %+v", attPath.Hops, tt.want)
+			}
+			if got := attPath.String(); got != "tgw-attach-0a" {
+				t.Errorf("AttPath.Walk() path = %v, want tgw-attach-0a", got)
+			}
+			if got := attPath.Path[0].Name; got != "vpc-a" {
+				t.Errorf("AttPath.Walk() source name = %v, want vpc-a", got)
+			}
+		})
+	}
+}
+
+func TestAttPath_WalkOffline(t *testing.T) {
+	route := func(cidr, id, vpc string) types.TransitGatewayRoute {
+		return types.TransitGatewayRoute{
+			DestinationCidrBlock: aws.String(cidr),
+			State:                "active",
+			Type:                 "propagated",
+			TransitGatewayAttachments: []types.TransitGatewayRouteAttachment{
+				{TransitGatewayAttachmentId: aws.String(id), ResourceId: aws.String(vpc), ResourceType: "vpc"},
+			},
+		}
+	}
+	routes := []types.TransitGatewayRoute{
+		route("10.0.0.0/16", "tgw-attach-0a", "vpc-0a"),
+		route("10.1.0.0/16", "tgw-attach-0b", "vpc-0b"),
+	}
+	tgw := &Tgw{
+		ID: "tgw-0a",
+		RouteTables: []*TgwRouteTable{
+			{ID: "tgw-rtb-0a", Name: "a", Attachments: []*TgwAttachment{{ID: "tgw-attach-0a"}}, Routes: routes},
+			{ID: "tgw-rtb-0b", Name: "b", Attachments: []*TgwAttachment{{ID: "tgw-attach-0b", Name: "vpc-b"}}, Routes: routes},
+		},
+	}
+	attPath := NewAttPath()
+	attPath.Tgw = tgw
+	if err := attPath.Walk(context.TODO(), nil, net.ParseIP("10.0.0.1"), net.ParseIP("10.1.0.1")); err != nil {
+		t.Fatalf("AttPath.Walk() error = %v", err)
+	}
+	if got, want := attPath.String(), "tgw-attach-0a -> tgw-attach-0b"; got != want {
+		t.Errorf("AttPath.Walk() path = %v, want %v", got, want)
+	}
+	var hops []string
+	for _, hop := range attPath.Hops {
+		hops = append(hops, hop.RouteTableName+" "+hop.Prefix+" "+hop.NextHops[0].Name)
+	}
+	if want := []string{"a 10.1.0.0/16 vpc-b", "b 10.1.0.0/16 vpc-b"}; !reflect.DeepEqual(hops, want) {
+		t.Errorf("AttPath.Walk() hops = %v, want %v", hops, want)
+	}
+}
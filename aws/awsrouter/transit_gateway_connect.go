@@ -0,0 +1,153 @@
+package awsrouter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/rogerscuall/aws-router/ports"
+)
+
+// TgwConnect is a Connect attachment of the Tgw, the GRE tunnels to the appliances of an SD-WAN.
+// ID is the ID of the Connect attachment and TransportAttachmentID the ID of the VPC or Direct Connect attachment it runs over.
+type TgwConnect struct {
+	ID                    string
+	TransportAttachmentID string
+	State                 string
+	Protocol              string
+	Peers                 []*TgwConnectPeer
+}
+
+// TgwConnectPeer is a peer of a Connect attachment, a GRE tunnel between TgwAddress and PeerAddress with its BGP sessions.
+type TgwConnectPeer struct {
+	ID               string
+	State            string
+	PeerAddress      string
+	TgwAddress       string
+	InsideCidrBlocks []string
+	BgpSessions      []BgpSession
+}
+
+// BgpSession is a BGP session of a Connect peer, the addresses are inside the GRE tunnel.
+type BgpSession struct {
+	PeerAddress string
+	PeerAsn     int64
+	TgwAddress  string
+	TgwAsn      int64
+	Status      string
+}
+
+// Up reports if one of the BGP sessions of the peer is up.
+func (p *TgwConnectPeer) Up() bool {
+	for _, session := range p.BgpSessions {
+		if session.Status == string(types.BgpStatusUp) {
+			return true
+		}
+	}
+	return false
+}
+
+// PeersUp returns the number of peers with a BGP session up.
+func (c *TgwConnect) PeersUp() int {
+	n := 0
+	for _, peer := range c.Peers {
+		if peer.Up() {
+			n++
+		}
+	}
+	return n
+}
+
+// UpdateConnects updates the Connects of the Tgw with its Connect attachments and their peers.
+// On error the Connects are left unknown.
+func (t *Tgw) UpdateConnects(ctx context.Context, api ports.AWSRouter) error {
+	var connects []types.TransitGatewayConnect
+	nextToken := ""
+	for {
+		result, err := ports.GetTgwConnects(ctx, api, ports.TgwConnectInputFilter(t.ID, nextToken))
+		if err != nil {
+			t.Connects = nil
+			return fmt.Errorf("error retrieving the Connect attachments of %s: %w", t.Name, err)
+		}
+		connects = append(connects, result.TransitGatewayConnects...)
+		nextToken = aws.StringValue(result.NextToken)
+		if nextToken == "" {
+			break
+		}
+	}
+	// The peers are filtered by the Connect attachments, without them there is nothing to ask.
+	attachmentIDs := make([]string, 0, len(connects))
+	for _, connect := range connects {
+		attachmentIDs = append(attachmentIDs, aws.StringValue(connect.TransitGatewayAttachmentId))
+	}
+	var peers []types.TransitGatewayConnectPeer
+	for len(attachmentIDs) > 0 {
+		result, err := ports.GetTgwConnectPeers(ctx, api, ports.TgwConnectPeerInputFilter(attachmentIDs, nextToken))
+		if err != nil {
+			t.Connects = nil
+			return fmt.Errorf("error retrieving the Connect peers of %s: %w", t.Name, err)
+		}
+		peers = append(peers, result.TransitGatewayConnectPeers...)
+		nextToken = aws.StringValue(result.NextToken)
+		if nextToken == "" {
+			break
+		}
+	}
+	t.Connects = []*TgwConnect{}
+	for _, connect := range connects {
+		c := &TgwConnect{
+			ID:                    aws.StringValue(connect.TransitGatewayAttachmentId),
+			TransportAttachmentID: aws.StringValue(connect.TransportTransitGatewayAttachmentId),
+			State:                 string(connect.State),
+		}
+		if connect.Options != nil {
+			c.Protocol = string(connect.Options.Protocol)
+		}
+		t.Connects = append(t.Connects, c)
+	}
+	sort.Slice(t.Connects, func(i, j int) bool { return t.Connects[i].ID < t.Connects[j].ID })
+	for _, peer := range peers {
+		c := t.connect(aws.StringValue(peer.TransitGatewayAttachmentId))
+		if c == nil {
+			continue
+		}
+		p := &TgwConnectPeer{ID: aws.StringValue(peer.TransitGatewayConnectPeerId), State: string(peer.State)}
+		if config := peer.ConnectPeerConfiguration; config != nil {
+			p.PeerAddress = aws.StringValue(config.PeerAddress)
+			p.TgwAddress = aws.StringValue(config.TransitGatewayAddress)
+			p.InsideCidrBlocks = config.InsideCidrBlocks
+			for _, bgp := range config.BgpConfigurations {
+				p.BgpSessions = append(p.BgpSessions, BgpSession{
+					PeerAddress: aws.StringValue(bgp.PeerAddress),
+					PeerAsn:     aws.Int64Value(bgp.PeerAsn),
+					TgwAddress:  aws.StringValue(bgp.TransitGatewayAddress),
+					TgwAsn:      aws.Int64Value(bgp.TransitGatewayAsn),
+					Status:      string(bgp.BgpStatus),
+				})
+			}
+			sort.Slice(p.BgpSessions, func(i, j int) bool { return p.BgpSessions[i].TgwAddress < p.BgpSessions[j].TgwAddress })
+		}
+		c.Peers = append(c.Peers, p)
+	}
+	for _, c := range t.Connects {
+		sort.Slice(c.Peers, func(i, j int) bool { return c.Peers[i].ID < c.Peers[j].ID })
+	}
+	return nil
+}
+
+// connect returns the Connect attachment with the ID id, or nil if it is unknown.
+func (t *Tgw) connect(id string) *TgwConnect {
+	for _, c := range t.Connects {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// Connect returns the Connect attachment of the attachment att, or nil if it is not a Connect attachment or it is unknown.
+func (t *Tgw) Connect(att *TgwAttachment) *TgwConnect {
+	return t.connect(att.ID)
+}
@@ -0,0 +1,64 @@
+package awsrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTgw_UpdateConnects(t *testing.T) {
+	tgw := &Tgw{ID: "tgw-0a", Name: "core"}
+	if err := tgw.UpdateConnects(context.Background(), TgwDescriberImpl{}); err != nil {
+		t.Fatalf("UpdateConnects() error = %v", err)
+	}
+	want := []*TgwConnect{
+		{
+			ID:                    "tgw-attach-0c",
+			TransportAttachmentID: "tgw-attach-0t",
+			State:                 "available",
+			Protocol:              "gre",
+			Peers: []*TgwConnectPeer{
+				{
+					ID:               "tgw-connect-peer-0a",
+					State:            "available",
+					PeerAddress:      "10.2.0.10",
+					TgwAddress:       "192.0.2.1",
+					InsideCidrBlocks: []string{"169.254.5.0/29"},
+					BgpSessions: []BgpSession{
+						{PeerAddress: "169.254.5.1", PeerAsn: 65001, TgwAddress: "169.254.5.2", TgwAsn: 64512, Status: "up"},
+					},
+				},
+				{
+					ID:               "tgw-connect-peer-0b",
+					State:            "available",
+					PeerAddress:      "10.2.0.11",
+					TgwAddress:       "192.0.2.2",
+					InsideCidrBlocks: []string{"169.254.6.0/29"},
+					BgpSessions: []BgpSession{
+						{PeerAddress: "169.254.6.1", PeerAsn: 65001, TgwAddress: "169.254.6.2", TgwAsn: 64512, Status: "down"},
+						{PeerAddress: "169.254.6.1", PeerAsn: 65001, TgwAddress: "169.254.6.3", TgwAsn: 64512, Status: "down"},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, tgw.Connects); diff != "" {
+		t.Errorf("Connects mismatch (-want +got):\n%s", diff)
+	}
+	if got := tgw.Connects[0].PeersUp(); got != 1 {
+		t.Errorf("PeersUp() = %d, want 1", got)
+	}
+	tests := []struct {
+		att  *TgwAttachment
+		want *TgwConnect
+	}{
+		{att: &TgwAttachment{ID: "tgw-attach-0c", ResourceID: "tgw-attach-0t", Type: "connect"}, want: want[0]},
+		{att: &TgwAttachment{ID: "tgw-attach-0t", ResourceID: "vpc-0t", Type: "vpc"}, want: nil},
+	}
+	for _, tt := range tests {
+		if diff := cmp.Diff(tt.want, tgw.Connect(tt.att)); diff != "" {
+			t.Errorf("Connect(%s) mismatch (-want +got):\n%s", tt.att.ID, diff)
+		}
+	}
+}
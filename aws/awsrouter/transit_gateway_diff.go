@@ -0,0 +1,238 @@
+package awsrouter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// ChangeKind is the kind of a Change between two versions of the routing.
+type ChangeKind string
+
+const (
+	ChangeTgwAdded          ChangeKind = "tgw-added"
+	ChangeTgwRemoved        ChangeKind = "tgw-removed"
+	ChangeRouteTableAdded   ChangeKind = "route-table-added"
+	ChangeRouteTableRemoved ChangeKind = "route-table-removed"
+	ChangeRouteAdded        ChangeKind = "route-added"
+	ChangeRouteRemoved      ChangeKind = "route-removed"
+	// ChangeRouteBlackhole is a route that was active and is now a blackhole.
+	ChangeRouteBlackhole ChangeKind = "route-blackhole"
+	// ChangeRouteActive is a route that was a blackhole and is now active.
+	ChangeRouteActive ChangeKind = "route-active"
+	// ChangeRouteNextHops is a route with different next hop attachments.
+	ChangeRouteNextHops     ChangeKind = "route-next-hops-changed"
+	ChangeAttachmentAdded   ChangeKind = "attachment-added"
+	ChangeAttachmentRemoved ChangeKind = "attachment-removed"
+	// ChangeAssociation is an attachment associated to a different route table, or to none.
+	ChangeAssociation ChangeKind = "association-changed"
+)
+
+// Change is a difference between two versions of the routing of a Transit Gateway.
+// Old and New are the values before and after the change, like the state of a route or the associated route table,
+// they are empty when they do not apply.
+type Change struct {
+	Kind         ChangeKind
+	TgwID        string
+	TgwName      string
+	RouteTableID string
+	AttachmentID string
+	Prefix       string
+	Old          string
+	New          string
+	Message      string
+}
+
+// DiffTgws returns the changes from the routing in old to the routing in new.
+// The changes are sorted by TGW, then the route tables with their routes and then the attachments.
+// The routes of a route table added or removed are not reported one by one.
+func DiffTgws(old, new []*Tgw) []Change {
+	var ids []string
+	oldTgws := make(map[string]*Tgw)
+	for _, tgw := range old {
+		oldTgws[tgw.ID] = tgw
+		ids = append(ids, tgw.ID)
+	}
+	newTgws := make(map[string]*Tgw)
+	for _, tgw := range new {
+		newTgws[tgw.ID] = tgw
+		ids = append(ids, tgw.ID)
+	}
+	var changes []Change
+	for _, id := range sortedUnique(ids) {
+		o, n := oldTgws[id], newTgws[id]
+		switch {
+		case o == nil:
+			changes = append(changes, Change{Kind: ChangeTgwAdded, TgwID: n.ID, TgwName: n.Name, Message: fmt.Sprintf("transit gateway %s was added", n.Name)})
+		case n == nil:
+			changes = append(changes, Change{Kind: ChangeTgwRemoved, TgwID: o.ID, TgwName: o.Name, Message: fmt.Sprintf("transit gateway %s was removed", o.Name)})
+		default:
+			changes = append(changes, diffTgw(o, n)...)
+		}
+	}
+	return changes
+}
+
+// diffTgw returns the changes between two versions of the same TGW.
+func diffTgw(old, new *Tgw) []Change {
+	var changes []Change
+	add := func(c Change) {
+		c.TgwID, c.TgwName = new.ID, new.Name
+		changes = append(changes, c)
+	}
+
+	var rtIDs []string
+	oldRts := make(map[string]*TgwRouteTable)
+	for _, rt := range old.RouteTables {
+		oldRts[rt.ID] = rt
+		rtIDs = append(rtIDs, rt.ID)
+	}
+	newRts := make(map[string]*TgwRouteTable)
+	for _, rt := range new.RouteTables {
+		newRts[rt.ID] = rt
+		rtIDs = append(rtIDs, rt.ID)
+	}
+	for _, id := range sortedUnique(rtIDs) {
+		o, n := oldRts[id], newRts[id]
+		switch {
+		case o == nil:
+			add(Change{Kind: ChangeRouteTableAdded, RouteTableID: id, Message: fmt.Sprintf("route table %s was added", n.Name)})
+		case n == nil:
+			add(Change{Kind: ChangeRouteTableRemoved, RouteTableID: id, Message: fmt.Sprintf("route table %s was removed", o.Name)})
+		default:
+			for _, c := range diffRoutes(o, n) {
+				add(c)
+			}
+		}
+	}
+
+	var attIDs []string
+	oldAtts := make(map[string]*TgwAttachment)
+	for _, att := range old.Attachments() {
+		oldAtts[att.ID] = att
+		attIDs = append(attIDs, att.ID)
+	}
+	newAtts := make(map[string]*TgwAttachment)
+	for _, att := range new.Attachments() {
+		newAtts[att.ID] = att
+		attIDs = append(attIDs, att.ID)
+	}
+	for _, id := range sortedUnique(attIDs) {
+		o, n := oldAtts[id], newAtts[id]
+		switch {
+		case o == nil:
+			add(Change{Kind: ChangeAttachmentAdded, AttachmentID: id, New: n.ResourceID, Message: fmt.Sprintf("attachment %s to %s %s was added", attachmentName(n), n.Type, n.ResourceID)})
+			continue
+		case n == nil:
+			add(Change{Kind: ChangeAttachmentRemoved, AttachmentID: id, Old: o.ResourceID, Message: fmt.Sprintf("attachment %s to %s %s was removed", attachmentName(o), o.Type, o.ResourceID)})
+			continue
+		}
+		oldRt, newRt := associatedID(old, id), associatedID(new, id)
+		if oldRt != newRt {
+			add(Change{
+				Kind:         ChangeAssociation,
+				AttachmentID: id,
+				RouteTableID: newRt,
+				Old:          oldRt,
+				New:          newRt,
+				Message:      fmt.Sprintf("attachment %s association changed from %s to %s", attachmentName(n), noneIfEmpty(oldRt), noneIfEmpty(newRt)),
+			})
+		}
+	}
+	return changes
+}
+
+// diffRoutes returns the changes between the routes of two versions of the same route table.
+func diffRoutes(old, new *TgwRouteTable) []Change {
+	oldRoutes, oldDsts := routesByDestination(old.Routes)
+	newRoutes, newDsts := routesByDestination(new.Routes)
+	var changes []Change
+	for _, dst := range sortedUnique(append(oldDsts, newDsts...)) {
+		o, n := oldRoutes[dst], newRoutes[dst]
+		c := Change{RouteTableID: new.ID, Prefix: dst}
+		switch {
+		case o == nil:
+			c.Kind, c.New = ChangeRouteAdded, nextHops(*n)
+			c.Message = fmt.Sprintf("route %s to %s was added to %s", dst, noneIfEmpty(c.New), new.Name)
+		case n == nil:
+			c.Kind, c.Old = ChangeRouteRemoved, nextHops(*o)
+			c.Message = fmt.Sprintf("route %s to %s was removed from %s", dst, noneIfEmpty(c.Old), new.Name)
+		case o.State != types.TransitGatewayRouteStateBlackhole && n.State == types.TransitGatewayRouteStateBlackhole:
+			c.Kind, c.Old, c.New = ChangeRouteBlackhole, string(o.State), string(n.State)
+			c.Message = fmt.Sprintf("route %s in %s is now a blackhole", dst, new.Name)
+		case o.State == types.TransitGatewayRouteStateBlackhole && n.State != types.TransitGatewayRouteStateBlackhole:
+			c.Kind, c.Old, c.New = ChangeRouteActive, string(o.State), string(n.State)
+			c.Message = fmt.Sprintf("route %s in %s is no longer a blackhole", dst, new.Name)
+		case nextHops(*o) != nextHops(*n):
+			c.Kind, c.Old, c.New = ChangeRouteNextHops, nextHops(*o), nextHops(*n)
+			c.Message = fmt.Sprintf("route %s in %s changed next hops from %s to %s", dst, new.Name, noneIfEmpty(c.Old), noneIfEmpty(c.New))
+		default:
+			continue
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// routesByDestination returns the routes by their destination, the CIDR or the prefix list ID, and the list of destinations.
+func routesByDestination(routes []types.TransitGatewayRoute) (map[string]*types.TransitGatewayRoute, []string) {
+	result := make(map[string]*types.TransitGatewayRoute, len(routes))
+	dsts := make([]string, 0, len(routes))
+	for i, route := range routes {
+		dst := aws.StringValue(route.DestinationCidrBlock)
+		if dst == "" {
+			dst = aws.StringValue(route.PrefixListId)
+		}
+		result[dst] = &routes[i]
+		dsts = append(dsts, dst)
+	}
+	return result, dsts
+}
+
+// nextHops returns the sorted IDs of the next hop attachments of the route separated by commas.
+func nextHops(route types.TransitGatewayRoute) string {
+	ids := make([]string, 0, len(route.TransitGatewayAttachments))
+	for _, att := range route.TransitGatewayAttachments {
+		ids = append(ids, aws.StringValue(att.TransitGatewayAttachmentId))
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+// associatedID returns the ID of the route table where the attachment is associated, or empty if it is not associated.
+func associatedID(tgw *Tgw, attachmentID string) string {
+	if rt := tgw.AssociatedRouteTable(attachmentID); rt != nil {
+		return rt.ID
+	}
+	return ""
+}
+
+// attachmentName returns the name of the attachment, or the ID if it has no name.
+func attachmentName(att *TgwAttachment) string {
+	if att.Name != "" {
+		return att.Name
+	}
+	return att.ID
+}
+
+func noneIfEmpty(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// sortedUnique returns the strings sorted and without duplicates.
+func sortedUnique(ss []string) []string {
+	sort.Strings(ss)
+	result := ss[:0]
+	for i, s := range ss {
+		if i == 0 || s != ss[i-1] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package awsrouter
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/go-cmp/cmp"
+)
+
+// diffRoute returns a route to cidr in state with the next hops atts.
+func diffRoute(cidr string, state types.TransitGatewayRouteState, atts ...string) types.TransitGatewayRoute {
+	r := types.TransitGatewayRoute{DestinationCidrBlock: aws.String(cidr), State: state, Type: "propagated"}
+	for _, id := range atts {
+		r.TransitGatewayAttachments = append(r.TransitGatewayAttachments, types.TransitGatewayRouteAttachment{
+			TransitGatewayAttachmentId: aws.String(id),
+			ResourceId:                 aws.String("vpc-" + id),
+			ResourceType:               "vpc",
+		})
+	}
+	return r
+}
+
+func TestDiffTgws(t *testing.T) {
+	attA := &TgwAttachment{ID: "tgw-attach-0a", Name: "vpc-a", ResourceID: "vpc-0a", Type: "vpc"}
+	attB := &TgwAttachment{ID: "tgw-attach-0b", Name: "vpc-b", ResourceID: "vpc-0b", Type: "vpc"}
+	attC := &TgwAttachment{ID: "tgw-attach-0c", ResourceID: "vpc-0c", Type: "vpc"}
+	old := []*Tgw{
+		{
+			ID:   "tgw-0a",
+			Name: "core",
+			RouteTables: []*TgwRouteTable{
+				{
+					ID:          "tgw-rtb-0a",
+					Name:        "spokes",
+					Attachments: []*TgwAttachment{attA, attB},
+					Routes: []types.TransitGatewayRoute{
+						diffRoute("10.0.0.0/16", "active", "tgw-attach-0a"),
+						diffRoute("10.1.0.0/16", "active", "tgw-attach-0b"),
+						diffRoute("10.2.0.0/16", "active", "tgw-attach-0b"),
+						diffRoute("10.3.0.0/16", "blackhole"),
+						diffRoute("10.4.0.0/16", "active", "tgw-attach-0a"),
+					},
+				},
+				{ID: "tgw-rtb-0z", Name: "old"},
+			},
+		},
+		{ID: "tgw-0z", Name: "removed"},
+	}
+	new := []*Tgw{
+		{ID: "tgw-0b", Name: "added"},
+		{
+			ID:   "tgw-0a",
+			Name: "core",
+			RouteTables: []*TgwRouteTable{
+				{ID: "tgw-rtb-0b", Name: "shared", Attachments: []*TgwAttachment{attB}},
+				{
+					ID:          "tgw-rtb-0a",
+					Name:        "spokes",
+					Attachments: []*TgwAttachment{attA, attC},
+					Routes: []types.TransitGatewayRoute{
+						// Same routes in a different order are not a change.
+						diffRoute("10.5.0.0/16", "active", "tgw-attach-0c"),
+						diffRoute("10.4.0.0/16", "active", "tgw-attach-0c", "tgw-attach-0a"),
+						diffRoute("10.3.0.0/16", "active", "tgw-attach-0a"),
+						diffRoute("10.2.0.0/16", "blackhole"),
+						diffRoute("10.0.0.0/16", "active", "tgw-attach-0a"),
+					},
+				},
+			},
+		},
+	}
+	want := []Change{
+		{Kind: ChangeRouteRemoved, TgwID: "tgw-0a", TgwName: "core", RouteTableID: "tgw-rtb-0a", Prefix: "10.1.0.0/16", Old: "tgw-attach-0b", Message: "route 10.1.0.0/16 to tgw-attach-0b was removed from spokes"},
+		{Kind: ChangeRouteBlackhole, TgwID: "tgw-0a", TgwName: "core", RouteTableID: "tgw-rtb-0a", Prefix: "10.2.0.0/16", Old: "active", New: "blackhole", Message: "route 10.2.0.0/16 in spokes is now a blackhole"},
+		{Kind: ChangeRouteActive, TgwID: "tgw-0a", TgwName: "core", RouteTableID: "tgw-rtb-0a", Prefix: "10.3.0.0/16", Old: "blackhole", New: "active", Message: "route 10.3.0.0/16 in spokes is no longer a blackhole"},
+		{Kind: ChangeRouteNextHops, TgwID: "tgw-0a", TgwName: "core", RouteTableID: "tgw-rtb-0a", Prefix: "10.4.0.0/16", Old: "tgw-attach-0a", New: "tgw-attach-0a,tgw-attach-0c", Message: "route 10.4.0.0/16 in spokes changed next hops from tgw-attach-0a to tgw-attach-0a,tgw-attach-0c"},
+		{Kind: ChangeRouteAdded, TgwID: "tgw-0a", TgwName: "core", RouteTableID: "tgw-rtb-0a", Prefix: "10.5.0.0/16", New: "tgw-attach-0c", Message: "route 10.5.0.0/16 to tgw-attach-0c was added to spokes"},
+		{Kind: ChangeRouteTableAdded, TgwID: "tgw-0a", TgwName: "core", RouteTableID: "tgw-rtb-0b", Message: "route table shared was added"},
+		{Kind: ChangeRouteTableRemoved, TgwID: "tgw-0a", TgwName: "core", RouteTableID: "tgw-rtb-0z", Message: "route table old was removed"},
+		{Kind: ChangeAssociation, TgwID: "tgw-0a", TgwName: "core", RouteTableID: "tgw-rtb-0b", AttachmentID: "tgw-attach-0b", Old: "tgw-rtb-0a", New: "tgw-rtb-0b", Message: "attachment vpc-b association changed from tgw-rtb-0a to tgw-rtb-0b"},
+		{Kind: ChangeAttachmentAdded, TgwID: "tgw-0a", TgwName: "core", AttachmentID: "tgw-attach-0c", New: "vpc-0c", Message: "attachment tgw-attach-0c to vpc vpc-0c was added"},
+		{Kind: ChangeTgwAdded, TgwID: "tgw-0b", TgwName: "added", Message: "transit gateway added was added"},
+		{Kind: ChangeTgwRemoved, TgwID: "tgw-0z", TgwName: "removed", Message: "transit gateway removed was removed"},
+	}
+	if diff := cmp.Diff(want, DiffTgws(old, new)); diff != "" {
+		t.Errorf("DiffTgws() mismatch (-want +got):\n%s", diff)
+	}
+	if got := DiffTgws(new, new); len(got) != 0 {
+		t.Errorf("DiffTgws() of the same routing = %v, want no changes", got)
+	}
+}
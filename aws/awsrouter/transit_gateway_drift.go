@@ -0,0 +1,315 @@
+package awsrouter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"gopkg.in/yaml.v3"
+)
+
+// Intent is the declared routing of some route tables, read from an intent file or from a Terraform state.
+type Intent struct {
+	RouteTables []IntentRouteTable `yaml:"route_tables"`
+}
+
+// IntentRouteTable is the declared routing of a route table. Tgw, RouteTable and the attachments are IDs or names,
+// Tgw can be empty when the route table is unique. A nil list is not managed by the intent and is not compared,
+// an empty list declares that the route table has none.
+type IntentRouteTable struct {
+	Tgw          string        `yaml:"tgw"`
+	RouteTable   string        `yaml:"route_table"`
+	StaticRoutes []IntentRoute `yaml:"static_routes"`
+	Associations []string      `yaml:"associations"`
+	Propagations []string      `yaml:"propagations"`
+}
+
+// IntentRoute is a declared static route, Destination is a CIDR or a prefix list ID.
+type IntentRoute struct {
+	Destination string `yaml:"destination"`
+	Attachment  string `yaml:"attachment"`
+	Blackhole   bool   `yaml:"blackhole"`
+}
+
+// ReadIntent reads an intent file, a Terraform state in JSON or the YAML intent schema.
+func ReadIntent(r io.Reader) (*Intent, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the intent: %w", err)
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return ReadTerraformState(bytes.NewReader(data))
+	}
+	var intent Intent
+	if err := yaml.Unmarshal(data, &intent); err != nil {
+		return nil, fmt.Errorf("error reading the intent: %w", err)
+	}
+	return &intent, nil
+}
+
+// terraformState is the part of a Terraform state used for the intent.
+type terraformState struct {
+	Resources []struct {
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Instances []struct {
+			Attributes struct {
+				ID                         string `json:"id"`
+				TransitGatewayID           string `json:"transit_gateway_id"`
+				TransitGatewayRouteTableID string `json:"transit_gateway_route_table_id"`
+				TransitGatewayAttachmentID string `json:"transit_gateway_attachment_id"`
+				DestinationCidrBlock       string `json:"destination_cidr_block"`
+				PrefixListID               string `json:"prefix_list_id"`
+				Blackhole                  bool   `json:"blackhole"`
+			} `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// ReadTerraformState reads the intent from the managed resources of a Terraform state in JSON, like the ones
+// written by export terraform. Every route table in the state manages its static routes, its associations and
+// propagations are managed only if the state has at least one of them for the route table, so the ones managed
+// outside of the state are not reported as unexpected.
+func ReadTerraformState(r io.Reader) (*Intent, error) {
+	var state terraformState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return nil, fmt.Errorf("error reading the terraform state: %w", err)
+	}
+	intent := &Intent{}
+	index := make(map[string]int)
+	routeTable := func(id, tgwID string) *IntentRouteTable {
+		i, ok := index[id]
+		if !ok {
+			i = len(intent.RouteTables)
+			index[id] = i
+			intent.RouteTables = append(intent.RouteTables, IntentRouteTable{
+				RouteTable:   id,
+				StaticRoutes: []IntentRoute{},
+			})
+		}
+		rt := &intent.RouteTables[i]
+		if tgwID != "" {
+			rt.Tgw = tgwID
+		}
+		return rt
+	}
+	for _, resource := range state.Resources {
+		if resource.Mode != "managed" {
+			continue
+		}
+		for _, instance := range resource.Instances {
+			a := instance.Attributes
+			switch resource.Type {
+			case "aws_ec2_transit_gateway_route_table":
+				routeTable(a.ID, a.TransitGatewayID)
+			case "aws_ec2_transit_gateway_route":
+				rt := routeTable(a.TransitGatewayRouteTableID, "")
+				rt.StaticRoutes = append(rt.StaticRoutes, IntentRoute{Destination: a.DestinationCidrBlock, Attachment: a.TransitGatewayAttachmentID, Blackhole: a.Blackhole})
+			case "aws_ec2_transit_gateway_prefix_list_reference":
+				rt := routeTable(a.TransitGatewayRouteTableID, "")
+				rt.StaticRoutes = append(rt.StaticRoutes, IntentRoute{Destination: a.PrefixListID, Attachment: a.TransitGatewayAttachmentID, Blackhole: a.Blackhole})
+			case "aws_ec2_transit_gateway_route_table_association":
+				rt := routeTable(a.TransitGatewayRouteTableID, "")
+				rt.Associations = append(rt.Associations, a.TransitGatewayAttachmentID)
+			case "aws_ec2_transit_gateway_route_table_propagation":
+				rt := routeTable(a.TransitGatewayRouteTableID, "")
+				rt.Propagations = append(rt.Propagations, a.TransitGatewayAttachmentID)
+			}
+		}
+	}
+	return intent, nil
+}
+
+// DriftKind is the kind of a difference between the routing and the intent.
+type DriftKind string
+
+const (
+	// DriftMissing is declared in the intent but it is not in AWS.
+	DriftMissing DriftKind = "missing"
+	// DriftUnexpected is in AWS but it is not declared in the intent.
+	DriftUnexpected DriftKind = "unexpected"
+	// DriftChanged is a static route in AWS and in the intent with a different next hop.
+	DriftChanged DriftKind = "changed"
+)
+
+// DriftResource is the kind of resource of a Drift.
+type DriftResource string
+
+const (
+	DriftRouteTable  DriftResource = "route-table"
+	DriftStaticRoute DriftResource = "static-route"
+	DriftAssociation DriftResource = "association"
+	DriftPropagation DriftResource = "propagation"
+)
+
+// Drift is a difference between the routing and the intent. Key is the destination of a static route
+// or the ID of the attachment of an association or a propagation. Declared and Actual are the next hops
+// of a static route, the ID of an attachment or blackhole.
+type Drift struct {
+	Kind           DriftKind
+	Resource       DriftResource
+	TgwID          string
+	TgwName        string
+	RouteTableID   string
+	RouteTableName string
+	Key            string
+	Declared       string
+	Actual         string
+	Message        string
+}
+
+// FindDrift compares the route tables declared in the intent with the routing in tgws.
+// The route tables of a Tgw with a declared route table that are not in the intent are unexpected.
+// When the propagations of a route table are unknown they are inferred from its propagated routes.
+// The drifts follow the order of the intent, the static routes sorted by destination, then the associations and propagations by ID.
+func FindDrift(tgws []*Tgw, intent *Intent) ([]Drift, error) {
+	var drifts []Drift
+	declared := make(map[*TgwRouteTable]bool)
+	seen := make(map[*Tgw]bool)
+	var declaredTgws []*Tgw
+	for _, irt := range intent.RouteTables {
+		tgw, rt, err := FindRouteTable(tgws, irt.Tgw, irt.RouteTable)
+		if errors.Is(err, ErrTgwRouteTableNotFound) {
+			d := Drift{Kind: DriftMissing, Resource: DriftRouteTable, RouteTableID: irt.RouteTable, Key: irt.RouteTable}
+			if tgw != nil {
+				d.TgwID, d.TgwName = tgw.ID, tgw.Name
+			}
+			d.Message = fmt.Sprintf("route table %s is declared but it is not in AWS", irt.RouteTable)
+			drifts = append(drifts, d)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if declared[rt] {
+			return nil, fmt.Errorf("route table %s is declared more than once", rt.Name)
+		}
+		declared[rt] = true
+		if !seen[tgw] {
+			seen[tgw] = true
+			declaredTgws = append(declaredTgws, tgw)
+		}
+		drifts = append(drifts, tgw.routeTableDrift(rt, irt)...)
+	}
+	for _, tgw := range declaredTgws {
+		for _, rt := range tgw.RouteTables {
+			if declared[rt] {
+				continue
+			}
+			drifts = append(drifts, Drift{
+				Kind: DriftUnexpected, Resource: DriftRouteTable,
+				TgwID: tgw.ID, TgwName: tgw.Name, RouteTableID: rt.ID, RouteTableName: rt.Name, Key: rt.ID,
+				Message: fmt.Sprintf("route table %s is in AWS but it is not declared", rt.Name),
+			})
+		}
+	}
+	return drifts, nil
+}
+
+// routeTableDrift compares the route table rt with its declaration irt.
+func (t *Tgw) routeTableDrift(rt *TgwRouteTable, irt IntentRouteTable) []Drift {
+	var drifts []Drift
+	drift := func(kind DriftKind, resource DriftResource, key, declared, actual, message string) {
+		drifts = append(drifts, Drift{
+			Kind: kind, Resource: resource,
+			TgwID: t.ID, TgwName: t.Name, RouteTableID: rt.ID, RouteTableName: rt.Name,
+			Key: key, Declared: declared, Actual: actual, Message: message,
+		})
+	}
+
+	if irt.StaticRoutes != nil {
+		actual := make(map[string]string)
+		for _, route := range staticRoutes(rt) {
+			destination := routeDestination(route)
+			actual[destination] = "blackhole"
+			if len(route.TransitGatewayAttachments) > 0 && route.State != types.TransitGatewayRouteStateBlackhole {
+				actual[destination] = aws.StringValue(route.TransitGatewayAttachments[0].TransitGatewayAttachmentId)
+			}
+		}
+		wanted := make(map[string]string)
+		for _, route := range irt.StaticRoutes {
+			wanted[route.Destination] = "blackhole"
+			if !route.Blackhole {
+				wanted[route.Destination] = t.attachmentID(route.Attachment)
+			}
+		}
+		for _, destination := range sortedKeys(wanted, actual) {
+			w, inIntent := wanted[destination]
+			a, inAWS := actual[destination]
+			switch {
+			case !inAWS:
+				drift(DriftMissing, DriftStaticRoute, destination, w, "",
+					fmt.Sprintf("static route to %s via %s is declared but it is not in AWS", destination, t.nextHopName(w)))
+			case !inIntent:
+				drift(DriftUnexpected, DriftStaticRoute, destination, "", a,
+					fmt.Sprintf("static route to %s via %s is in AWS but it is not declared", destination, t.nextHopName(a)))
+			case w != a:
+				drift(DriftChanged, DriftStaticRoute, destination, w, a,
+					fmt.Sprintf("static route to %s is via %s in AWS but via %s in the intent", destination, t.nextHopName(a), t.nextHopName(w)))
+			}
+		}
+	}
+
+	compare := func(resource DriftResource, declared []string, actual []*TgwAttachment) {
+		if declared == nil {
+			return
+		}
+		wanted := make(map[string]string)
+		for _, id := range declared {
+			wanted[t.attachmentID(id)] = ""
+		}
+		inAWS := make(map[string]string)
+		for _, att := range actual {
+			inAWS[att.ID] = ""
+		}
+		for _, id := range sortedKeys(wanted, inAWS) {
+			_, w := wanted[id]
+			_, a := inAWS[id]
+			switch {
+			case !a:
+				drift(DriftMissing, resource, id, id, "", fmt.Sprintf("%s of attachment %s is declared but it is not in AWS", resource, t.attachmentName(id)))
+			case !w:
+				drift(DriftUnexpected, resource, id, "", id, fmt.Sprintf("%s of attachment %s is in AWS but it is not declared", resource, t.attachmentName(id)))
+			}
+		}
+	}
+	compare(DriftAssociation, irt.Associations, rt.Attachments)
+	propagations, _ := t.routeTablePropagations(rt)
+	compare(DriftPropagation, irt.Propagations, propagations)
+	return drifts
+}
+
+// attachmentID returns the ID of the attachment with the ID or name id, or id if the Tgw has no such attachment.
+func (t *Tgw) attachmentID(id string) string {
+	if att := t.findAttachment(id); att != nil {
+		return att.ID
+	}
+	return id
+}
+
+// nextHopName returns the name of the attachment of a next hop, or blackhole.
+func (t *Tgw) nextHopName(nextHop string) string {
+	if nextHop == "blackhole" {
+		return nextHop
+	}
+	return t.attachmentName(nextHop)
+}
+
+// sortedKeys returns the keys of a and b sorted with lessDestination.
+func sortedKeys(a, b map[string]string) []string {
+	var keys []string
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		keys = append(keys, k)
+	}
+	keys = sortedUnique(keys)
+	sort.Slice(keys, func(i, j int) bool { return lessDestination(keys[i], keys[j]) })
+	return keys
+}
@@ -0,0 +1,144 @@
+package awsrouter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadIntent(t *testing.T) {
+	yamlIntent := `
+route_tables:
+  - tgw: core
+    route_table: spokes
+    static_routes:
+      - destination: 10.5.0.0/16
+        blackhole: true
+    associations: []
+`
+	stateIntent := `{
+  "version": 4,
+  "resources": [
+    {"mode": "data", "type": "aws_ec2_transit_gateway_route_table", "instances": [{"attributes": {"id": "tgw-rtb-0d"}}]},
+    {"mode": "managed", "type": "aws_ec2_transit_gateway_route", "instances": [
+      {"attributes": {"destination_cidr_block": "10.9.0.0/16", "transit_gateway_attachment_id": "tgw-attach-0s", "transit_gateway_route_table_id": "tgw-rtb-0a"}}
+    ]},
+    {"mode": "managed", "type": "aws_ec2_transit_gateway_route_table", "instances": [{"attributes": {"id": "tgw-rtb-0a", "transit_gateway_id": "tgw-0a"}}]},
+    {"mode": "managed", "type": "aws_ec2_transit_gateway_prefix_list_reference", "instances": [
+      {"attributes": {"prefix_list_id": "pl-0a", "blackhole": true, "transit_gateway_route_table_id": "tgw-rtb-0a"}}
+    ]},
+    {"mode": "managed", "type": "aws_ec2_transit_gateway_route_table_association", "instances": [
+      {"attributes": {"transit_gateway_attachment_id": "tgw-attach-0a", "transit_gateway_route_table_id": "tgw-rtb-0a"}}
+    ]},
+    {"mode": "managed", "type": "aws_ec2_transit_gateway_route_table_propagation", "instances": [
+      {"attributes": {"transit_gateway_attachment_id": "tgw-attach-0s", "transit_gateway_route_table_id": "tgw-rtb-0s"}}
+    ]}
+  ]
+}`
+	tests := []struct {
+		name  string
+		input string
+		want  *Intent
+	}{
+		{
+			name:  "yaml",
+			input: yamlIntent,
+			want: &Intent{RouteTables: []IntentRouteTable{{
+				Tgw:          "core",
+				RouteTable:   "spokes",
+				StaticRoutes: []IntentRoute{{Destination: "10.5.0.0/16", Blackhole: true}},
+				Associations: []string{},
+			}}},
+		},
+		{
+			name:  "terraform state",
+			input: stateIntent,
+			want: &Intent{RouteTables: []IntentRouteTable{
+				{
+					Tgw:        "tgw-0a",
+					RouteTable: "tgw-rtb-0a",
+					StaticRoutes: []IntentRoute{
+						{Destination: "10.9.0.0/16", Attachment: "tgw-attach-0s"},
+						{Destination: "pl-0a", Blackhole: true},
+					},
+					Associations: []string{"tgw-attach-0a"},
+				},
+				// The associations of tgw-rtb-0s and the propagations of tgw-rtb-0a are not in the state, so they are not managed.
+				{RouteTable: "tgw-rtb-0s", StaticRoutes: []IntentRoute{}, Propagations: []string{"tgw-attach-0s"}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadIntent(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ReadIntent() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ReadIntent() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFindDrift(t *testing.T) {
+	tests := []struct {
+		name   string
+		intent *Intent
+		want   []string
+	}{
+		{
+			name: "no drift",
+			intent: &Intent{RouteTables: []IntentRouteTable{
+				{
+					RouteTable:   "spokes",
+					StaticRoutes: []IntentRoute{{Destination: "10.9.0.0/16", Attachment: "vpc-s"}},
+					Associations: []string{"vpc-a", "tgw-attach-0b"},
+					Propagations: []string{"vpc-a"},
+				},
+				// The propagations of shared are inferred from its propagated routes.
+				{RouteTable: "tgw-rtb-0s", Propagations: []string{"vpc-a", "vpc-b", "vpc-s"}},
+			}},
+		},
+		{
+			name: "drift",
+			intent: &Intent{RouteTables: []IntentRouteTable{
+				{
+					Tgw:        "core",
+					RouteTable: "spokes",
+					StaticRoutes: []IntentRoute{
+						{Destination: "10.10.0.0/16", Blackhole: true},
+						{Destination: "10.9.0.0/16", Attachment: "vpc-b"},
+					},
+					Associations: []string{"vpc-a"},
+					Propagations: []string{"vpc-a", "vpc-b"},
+				},
+				{RouteTable: "old"},
+			}},
+			want: []string{
+				"changed static-route spokes: static route to 10.9.0.0/16 is via vpc-s in AWS but via vpc-b in the intent",
+				"missing static-route spokes: static route to 10.10.0.0/16 via blackhole is declared but it is not in AWS",
+				"unexpected association spokes: association of attachment vpc-b is in AWS but it is not declared",
+				"missing propagation spokes: propagation of attachment vpc-b is declared but it is not in AWS",
+				"missing route-table : route table old is declared but it is not in AWS",
+				"unexpected route-table shared: route table shared is in AWS but it is not declared",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drifts, err := FindDrift([]*Tgw{propagationTgw()}, tt.intent)
+			if err != nil {
+				t.Fatalf("FindDrift() error = %v", err)
+			}
+			var got []string
+			for _, d := range drifts {
+				got = append(got, string(d.Kind)+" "+string(d.Resource)+" "+d.RouteTableName+": "+d.Message)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("FindDrift() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package awsrouter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const (
+	// excelSummarySheet has the Transit Gateway details and the counters per route table.
+	excelSummarySheet = "Summary"
+	// excelAttachmentsSheet has one row per attachment of the Transit Gateway.
+	excelAttachmentsSheet = "Attachments"
+	// excelMatrixSheet has the associations and propagations of each attachment to each route table.
+	excelMatrixSheet = "Associations"
+	// excelSummaryTableRow is the row of the header of the route table counters in the summary sheet.
+	excelSummaryTableRow = 9
+	// excelMaxSheetName is the maximum length of a sheet name allowed by Excel.
+	excelMaxSheetName = 31
+)
+
+// ExcelSheet maps a route table to the sheet where its routes are exported.
+// The sheet name can differ from the route table name, because Excel limits the length and characters of sheet names.
+type ExcelSheet struct {
+	RouteTableID   string
+	RouteTableName string
+	Sheet          string
+}
+
+// ExcelWorkbook is an Excel file created by ExportTgwRoutesExcel.
+type ExcelWorkbook struct {
+	TgwID   string
+	TgwName string
+	Path    string
+	Sheets  []ExcelSheet
+}
+
+// uniqueName returns name truncated to maxLen characters, if the result is already in used a suffix " (n)" is added.
+// The comparison is case insensitive, like the sheet names in Excel or the file names in Windows and macOS.
+// The returned name is added to used.
+func uniqueName(name string, maxLen int, used map[string]struct{}) string {
+	truncate := func(s string, n int) string {
+		r := []rune(s)
+		if len(r) > n {
+			return string(r[:n])
+		}
+		return s
+	}
+	result := truncate(name, maxLen)
+	for i := 2; ; i++ {
+		if _, ok := used[strings.ToLower(result)]; !ok {
+			break
+		}
+		suffix := fmt.Sprintf(" (%d)", i)
+		result = truncate(name, maxLen-len(suffix)) + suffix
+	}
+	used[strings.ToLower(result)] = struct{}{}
+	return result
+}
+
+// excelSheetName returns a valid and unique sheet name for name.
+// The characters not allowed by Excel are replaced with "_" and the name is truncated to 31 characters.
+func excelSheetName(name string, used map[string]struct{}) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) || r < 32 {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, "'")
+	if name == "" {
+		name = "RouteTable"
+	}
+	return uniqueName(name, excelMaxSheetName, used)
+}
+
+// excelStyles are the IDs of the cell styles used in the workbook.
+type excelStyles struct {
+	header    int
+	link      int
+	blackhole int
+}
+
+func newExcelStyles(f *excelize.File) (excelStyles, error) {
+	var s excelStyles
+	var err error
+	if s.header, err = f.NewStyle(`{"font":{"bold":true,"color":"#FFFFFF"},"fill":{"type":"pattern","color":["#1F4E78"],"pattern":1}}`); err != nil {
+		return s, err
+	}
+	if s.link, err = f.NewStyle(`{"font":{"color":"#1265BE","underline":"single"}}`); err != nil {
+		return s, err
+	}
+	if s.blackhole, err = f.NewStyle(`{"font":{"color":"#C00000","italic":true}}`); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// excelCell returns the axis of a cell, col starts at 0 and row at 1.
+func excelCell(col, row int) string {
+	return fmt.Sprintf("%s%d", excelize.ToAlphaString(col), row)
+}
+
+// excelLocation returns the hyperlink location of a cell in a sheet of the same workbook.
+func excelLocation(sheet string, col, row int) string {
+	return fmt.Sprintf("'%s'!%s", strings.ReplaceAll(sheet, "'", "''"), excelCell(col, row))
+}
+
+// writeExcelTable writes a header at row and the rows below it, the header is styled and an autofilter is added.
+// If freeze is true the rows up to the header are frozen.
+func writeExcelTable(f *excelize.File, sheet string, row int, header []string, rows [][]interface{}, styles excelStyles, freeze bool) error {
+	f.SetSheetRow(sheet, excelCell(0, row), &header)
+	f.SetCellStyle(sheet, excelCell(0, row), excelCell(len(header)-1, row), styles.header)
+	for i := range rows {
+		f.SetSheetRow(sheet, excelCell(0, row+i+1), &rows[i])
+	}
+	f.SetColWidth(sheet, "A", excelize.ToAlphaString(len(header)-1), 22)
+	if freeze {
+		f.SetPanes(sheet, fmt.Sprintf(`{"freeze":true,"split":false,"x_split":0,"y_split":%d,"top_left_cell":"%s","active_pane":"bottomLeft"}`, row, excelCell(0, row+1)))
+	}
+	return f.AutoFilter(sheet, excelCell(0, row), excelCell(len(header)-1, row+len(rows)), "")
+}
+
+// newTgwExcel builds the workbook of a Tgw and returns the sheet used for each route table. The sheets are:
+//   - Summary: Transit Gateway details and the number of routes, blackholes, associations and propagations per route table.
+//   - Attachments: every attachment with its associated route table and the route tables where it propagates.
+//   - Associations: a matrix of attachments and route tables with the associations and propagations.
+//   - One sheet per route table with all the routes, the attachment of each route links to the Attachments sheet.
+//     The first row of the sheet has the original name and ID of the route table.
+func newTgwExcel(tgw *Tgw) (*excelize.File, []ExcelSheet, error) {
+	f := excelize.NewFile()
+	f.SetSheetName("Sheet1", excelSummarySheet)
+	styles, err := newExcelStyles(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating the excel styles: %w", err)
+	}
+	attachments := tgw.Attachments()
+
+	// Sheet names, the fixed sheets and History (reserved by Excel) can not be used by route tables.
+	usedSheets := map[string]struct{}{"history": {}}
+	for _, name := range []string{excelSummarySheet, excelAttachmentsSheet, excelMatrixSheet} {
+		usedSheets[strings.ToLower(name)] = struct{}{}
+	}
+	var sheets []ExcelSheet
+	for _, rt := range tgw.RouteTables {
+		sheets = append(sheets, ExcelSheet{
+			RouteTableID:   rt.ID,
+			RouteTableName: rt.Name,
+			Sheet:          excelSheetName(rt.Name, usedSheets),
+		})
+	}
+
+	// Attachments, the row of each attachment is used by the hyperlinks on the route sheets.
+	attachmentRow := make(map[string]int)
+	f.NewSheet(excelAttachmentsSheet)
+	var attachmentRows [][]interface{}
+	for i, att := range attachments {
+		attachmentRow[att.ID] = i + 2
+		var associated string
+		if rt := tgw.AssociatedRouteTable(att.ID); rt != nil {
+			associated = rt.Name
+		}
+		var propagating []string
+		for _, rt := range tgw.PropagatingRouteTables(att.ID) {
+			propagating = append(propagating, rt.Name)
+		}
+		attachmentRows = append(attachmentRows, []interface{}{
+			att.ID, att.Name, att.Type, att.ResourceID, att.OwnerID, associated, strings.Join(propagating, ", "),
+		})
+	}
+	header := []string{"AttachmentID", "AttachmentName", "Type", "ResourceID", "Owner", "AssociatedRouteTable", "PropagatingRouteTables"}
+	if err := writeExcelTable(f, excelAttachmentsSheet, 1, header, attachmentRows, styles, true); err != nil {
+		return nil, nil, fmt.Errorf("error writing the attachments: %w", err)
+	}
+
+	// Association and propagation matrix.
+	f.NewSheet(excelMatrixSheet)
+	header = []string{"AttachmentID", "AttachmentName"}
+	for _, sheet := range sheets {
+		header = append(header, sheet.Sheet)
+	}
+	var matrixRows [][]interface{}
+	for _, att := range attachments {
+		row := []interface{}{att.ID, att.Name}
+		associated := tgw.AssociatedRouteTable(att.ID)
+		propagating := tgw.PropagatingRouteTables(att.ID)
+		for _, rt := range tgw.RouteTables {
+			var cell []string
+			if associated == rt {
+				cell = append(cell, "association")
+			}
+			for _, p := range propagating {
+				if p == rt {
+					cell = append(cell, "propagation")
+				}
+			}
+			row = append(row, strings.Join(cell, ", "))
+		}
+		matrixRows = append(matrixRows, row)
+	}
+	if err := writeExcelTable(f, excelMatrixSheet, 1, header, matrixRows, styles, true); err != nil {
+		return nil, nil, fmt.Errorf("error writing the association matrix: %w", err)
+	}
+
+	// One sheet per route table.
+	for i, tgwRouteTable := range tgw.RouteTables {
+		sheet := sheets[i].Sheet
+		fmt.Fprintln(os.Stderr, "Route Table Name:", tgwRouteTable.Name)
+		f.NewSheet(sheet)
+		f.SetCellValue(sheet, "A1", fmt.Sprintf("Route Table: %s (%s)", tgwRouteTable.Name, tgwRouteTable.ID))
+		var routeRows [][]interface{}
+		var routeAttachmentIDs []string
+		for _, route := range tgwRouteTable.Routes {
+			var attachmentID, attachmentName, resourceID, resourceType string
+			if len(route.TransitGatewayAttachments) != 0 {
+				att := route.TransitGatewayAttachments[0]
+				attachmentID = aws.StringValue(att.TransitGatewayAttachmentId)
+				attachmentName = tgw.GetAttachmentName(attachmentID)
+				if attachmentName == "" {
+					attachmentName = attachmentID
+				}
+				resourceID = aws.StringValue(att.ResourceId)
+				resourceType = fmt.Sprint(att.ResourceType)
+			}
+			prefixListId := "-"
+			if route.PrefixListId != nil {
+				prefixListId = *route.PrefixListId
+			}
+			routeAttachmentIDs = append(routeAttachmentIDs, attachmentID)
+			routeRows = append(routeRows, []interface{}{
+				aws.StringValue(route.DestinationCidrBlock),
+				fmt.Sprint(route.State),
+				fmt.Sprint(route.Type),
+				prefixListId,
+				attachmentName,
+				attachmentID,
+				resourceID,
+				resourceType,
+			})
+		}
+		header := []string{"Destination", "State", "RouteType", "PrefixList", "AttachmentName", "AttachmentID", "ResourceID", "ResourceType"}
+		if err := writeExcelTable(f, sheet, 2, header, routeRows, styles, true); err != nil {
+			return nil, nil, fmt.Errorf("error writing the route table %s: %w", tgwRouteTable.Name, err)
+		}
+		for i, route := range tgwRouteTable.Routes {
+			row := i + 3
+			if route.State == types.TransitGatewayRouteStateBlackhole {
+				f.SetCellStyle(sheet, excelCell(0, row), excelCell(len(header)-1, row), styles.blackhole)
+			}
+			if attRow, ok := attachmentRow[routeAttachmentIDs[i]]; ok {
+				f.SetCellHyperLink(sheet, excelCell(4, row), excelLocation(excelAttachmentsSheet, 0, attRow), "Location")
+				f.SetCellStyle(sheet, excelCell(4, row), excelCell(4, row), styles.link)
+			}
+		}
+	}
+
+	// Summary, written last so the route table names can link to their sheets.
+	details := [][]interface{}{
+		{"Name", tgw.Name},
+		{"ID", tgw.ID},
+		{"State", fmt.Sprint(tgw.Data.State)},
+		{"Owner", aws.StringValue(tgw.Data.OwnerId)},
+		{"Description", aws.StringValue(tgw.Data.Description)},
+	}
+	if tgw.Data.Options != nil {
+		details = append(details, []interface{}{"AmazonSideAsn", aws.Int64Value(tgw.Data.Options.AmazonSideAsn)})
+	}
+	f.SetSheetRow(excelSummarySheet, "A1", &[]string{"Transit Gateway"})
+	f.SetCellStyle(excelSummarySheet, "A1", "B1", styles.header)
+	for i := range details {
+		f.SetSheetRow(excelSummarySheet, excelCell(0, i+2), &details[i])
+	}
+	var summaryRows [][]interface{}
+	for _, rt := range tgw.RouteTables {
+		var active, blackhole, propagated, static, propagations int
+		for _, route := range rt.Routes {
+			switch route.State {
+			case types.TransitGatewayRouteStateActive:
+				active++
+			case types.TransitGatewayRouteStateBlackhole:
+				blackhole++
+			}
+			switch route.Type {
+			case types.TransitGatewayRouteTypePropagated:
+				propagated++
+			case types.TransitGatewayRouteTypeStatic:
+				static++
+			}
+		}
+		for _, att := range attachments {
+			for _, p := range tgw.PropagatingRouteTables(att.ID) {
+				if p == rt {
+					propagations++
+				}
+			}
+		}
+		summaryRows = append(summaryRows, []interface{}{
+			rt.Name, rt.ID, len(rt.Routes), active, blackhole, propagated, static, len(rt.Attachments), propagations,
+		})
+	}
+	header = []string{"RouteTable", "RouteTableID", "Routes", "Active", "Blackhole", "Propagated", "Static", "Associations", "Propagations"}
+	if err := writeExcelTable(f, excelSummarySheet, excelSummaryTableRow, header, summaryRows, styles, false); err != nil {
+		return nil, nil, fmt.Errorf("error writing the summary: %w", err)
+	}
+	for i, sheet := range sheets {
+		row := excelSummaryTableRow + i + 1
+		f.SetCellHyperLink(excelSummarySheet, excelCell(0, row), excelLocation(sheet.Sheet, 0, 1), "Location")
+		f.SetCellStyle(excelSummarySheet, excelCell(0, row), excelCell(0, row), styles.link)
+	}
+	f.SetActiveSheet(f.GetSheetIndex(excelSummarySheet))
+	return f, sheets, nil
+}
+
+// ExportTgwRoutesExcel creates a Excel with all the routes in all Tgw Route Tables.
+// Each Tgw has its own Excel in dir, with a summary, the attachments, the associations and a sheet per Tgw Route Table.
+// The file names are the Tgw names, with the characters not allowed in file names replaced and a suffix if two Tgws share a name.
+// It returns the workbooks created, with the sheet used for each route table.
+func ExportTgwRoutesExcel(tgws []*Tgw, dir string) ([]ExcelWorkbook, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading folder %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("folder %s is not a directory", dir)
+	}
+	var workbooks []ExcelWorkbook
+	usedFiles := make(map[string]struct{})
+	for _, tgw := range tgws {
+		fmt.Fprintln(os.Stderr, "Transit Gateway Name:", tgw.Name)
+		f, sheets, err := newTgwExcel(tgw)
+		if err != nil {
+			return workbooks, fmt.Errorf("error creating excel for %s: %w", tgw.Name, err)
+		}
+		fileName := filepath.Join(dir, UniqueFileName(tgw.Name, usedFiles)+".xlsx")
+		if err := f.SaveAs(fileName); err != nil {
+			return workbooks, fmt.Errorf("error saving excel: %w", err)
+		}
+		workbooks = append(workbooks, ExcelWorkbook{
+			TgwID:   tgw.ID,
+			TgwName: tgw.Name,
+			Path:    fileName,
+			Sheets:  sheets,
+		})
+	}
+	return workbooks, nil
+}
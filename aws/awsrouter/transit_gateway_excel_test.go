@@ -0,0 +1,155 @@
+package awsrouter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+var excelTgw = &Tgw{
+	ID:   "tgw-0d7f9b0a",
+	Name: "testA",
+	Data: types.TransitGateway{
+		TransitGatewayId: aws.String("tgw-0d7f9b0a"),
+		State:            "available",
+		OwnerId:          aws.String("123456789012"),
+	},
+	RouteTables: []*TgwRouteTable{
+		{
+			ID:   "tgw-rtb-0a",
+			Name: "prod",
+			Attachments: []*TgwAttachment{
+				{ID: "tgw-attach-0a", Name: "vpc-prod", ResourceID: "vpc-0a", Type: "vpc", OwnerID: "123456789012"},
+			},
+			Routes: []types.TransitGatewayRoute{
+				{
+					DestinationCidrBlock: aws.String("10.1.0.0/16"),
+					State:                "active",
+					Type:                 "propagated",
+					TransitGatewayAttachments: []types.TransitGatewayRouteAttachment{
+						{TransitGatewayAttachmentId: aws.String("tgw-attach-0b"), ResourceId: aws.String("vpc-0b"), ResourceType: "vpc"},
+					},
+				},
+				{
+					DestinationCidrBlock: aws.String("10.2.0.0/16"),
+					State:                "blackhole",
+					Type:                 "static",
+				},
+			},
+		},
+		{
+			ID:   "tgw-rtb-0b",
+			Name: "dev",
+			Attachments: []*TgwAttachment{
+				{ID: "tgw-attach-0b", Name: "vpc-dev", ResourceID: "vpc-0b", Type: "vpc"},
+			},
+			Routes: []types.TransitGatewayRoute{
+				{
+					DestinationCidrBlock: aws.String("10.0.0.0/16"),
+					State:                "active",
+					Type:                 "propagated",
+					TransitGatewayAttachments: []types.TransitGatewayRouteAttachment{
+						{TransitGatewayAttachmentId: aws.String("tgw-attach-0a"), ResourceId: aws.String("vpc-0a"), ResourceType: "vpc"},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestNewTgwExcel(t *testing.T) {
+	f, _, err := newTgwExcel(excelTgw)
+	if err != nil {
+		t.Fatalf("newTgwExcel() error = %v", err)
+	}
+	var sheets []string
+	for i := 1; i <= len(f.GetSheetMap()); i++ {
+		sheets = append(sheets, f.GetSheetMap()[i])
+	}
+	wantSheets := []string{"Summary", "Attachments", "Associations", "prod", "dev"}
+	if !reflect.DeepEqual(sheets, wantSheets) {
+		t.Errorf("newTgwExcel() sheets = %v, want %v", sheets, wantSheets)
+	}
+	tests := []struct {
+		sheet string
+		axis  string
+		want  string
+	}{
+		{sheet: "Summary", axis: "B3", want: "tgw-0d7f9b0a"},
+		{sheet: "Summary", axis: "A10", want: "prod"},
+		{sheet: "Summary", axis: "E10", want: "1"},
+		{sheet: "Attachments", axis: "A2", want: "tgw-attach-0a"},
+		{sheet: "Attachments", axis: "E2", want: "123456789012"},
+		{sheet: "Attachments", axis: "F2", want: "prod"},
+		{sheet: "Attachments", axis: "G2", want: "dev"},
+		{sheet: "Associations", axis: "C2", want: "association"},
+		{sheet: "Associations", axis: "D2", want: "propagation"},
+		{sheet: "prod", axis: "A1", want: "Route Table: prod (tgw-rtb-0a)"},
+		{sheet: "prod", axis: "E3", want: "vpc-dev"},
+		{sheet: "prod", axis: "B4", want: "blackhole"},
+	}
+	for _, tt := range tests {
+		if got := f.GetCellValue(tt.sheet, tt.axis); got != tt.want {
+			t.Errorf("newTgwExcel() %s!%s = %v, want %v", tt.sheet, tt.axis, got, tt.want)
+		}
+	}
+	if ok, link := f.GetCellHyperLink("prod", "E3"); !ok || link != "'Attachments'!A3" {
+		t.Errorf("newTgwExcel() prod!E3 hyperlink = %v, want 'Attachments'!A3", link)
+	}
+}
+
+func TestExcelSheetName(t *testing.T) {
+	used := map[string]struct{}{"summary": {}}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "prod", want: "prod"},
+		{name: "Prod", want: "Prod (2)"},
+		{name: "summary", want: "summary (2)"},
+		{name: "shared/services:egress", want: "shared_services_egress"},
+		{name: "'quoted'", want: "quoted"},
+		{name: "a-very-long-route-table-name-for-production", want: "a-very-long-route-table-name-fo"},
+		{name: "a-very-long-route-table-name-for-development", want: "a-very-long-route-table-nam (2)"},
+		{name: "", want: "RouteTable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := excelSheetName(tt.name, used); got != tt.want {
+				t.Errorf("excelSheetName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportTgwRoutesExcel(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested", "excel")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sameName := &Tgw{ID: "tgw-0d7f9b0b", Name: "testA", RouteTables: []*TgwRouteTable{
+		{ID: "tgw-rtb-0c", Name: "shared/services"},
+	}}
+	workbooks, err := ExportTgwRoutesExcel([]*Tgw{excelTgw, sameName}, dir)
+	if err != nil {
+		t.Fatalf("ExportTgwRoutesExcel() error = %v", err)
+	}
+	wantPaths := []string{filepath.Join(dir, "testA.xlsx"), filepath.Join(dir, "testA (2).xlsx")}
+	for i, workbook := range workbooks {
+		if workbook.Path != wantPaths[i] {
+			t.Errorf("ExportTgwRoutesExcel() path = %v, want %v", workbook.Path, wantPaths[i])
+		}
+		if _, err := excelize.OpenFile(workbook.Path); err != nil {
+			t.Errorf("ExportTgwRoutesExcel() did not create a valid file: %v", err)
+		}
+	}
+	want := []ExcelSheet{{RouteTableID: "tgw-rtb-0c", RouteTableName: "shared/services", Sheet: "shared_services"}}
+	if !reflect.DeepEqual(workbooks[1].Sheets, want) {
+		t.Errorf("ExportTgwRoutesExcel() sheets = %v, want %v", workbooks[1].Sheets, want)
+	}
+}
@@ -0,0 +1,226 @@
+package awsrouter
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// RouteExplanation explains how a route table routes a prefix.
+type RouteExplanation struct {
+	Tgw        *Tgw
+	RouteTable *TgwRouteTable
+	Prefix     net.IPNet
+	// Route is the route used for the prefix: the route to exactly the prefix or else the most specific route
+	// that covers all of it. It is nil when no route covers the prefix.
+	Route *types.TransitGatewayRoute
+	// Exact is true when Route is a route to exactly the prefix.
+	Exact bool
+	// Candidates are the routes of the route table that cover the prefix or are inside it, the most specific first.
+	Candidates []types.TransitGatewayRoute
+	// Owners are the attachments with a propagated route to a prefix that overlaps the prefix,
+	// in any route table of the Tgw. They are the attachments that have the prefix, or part of it.
+	Owners []*TgwAttachment
+	// Reasons explain in plain sentences where Route comes from, or why there is no route.
+	Reasons []string
+}
+
+// FindRouteTable returns the route table with the ID or name rtID and its Tgw.
+// tgwID is the ID or name of the Tgw, when it is empty all the Tgws are searched and the route table must be unique.
+func FindRouteTable(tgws []*Tgw, tgwID, rtID string) (*Tgw, *TgwRouteTable, error) {
+	if tgwID != "" {
+		tgw, err := findTgw(tgws, tgwID)
+		if err != nil {
+			return nil, nil, err
+		}
+		rt, err := tgw.findRouteTable(rtID)
+		return tgw, rt, err
+	}
+	var foundTgw *Tgw
+	var found *TgwRouteTable
+	for _, tgw := range tgws {
+		rt, err := tgw.findRouteTable(rtID)
+		if err != nil {
+			continue
+		}
+		if found != nil {
+			return nil, nil, fmt.Errorf("route table %q is in %s and %s, select the transit gateway", rtID, foundTgw.Name, tgw.Name)
+		}
+		foundTgw, found = tgw, rt
+	}
+	if found == nil {
+		return nil, nil, fmt.Errorf("route table %q not found: %w", rtID, ErrTgwRouteTableNotFound)
+	}
+	return foundTgw, found, nil
+}
+
+// ExplainRoute explains how the route table rt of the Tgw routes prefix.
+// The route to exactly the prefix is found with FilterRouteTableRoutesPerPrefix, otherwise the route
+// used for the first address of the prefix is found with BestRouteToIP, if it covers all the prefix.
+func (t *Tgw) ExplainRoute(rt *TgwRouteTable, prefix net.IPNet) (RouteExplanation, error) {
+	prefix = net.IPNet{IP: prefix.IP.Mask(prefix.Mask), Mask: prefix.Mask}
+	e := RouteExplanation{Tgw: t, RouteTable: rt, Prefix: prefix}
+	candidates, err := candidateRoutes(rt, prefix)
+	if err != nil {
+		return e, err
+	}
+	e.Candidates = candidates
+
+	exact, err := FilterRouteTableRoutesPerPrefix([]*TgwRouteTable{rt}, prefix)
+	if err != nil {
+		return e, err
+	}
+	if len(exact) > 0 {
+		e.Route = &exact[0].Routes[0]
+		e.Exact = true
+	} else {
+		best, err := rt.BestRouteToIP(prefix.IP)
+		if err != nil {
+			return e, err
+		}
+		if covers(best, prefix) {
+			e.Route = &best
+		} else {
+			// The best route is more specific than the prefix, the route of the prefix is the next covering one.
+			for i := range e.Candidates {
+				if covers(e.Candidates[i], prefix) {
+					e.Route = &e.Candidates[i]
+					break
+				}
+			}
+		}
+	}
+
+	e.Owners = t.prefixOwners(prefix)
+	e.Reasons = t.explainReasons(e)
+	return e, nil
+}
+
+// explainReasons returns the reasons of the explanation e.
+func (t *Tgw) explainReasons(e RouteExplanation) []string {
+	rt := e.RouteTable
+	prefix := e.Prefix.String()
+	if e.Exact {
+		return t.ExplainPropagation(rt, prefix)
+	}
+	var reasons []string
+	if e.Route != nil {
+		var hops []string
+		for _, att := range e.Route.TransitGatewayAttachments {
+			hops = append(hops, t.attachmentName(aws.StringValue(att.TransitGatewayAttachmentId)))
+		}
+		reasons = append(reasons, fmt.Sprintf("there is no route to %s in route table %s, the %s route to %s via %s covers it",
+			prefix, rt.Name, e.Route.Type, aws.StringValue(e.Route.DestinationCidrBlock), noneIfEmpty(strings.Join(hops, ", "))))
+	} else {
+		reasons = append(reasons, fmt.Sprintf("there is no route to %s in route table %s and no route covers it", prefix, rt.Name))
+	}
+	if len(e.Owners) == 0 {
+		return append(reasons, fmt.Sprintf("no attachment propagates %s or part of it to any route table of %s", prefix, t.Name))
+	}
+	for _, att := range e.Owners {
+		owned := strings.Join(t.ownedPrefixes(att.ID, e.Prefix), ", ")
+		var names []string
+		propagates := false
+		for _, other := range t.PropagatingRouteTables(att.ID) {
+			propagates = propagates || other == rt
+			names = append(names, other.Name)
+		}
+		if propagates {
+			reasons = append(reasons, fmt.Sprintf("attachment %s has %s and propagates to route table %s", t.attachmentName(att.ID), owned, rt.Name))
+			continue
+		}
+		reasons = append(reasons, fmt.Sprintf("attachment %s has %s and propagates to %s but not to route table %s",
+			t.attachmentName(att.ID), owned, noneIfEmpty(strings.Join(names, ", ")), rt.Name))
+	}
+	return reasons
+}
+
+// candidateRoutes returns the routes of rt that cover prefix or are inside it, the most specific first.
+// The routes to a prefix list are not candidates.
+func candidateRoutes(rt *TgwRouteTable, prefix net.IPNet) ([]types.TransitGatewayRoute, error) {
+	var result []types.TransitGatewayRoute
+	var ones []int
+	for _, route := range rt.Routes {
+		if route.DestinationCidrBlock == nil {
+			continue
+		}
+		_, dst, err := net.ParseCIDR(*route.DestinationCidrBlock)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the CIDR %s of route table %s: %w", *route.DestinationCidrBlock, rt.ID, err)
+		}
+		if !overlaps(*dst, prefix) {
+			continue
+		}
+		n, _ := dst.Mask.Size()
+		result = append(result, route)
+		ones = append(ones, n)
+	}
+	sort.Sort(byPrefixLength{routes: result, ones: ones})
+	return result, nil
+}
+
+// byPrefixLength sorts routes from the most specific, ones are the prefix lengths of the routes.
+type byPrefixLength struct {
+	routes []types.TransitGatewayRoute
+	ones   []int
+}
+
+func (b byPrefixLength) Len() int { return len(b.routes) }
+
+func (b byPrefixLength) Less(i, j int) bool {
+	if b.ones[i] != b.ones[j] {
+		return b.ones[i] > b.ones[j]
+	}
+	return aws.StringValue(b.routes[i].DestinationCidrBlock) < aws.StringValue(b.routes[j].DestinationCidrBlock)
+}
+
+func (b byPrefixLength) Swap(i, j int) {
+	b.routes[i], b.routes[j] = b.routes[j], b.routes[i]
+	b.ones[i], b.ones[j] = b.ones[j], b.ones[i]
+}
+
+// prefixOwners returns the attachments, sorted by ID, with a propagated route that overlaps prefix.
+func (t *Tgw) prefixOwners(prefix net.IPNet) []*TgwAttachment {
+	var owners []*TgwAttachment
+	for _, att := range t.Attachments() {
+		if len(t.ownedPrefixes(att.ID, prefix)) > 0 {
+			owners = append(owners, att)
+		}
+	}
+	return owners
+}
+
+// ownedPrefixes returns the sorted prefixes propagated by the attachment that overlap prefix.
+func (t *Tgw) ownedPrefixes(attachmentID string, prefix net.IPNet) []string {
+	var result []string
+	for _, p := range t.propagatedPrefixes(attachmentID) {
+		_, dst, err := net.ParseCIDR(p)
+		if err == nil && overlaps(*dst, prefix) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// covers reports if the destination of route contains all the addresses of prefix.
+func covers(route types.TransitGatewayRoute, prefix net.IPNet) bool {
+	if route.DestinationCidrBlock == nil {
+		return false
+	}
+	_, dst, err := net.ParseCIDR(*route.DestinationCidrBlock)
+	if err != nil {
+		return false
+	}
+	routeOnes, _ := dst.Mask.Size()
+	prefixOnes, _ := prefix.Mask.Size()
+	return routeOnes <= prefixOnes && dst.Contains(prefix.IP)
+}
+
+// overlaps reports if one of the prefixes contains the other.
+func overlaps(a, b net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
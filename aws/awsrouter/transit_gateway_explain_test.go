@@ -0,0 +1,145 @@
+package awsrouter
+
+import (
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTgw_ExplainRoute(t *testing.T) {
+	tests := []struct {
+		name           string
+		tgw            func() *Tgw
+		prefix         string
+		wantRoute      string
+		wantExact      bool
+		wantCandidates []string
+		wantOwners     []string
+		wantReasons    []string
+	}{
+		{
+			name:           "Exact",
+			tgw:            propagationTgw,
+			prefix:         "10.0.0.0/16",
+			wantRoute:      "10.0.0.0/16",
+			wantExact:      true,
+			wantCandidates: []string{"10.0.0.0/16"},
+			wantOwners:     []string{"tgw-attach-0a"},
+			wantReasons:    []string{"the route to 10.0.0.0/16 in route table spokes is propagated by attachment vpc-a, its propagation is enabled"},
+		},
+		{
+			name:           "Covered",
+			tgw:            propagationTgw,
+			prefix:         "10.0.5.0/24",
+			wantRoute:      "10.0.0.0/16",
+			wantCandidates: []string{"10.0.0.0/16"},
+			wantOwners:     []string{"tgw-attach-0a"},
+			wantReasons: []string{
+				"there is no route to 10.0.5.0/24 in route table spokes, the propagated route to 10.0.0.0/16 via vpc-a covers it",
+				"attachment vpc-a has 10.0.0.0/16 and propagates to route table spokes",
+			},
+		},
+		{
+			name:           "More Specific Routes",
+			tgw:            propagationTgw,
+			prefix:         "10.0.0.0/8",
+			wantCandidates: []string{"10.0.0.0/16", "10.1.0.0/16", "10.9.0.0/16"},
+			wantOwners:     []string{"tgw-attach-0a", "tgw-attach-0b", "tgw-attach-0s"},
+			wantReasons: []string{
+				"there is no route to 10.0.0.0/8 in route table spokes and no route covers it",
+				"attachment vpc-a has 10.0.0.0/16 and propagates to route table spokes",
+				"attachment vpc-b has 10.1.0.0/16 and propagates to shared but not to route table spokes",
+				"attachment vpc-s has 10.9.0.0/16 and propagates to shared but not to route table spokes",
+			},
+		},
+		{
+			name: "Missing",
+			tgw: func() *Tgw {
+				tgw := whatIfTgws()[0]
+				tgw.RouteTables[0].Routes = tgw.RouteTables[0].Routes[:1]
+				return tgw
+			},
+			prefix:         "10.1.0.0/16",
+			wantCandidates: nil,
+			wantOwners:     []string{"tgw-attach-0b"},
+			wantReasons: []string{
+				"there is no route to 10.1.0.0/16 in route table spokes and no route covers it",
+				"attachment vpc-b has 10.1.0.0/16 and propagates to shared but not to route table spokes",
+			},
+		},
+		{
+			name:   "No Owner",
+			tgw:    propagationTgw,
+			prefix: "192.168.0.0/16",
+			wantReasons: []string{
+				"there is no route to 192.168.0.0/16 in route table spokes and no route covers it",
+				"no attachment propagates 192.168.0.0/16 or part of it to any route table of core",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tgw := tt.tgw()
+			_, prefix, err := net.ParseCIDR(tt.prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := tgw.ExplainRoute(tgw.RouteTables[0], *prefix)
+			if err != nil {
+				t.Fatalf("ExplainRoute() error = %v", err)
+			}
+			var route string
+			if e.Route != nil {
+				route = aws.StringValue(e.Route.DestinationCidrBlock)
+			}
+			if route != tt.wantRoute || e.Exact != tt.wantExact {
+				t.Errorf("ExplainRoute() route = %v, exact = %v, want %v, %v", route, e.Exact, tt.wantRoute, tt.wantExact)
+			}
+			var candidates []string
+			for _, r := range e.Candidates {
+				candidates = append(candidates, aws.StringValue(r.DestinationCidrBlock))
+			}
+			if diff := cmp.Diff(tt.wantCandidates, candidates); diff != "" {
+				t.Errorf("ExplainRoute() candidates mismatch (-want +got):\n%s", diff)
+			}
+			var owners []string
+			for _, att := range e.Owners {
+				owners = append(owners, att.ID)
+			}
+			if diff := cmp.Diff(tt.wantOwners, owners); diff != "" {
+				t.Errorf("ExplainRoute() owners mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantReasons, e.Reasons); diff != "" {
+				t.Errorf("ExplainRoute() reasons mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFindRouteTable(t *testing.T) {
+	tgws := append(whatIfTgws(), &Tgw{ID: "tgw-0b", Name: "edge", RouteTables: []*TgwRouteTable{{ID: "tgw-rtb-0e", Name: "spokes"}}})
+	tests := []struct {
+		name    string
+		tgw, rt string
+		want    string
+		wantErr bool
+	}{
+		{name: "ID", rt: "tgw-rtb-0s", want: "tgw-rtb-0s"},
+		{name: "Name In Tgw", tgw: "edge", rt: "spokes", want: "tgw-rtb-0e"},
+		{name: "Ambiguous Name", rt: "spokes", wantErr: true},
+		{name: "Not Found", rt: "prod", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, rt, err := FindRouteTable(tgws, tt.tgw, tt.rt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindRouteTable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && rt.ID != tt.want {
+				t.Errorf("FindRouteTable() = %v, want %v", rt.ID, tt.want)
+			}
+		})
+	}
+}
@@ -3,10 +3,10 @@ package awsrouter
 import (
 	"encoding/csv"
 	"fmt"
-	"io/fs"
+	"strings"
 
-	"github.com/360EntSecGroup-Skylar/excelize"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
 )
 
 // getNamesFromTags returns the name tags if exist, if not it will signal with an error.
@@ -19,98 +19,104 @@ func GetNamesFromTags(tags []types.Tag) (string, error) {
 	return "", fmt.Errorf("no name tag found")
 }
 
-// ExportTgwRoutesExcel creates a Excel with all the routes in all Tgw Route Tables.
-// Each sheet on the Excel is a Tgw Route Table, each route is a route.
-func ExportTgwRoutesExcel(tgws []*Tgw, folder fs.FileInfo) error {
-	if !folder.IsDir() {
-		return fmt.Errorf("folder %s is not a directory", folder.Name())
+// csvRouteHeader is the header of the route columns in the CSV exports.
+var csvRouteHeader = []string{
+	"Destination CIDR Block",
+	"Prefix List",
+	"State",
+	"Type",
+	"Blackhole",
+	"Attachment ID",
+	"Attachment Name",
+	"Resource ID",
+	"Resource Type",
+}
+
+// csvRouteRows returns the rows of the route columns for a route.
+// There is one row per next hop attachment, so ECMP routes have more than one row.
+// Routes without attachments, like blackhole routes, have a single row with empty attachment columns.
+func csvRouteRows(tgw *Tgw, route types.TransitGatewayRoute) [][]string {
+	columns := []string{
+		aws.StringValue(route.DestinationCidrBlock),
+		aws.StringValue(route.PrefixListId),
+		fmt.Sprint(route.State),
+		fmt.Sprint(route.Type),
+		fmt.Sprint(route.State == types.TransitGatewayRouteStateBlackhole),
 	}
-	folderName := folder.Name()
-	for _, tgw := range tgws {
-		attachMap := make(map[string]string)
-		fmt.Println("Transit Gateway Name:", tgw.Name)
-		f := excelize.NewFile()
-		for _, tgwRouteTable := range tgw.RouteTables {
-			for _, attachment := range tgwRouteTable.Attachments {
-				if _, ok := attachMap[attachment.ID]; !ok {
-					if attachment.Name != "" {
-						attachMap[attachment.ID] = attachment.Name
-					}
-				}
-			}
-		}
-		fmt.Println("The following attachment name where found in TGW:", tgw.Name)
-		for key, value := range attachMap {
-			fmt.Printf("\t %v->%v\n", key, value)
+	if len(route.TransitGatewayAttachments) == 0 {
+		return [][]string{append(columns, "", "", "", "")}
+	}
+	var rows [][]string
+	for _, att := range route.TransitGatewayAttachments {
+		attachmentID := aws.StringValue(att.TransitGatewayAttachmentId)
+		row := append([]string{}, columns...)
+		row = append(row,
+			attachmentID,
+			tgw.GetAttachmentName(attachmentID),
+			aws.StringValue(att.ResourceId),
+			fmt.Sprint(att.ResourceType),
+		)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// ExportRouteTableRoutesCsv creates a CSV with all the routes in one Tgw Route Table.
+// The attachment names are taken from all the route tables in tgw.
+func ExportRouteTableRoutesCsv(w *csv.Writer, tgw *Tgw, tgwrt TgwRouteTable) error {
+	defer w.Flush()
+	if err := w.Write(csvRouteHeader); err != nil {
+		return fmt.Errorf("error writing to csv: %w", err)
+	}
+	for _, route := range tgwrt.Routes {
+		if err := w.WriteAll(csvRouteRows(tgw, route)); err != nil {
+			return fmt.Errorf("error writing to csv: %w", err)
 		}
+	}
+	return nil
+}
+
+// ExportTgwRoutesCsv creates a single CSV with all the routes in all the Tgw Route Tables.
+// Each row starts with the Transit Gateway and Route Table of the route.
+func ExportTgwRoutesCsv(w *csv.Writer, tgws []*Tgw) error {
+	defer w.Flush()
+	header := append([]string{"Transit Gateway ID", "Transit Gateway Name", "Route Table ID", "Route Table Name"}, csvRouteHeader...)
+	if err := w.Write(header); err != nil {
+		return fmt.Errorf("error writing to csv: %w", err)
+	}
+	for _, tgw := range tgws {
 		for _, tgwRouteTable := range tgw.RouteTables {
-			fmt.Println("Route Table Name:", tgwRouteTable.Name)
-			sheet := f.NewSheet(tgwRouteTable.Name)
-			for i, route := range tgwRouteTable.Routes {
-				// Only for the header
-				if i == 0 {
-					// TODO: create an optional function to create a header and row
-					f.SetCellValue(tgwRouteTable.Name, "A1", "Destination")
-					f.SetCellValue(tgwRouteTable.Name, "B1", "State")
-					f.SetCellValue(tgwRouteTable.Name, "C1", "RouteType")
-					f.SetCellValue(tgwRouteTable.Name, "D1", "PrefixList")
-					f.SetCellValue(tgwRouteTable.Name, "E1", "AttachmentName")
-				}
-				state := fmt.Sprint(route.State)
-				routeType := fmt.Sprint(route.Type)
-				// for _, attachment := range tgwRouteTable.Attachments {
-				// 	fmt.Println("attachment id:", attachment.ID)
-				// 	fmt.Println("attachment name:", attachment.Name)
-				// }
-				var attachmentName string
-				if len(route.TransitGatewayAttachments) != 0 {
-					attachmentID := fmt.Sprint(*route.TransitGatewayAttachments[0].TransitGatewayAttachmentId)
-					fmt.Println("attachment id to find:", attachmentID)
-					name, ok := attachMap[attachmentID]
-					if ok {
-						attachmentName = name
-					} else {
-						attachmentName = attachmentID
+			for _, route := range tgwRouteTable.Routes {
+				for _, row := range csvRouteRows(tgw, route) {
+					row = append([]string{tgw.ID, tgw.Name, tgwRouteTable.ID, tgwRouteTable.Name}, row...)
+					if err := w.Write(row); err != nil {
+						return fmt.Errorf("error writing to csv: %w", err)
 					}
 				}
-				var prefixListId string
-				if route.PrefixListId == nil {
-					prefixListId = "-"
-				} else {
-					prefixListId = *route.PrefixListId
-				}
-				row := []string{
-					*route.DestinationCidrBlock,
-					state,
-					routeType,
-					prefixListId,
-					attachmentName,
-				}
-				f.SetSheetRow(tgwRouteTable.Name, "A"+fmt.Sprint(i+2), &row)
 			}
-			f.SetActiveSheet(sheet)
-		}
-		fileName := fmt.Sprintf("%s/%s.xlsx", folderName, tgw.Name)
-		if err := f.SaveAs(fileName); err != nil {
-			return fmt.Errorf("error saving excel: %w", err)
 		}
 	}
 	return nil
 }
 
-// ExportRouteTableRoutesCsv creates a CSV with all the routes in one Tgw Route Table.
-func ExportRouteTableRoutesCsv(w *csv.Writer, tgwrt TgwRouteTable) error {
-	defer w.Flush()
-	w.Write([]string{"Destination CIDR Block", "State", "Type"})
-	for _, route := range tgwrt.Routes {
-		state := fmt.Sprint(route.State)
-		routeType := fmt.Sprint(route.Type)
-		err := w.Write([]string{*route.DestinationCidrBlock, state, routeType})
-		if err != nil {
-			return fmt.Errorf("error writing to csv: %w", err)
+// SafeFileName replaces the characters that are not valid in a file name on Linux, macOS or Windows with "_".
+func SafeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r < 32, strings.ContainsRune(`<>:"/\|?*`, r):
+			return '_'
 		}
+		return r
+	}, name)
+	name = strings.Trim(name, " .")
+	if name == "" {
+		return "_"
 	}
-	return nil
+	return name
 }
 
-//
+// UniqueFileName returns SafeFileName(name) with a numeric suffix if it is already in used, ignoring the case,
+// and adds the result to used, so files saved in the same folder do not overwrite each other.
+func UniqueFileName(name string, used map[string]struct{}) string {
+	return uniqueName(SafeFileName(name), 250, used)
+}
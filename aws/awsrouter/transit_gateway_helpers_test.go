@@ -0,0 +1,114 @@
+package awsrouter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+var csvTgw = &Tgw{
+	ID:   "tgw-0d7f9b0a",
+	Name: "testA",
+	RouteTables: []*TgwRouteTable{
+		{
+			ID:   "tgw-rtb-0d7f9b0a",
+			Name: "rtb1",
+			Routes: []types.TransitGatewayRoute{
+				{
+					DestinationCidrBlock: aws.String("10.0.0.0/16"),
+					State:                "active",
+					Type:                 "propagated",
+					TransitGatewayAttachments: []types.TransitGatewayRouteAttachment{
+						{
+							TransitGatewayAttachmentId: aws.String("tgw-attach-0b"),
+							ResourceId:                 aws.String("vpc-0b"),
+							ResourceType:               "vpc",
+						},
+						{
+							TransitGatewayAttachmentId: aws.String("tgw-attach-0c"),
+							ResourceId:                 aws.String("vpn-0c"),
+							ResourceType:               "vpn",
+						},
+					},
+				},
+				{
+					PrefixListId: aws.String("pl-0a"),
+					State:        "blackhole",
+					Type:         "static",
+				},
+			},
+		},
+		{
+			ID:          "tgw-rtb-0d7f9b0b",
+			Name:        "rtb2",
+			Attachments: []*TgwAttachment{{ID: "tgw-attach-0b", Name: "vpc-b"}},
+		},
+	},
+}
+
+func TestExportRouteTableRoutesCsv(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportRouteTableRoutesCsv(csv.NewWriter(&buf), csvTgw, *csvTgw.RouteTables[0]); err != nil {
+		t.Fatalf("ExportRouteTableRoutesCsv() error = %v", err)
+	}
+	want := `Destination CIDR Block,Prefix List,State,Type,Blackhole,Attachment ID,Attachment Name,Resource ID,Resource Type
+10.0.0.0/16,,active,propagated,false,tgw-attach-0b,vpc-b,vpc-0b,vpc
+10.0.0.0/16,,active,propagated,false,tgw-attach-0c,,vpn-0c,vpn
+,pl-0a,blackhole,static,true,,,,
+`
+	if got := buf.String(); got != want {
+		t.Errorf("ExportRouteTableRoutesCsv() = %v, want %v", got, want)
+	}
+}
+
+func TestExportTgwRoutesCsv(t *testing.T) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+	if err := ExportTgwRoutesCsv(w, []*Tgw{csvTgw}); err != nil {
+		t.Fatalf("ExportTgwRoutesCsv() error = %v", err)
+	}
+	want := `Transit Gateway ID;Transit Gateway Name;Route Table ID;Route Table Name;Destination CIDR Block;Prefix List;State;Type;Blackhole;Attachment ID;Attachment Name;Resource ID;Resource Type
+tgw-0d7f9b0a;testA;tgw-rtb-0d7f9b0a;rtb1;10.0.0.0/16;;active;propagated;false;tgw-attach-0b;vpc-b;vpc-0b;vpc
+tgw-0d7f9b0a;testA;tgw-rtb-0d7f9b0a;rtb1;10.0.0.0/16;;active;propagated;false;tgw-attach-0c;;vpn-0c;vpn
+tgw-0d7f9b0a;testA;tgw-rtb-0d7f9b0a;rtb1;;pl-0a;blackhole;static;true;;;;
+`
+	if got := buf.String(); got != want {
+		t.Errorf("ExportTgwRoutesCsv() = %v, want %v", got, want)
+	}
+}
+
+func TestSafeFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "prod-routes", want: "prod-routes"},
+		{name: "prod/routes:v2", want: "prod_routes_v2"},
+		{name: `a\b*c?`, want: "a_b_c_"},
+		{name: " .. ", want: "_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SafeFileName(tt.name); got != tt.want {
+				t.Errorf("SafeFileName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUniqueFileName(t *testing.T) {
+	used := make(map[string]struct{})
+	var got []string
+	for _, name := range []string{"core_prod", "core/prod", "Core_Prod", "core_dev"} {
+		got = append(got, UniqueFileName(name, used))
+	}
+	want := []string{"core_prod", "core_prod (2)", "Core_Prod (3)", "core_dev"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UniqueFileName() = %v, want %v", got, want)
+	}
+}
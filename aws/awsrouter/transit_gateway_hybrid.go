@@ -0,0 +1,142 @@
+package awsrouter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/rogerscuall/aws-router/ports"
+)
+
+// VpnConnection is a Site-to-Site VPN attached to the Tgw, the ResourceID of its attachment is ID.
+type VpnConnection struct {
+	ID      string
+	State   string
+	Tunnels []VpnTunnel
+}
+
+// VpnTunnel is the status of a tunnel of a VPN connection, AcceptedRoutes are the routes learned with BGP.
+type VpnTunnel struct {
+	OutsideIP      string
+	Status         string
+	StatusMessage  string
+	AcceptedRoutes int
+}
+
+// Down reports if the traffic sent to the VPN is dropped: the VPN is being deleted or none of its tunnels is up.
+// A VPN without the status of the tunnels is not down.
+func (v *VpnConnection) Down() bool {
+	if v.State == string(types.VpnStateDeleting) || v.State == string(types.VpnStateDeleted) {
+		return true
+	}
+	if len(v.Tunnels) == 0 {
+		return false
+	}
+	return v.TunnelsUp() == 0
+}
+
+// TunnelsUp returns the number of tunnels up.
+func (v *VpnConnection) TunnelsUp() int {
+	n := 0
+	for _, tunnel := range v.Tunnels {
+		if tunnel.Status == string(types.TelemetryStatusUp) {
+			n++
+		}
+	}
+	return n
+}
+
+// AcceptedRoutes returns the routes learned with BGP, the most accepted by a tunnel as both tunnels learn the same routes.
+func (v *VpnConnection) AcceptedRoutes() int {
+	n := 0
+	for _, tunnel := range v.Tunnels {
+		if tunnel.AcceptedRoutes > n {
+			n = tunnel.AcceptedRoutes
+		}
+	}
+	return n
+}
+
+// DxGateway is a Direct Connect gateway associated to the Tgw, the ResourceID of its attachment is ID.
+// AllowedPrefixes are the prefixes advertised from the Tgw to the Direct Connect gateway.
+type DxGateway struct {
+	ID              string
+	State           string
+	AllowedPrefixes []string
+}
+
+// UpdateVpnConnections updates the VpnConnections of the Tgw with the VPN connections attached to it.
+// On error the VpnConnections are left unknown and the paths do not check the tunnels.
+func (t *Tgw) UpdateVpnConnections(ctx context.Context, api ports.AWSRouter) error {
+	result, err := ports.GetVpnConnections(ctx, api, ports.VpnConnectionInputFilter(t.ID))
+	if err != nil {
+		t.VpnConnections = nil
+		return fmt.Errorf("error retrieving the VPN connections of %s: %w", t.Name, err)
+	}
+	t.VpnConnections = []*VpnConnection{}
+	for _, vpn := range result.VpnConnections {
+		v := &VpnConnection{ID: aws.StringValue(vpn.VpnConnectionId), State: string(vpn.State)}
+		for _, telemetry := range vpn.VgwTelemetry {
+			v.Tunnels = append(v.Tunnels, VpnTunnel{
+				OutsideIP:      aws.StringValue(telemetry.OutsideIpAddress),
+				Status:         string(telemetry.Status),
+				StatusMessage:  aws.StringValue(telemetry.StatusMessage),
+				AcceptedRoutes: int(aws.Int32Value(telemetry.AcceptedRouteCount)),
+			})
+		}
+		sort.Slice(v.Tunnels, func(i, j int) bool { return v.Tunnels[i].OutsideIP < v.Tunnels[j].OutsideIP })
+		t.VpnConnections = append(t.VpnConnections, v)
+	}
+	sort.Slice(t.VpnConnections, func(i, j int) bool { return t.VpnConnections[i].ID < t.VpnConnections[j].ID })
+	return nil
+}
+
+// UpdateDxGateways updates the DxGateways of the Tgw with the Direct Connect gateways associated to it.
+// On error the DxGateways are left unknown.
+func (t *Tgw) UpdateDxGateways(ctx context.Context, api ports.AWSRouter) error {
+	t.DxGateways = []*DxGateway{}
+	nextToken := ""
+	for {
+		result, err := ports.GetDirectConnectGatewayAssociations(ctx, api, ports.DirectConnectGatewayAssociationInputFilter(t.ID, nextToken))
+		if err != nil {
+			t.DxGateways = nil
+			return fmt.Errorf("error retrieving the Direct Connect gateways of %s: %w", t.Name, err)
+		}
+		for _, association := range result.DirectConnectGatewayAssociations {
+			dx := &DxGateway{ID: aws.StringValue(association.DirectConnectGatewayId), State: string(association.AssociationState)}
+			for _, prefix := range association.AllowedPrefixesToDirectConnectGateway {
+				dx.AllowedPrefixes = append(dx.AllowedPrefixes, aws.StringValue(prefix.Cidr))
+			}
+			sort.Strings(dx.AllowedPrefixes)
+			t.DxGateways = append(t.DxGateways, dx)
+		}
+		nextToken = aws.StringValue(result.NextToken)
+		if nextToken == "" {
+			break
+		}
+	}
+	sort.Slice(t.DxGateways, func(i, j int) bool { return t.DxGateways[i].ID < t.DxGateways[j].ID })
+	return nil
+}
+
+// VpnConnection returns the VPN connection of the attachment att, or nil if it is not a VPN or it is unknown.
+func (t *Tgw) VpnConnection(att *TgwAttachment) *VpnConnection {
+	for _, vpn := range t.VpnConnections {
+		if vpn.ID == att.ResourceID {
+			return vpn
+		}
+	}
+	return nil
+}
+
+// DxGateway returns the Direct Connect gateway of the attachment att, or nil if it is not a Direct Connect gateway or it is unknown.
+func (t *Tgw) DxGateway(att *TgwAttachment) *DxGateway {
+	for _, dx := range t.DxGateways {
+		if dx.ID == att.ResourceID {
+			return dx
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package awsrouter
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTgw_UpdateVpnConnections(t *testing.T) {
+	tgw := &Tgw{ID: "tgw-0a", Name: "core"}
+	if err := tgw.UpdateVpnConnections(context.Background(), TgwDescriberImpl{}); err != nil {
+		t.Fatalf("UpdateVpnConnections() error = %v", err)
+	}
+	want := []*VpnConnection{
+		{
+			ID:    "vpn-0a",
+			State: "available",
+			Tunnels: []VpnTunnel{
+				{OutsideIP: "203.0.113.1", Status: "UP", AcceptedRoutes: 12},
+				{OutsideIP: "203.0.113.2", Status: "DOWN"},
+			},
+		},
+		{
+			ID:    "vpn-0d",
+			State: "available",
+			Tunnels: []VpnTunnel{
+				{OutsideIP: "198.51.100.1", Status: "DOWN", StatusMessage: "IPSEC IS DOWN"},
+				{OutsideIP: "198.51.100.2", Status: "DOWN", StatusMessage: "IPSEC IS DOWN"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, tgw.VpnConnections); diff != "" {
+		t.Errorf("VpnConnections mismatch (-want +got):\n%s", diff)
+	}
+	tests := []struct {
+		vpn            *VpnConnection
+		down           bool
+		up             int
+		acceptedRoutes int
+	}{
+		{vpn: want[0], down: false, up: 1, acceptedRoutes: 12},
+		{vpn: want[1], down: true, up: 0, acceptedRoutes: 0},
+		{vpn: &VpnConnection{ID: "vpn-0u", State: "available"}, down: false},
+		{vpn: &VpnConnection{ID: "vpn-0x", State: "deleting", Tunnels: []VpnTunnel{{Status: "UP"}}}, down: true, up: 1},
+	}
+	for _, tt := range tests {
+		if got := tt.vpn.Down(); got != tt.down {
+			t.Errorf("%s Down() = %v, want %v", tt.vpn.ID, got, tt.down)
+		}
+		if got := tt.vpn.TunnelsUp(); got != tt.up {
+			t.Errorf("%s TunnelsUp() = %d, want %d", tt.vpn.ID, got, tt.up)
+		}
+		if got := tt.vpn.AcceptedRoutes(); got != tt.acceptedRoutes {
+			t.Errorf("%s AcceptedRoutes() = %d, want %d", tt.vpn.ID, got, tt.acceptedRoutes)
+		}
+	}
+}
+
+func TestTgw_UpdateDxGateways(t *testing.T) {
+	tgw := &Tgw{ID: "tgw-0a", Name: "core"}
+	if err := tgw.UpdateDxGateways(context.Background(), TgwDescriberImpl{}); err != nil {
+		t.Fatalf("UpdateDxGateways() error = %v", err)
+	}
+	want := []*DxGateway{
+		{ID: "dxgw-0a", State: "associating"},
+		{ID: "dxgw-0b", State: "associated", AllowedPrefixes: []string{"10.0.0.0/16", "10.1.0.0/16"}},
+	}
+	if diff := cmp.Diff(want, tgw.DxGateways); diff != "" {
+		t.Errorf("DxGateways mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want[1], tgw.DxGateway(&TgwAttachment{ID: "tgw-attach-0d", ResourceID: "dxgw-0b", Type: "direct-connect-gateway"})); diff != "" {
+		t.Errorf("DxGateway() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTgw_WalkVerdictVpn(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		verdict FlowVerdict
+	}{
+		{name: "tunnel up", status: "UP", verdict: FlowReachable},
+		{name: "tunnels down", status: "DOWN", verdict: FlowVpnDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tgw := whatIfTgws()[0]
+			// vpc-s is a VPN.
+			for _, rt := range tgw.RouteTables {
+				for _, att := range rt.Attachments {
+					if att.ID == "tgw-attach-0s" {
+						att.ResourceID, att.Type = "vpn-0s", "vpn"
+					}
+				}
+				for _, route := range rt.Routes {
+					for i := range route.TransitGatewayAttachments {
+						if aws.StringValue(route.TransitGatewayAttachments[i].TransitGatewayAttachmentId) == "tgw-attach-0s" {
+							route.TransitGatewayAttachments[i].ResourceId = aws.String("vpn-0s")
+							route.TransitGatewayAttachments[i].ResourceType = "vpn"
+						}
+					}
+				}
+			}
+			tgw.VpnConnections = []*VpnConnection{{ID: "vpn-0s", State: "available", Tunnels: []VpnTunnel{{Status: tt.status}, {Status: "DOWN"}}}}
+			verdict, path := tgw.WalkVerdict(net.ParseIP("10.0.0.10"), net.ParseIP("10.9.0.10"))
+			if verdict != tt.verdict {
+				t.Errorf("WalkVerdict() verdict = %s, want %s", verdict, tt.verdict)
+			}
+			if diff := cmp.Diff([]string{"tgw-attach-0a", "tgw-attach-0s"}, path); diff != "" {
+				t.Errorf("WalkVerdict() path mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package awsrouter
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// Severity is how important a Finding is.
+type Severity string
+
+const (
+	// SeverityWarning is a problem that drops or misroutes traffic.
+	SeverityWarning Severity = "warning"
+	// SeverityInfo is a configuration that is valid but usually not intended.
+	SeverityInfo Severity = "info"
+)
+
+// Finding is a problem found by Lint in the routing of a Transit Gateway.
+// RouteTableID and AttachmentID are empty when the finding is not about a route table or an attachment.
+type Finding struct {
+	Severity     Severity
+	Check        string
+	TgwID        string
+	RouteTableID string
+	AttachmentID string
+	Message      string
+}
+
+// Lint checks the routing of the Tgw and returns the findings of the route tables first and then of the attachments.
+// The checks are:
+//   - blackhole: a route that drops the traffic.
+//   - empty-route-table: a route table without routes.
+//   - unassociated-route-table: a route table without attachments associated, it is never used.
+//   - unassociated-attachment: an attachment that is the next hop of a route but is not associated to any route table,
+//     the return traffic from it is dropped.
+//   - propagation-without-routes: an attachment with an enabled propagation to a route table that has no routes from it,
+//     only checked when the propagations of the route table are known.
+func (t *Tgw) Lint() []Finding {
+	var findings []Finding
+	for _, rt := range t.RouteTables {
+		if len(rt.Routes) == 0 {
+			findings = append(findings, Finding{
+				Severity:     SeverityInfo,
+				Check:        "empty-route-table",
+				TgwID:        t.ID,
+				RouteTableID: rt.ID,
+				Message:      fmt.Sprintf("route table %s has no routes", rt.Name),
+			})
+		}
+		if len(rt.Attachments) == 0 {
+			findings = append(findings, Finding{
+				Severity:     SeverityInfo,
+				Check:        "unassociated-route-table",
+				TgwID:        t.ID,
+				RouteTableID: rt.ID,
+				Message:      fmt.Sprintf("route table %s has no attachments associated", rt.Name),
+			})
+		}
+		for _, route := range rt.Routes {
+			if route.State != types.TransitGatewayRouteStateBlackhole {
+				continue
+			}
+			destination := aws.StringValue(route.DestinationCidrBlock)
+			if destination == "" {
+				destination = aws.StringValue(route.PrefixListId)
+			}
+			findings = append(findings, Finding{
+				Severity:     SeverityWarning,
+				Check:        "blackhole",
+				TgwID:        t.ID,
+				RouteTableID: rt.ID,
+				Message:      fmt.Sprintf("route to %s in route table %s is a blackhole", destination, rt.Name),
+			})
+		}
+	propagations:
+		for _, att := range rt.Propagations {
+			for _, route := range rt.Routes {
+				if route.Type == types.TransitGatewayRouteTypePropagated && routeUses(route, att.ID) {
+					continue propagations
+				}
+			}
+			findings = append(findings, Finding{
+				Severity:     SeverityInfo,
+				Check:        "propagation-without-routes",
+				TgwID:        t.ID,
+				RouteTableID: rt.ID,
+				AttachmentID: att.ID,
+				Message:      fmt.Sprintf("attachment %s propagates to route table %s but has no routes in it", t.attachmentName(att.ID), rt.Name),
+			})
+		}
+	}
+	for _, att := range t.Attachments() {
+		// The peerings with Cloud WAN are associated to a policy table instead of a route table.
+		if t.AssociatedRouteTable(att.ID) != nil || t.PolicyTable(att) != nil || !t.isNextHop(att.ID) {
+			continue
+		}
+		name := att.Name
+		if name == "" {
+			name = att.ID
+		}
+		findings = append(findings, Finding{
+			Severity:     SeverityWarning,
+			Check:        "unassociated-attachment",
+			TgwID:        t.ID,
+			AttachmentID: att.ID,
+			Message:      fmt.Sprintf("attachment %s is a next hop but is not associated to any route table", name),
+		})
+	}
+	return findings
+}
+
+// isNextHop reports if the attachment is the next hop of a route in any route table.
+func (t *Tgw) isNextHop(attachmentID string) bool {
+	for _, rt := range t.RouteTables {
+		for _, route := range rt.Routes {
+			if routeUses(route, attachmentID) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package awsrouter
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTgw_Lint(t *testing.T) {
+	tgw := &Tgw{
+		ID: "tgw-0d7f9b0a",
+		RouteTables: []*TgwRouteTable{
+			csvTgw.RouteTables[0],
+			{ID: "tgw-rtb-0d7f9b0c", Name: "rtb3"},
+		},
+	}
+	want := []Finding{
+		{
+			Severity:     SeverityInfo,
+			Check:        "unassociated-route-table",
+			TgwID:        "tgw-0d7f9b0a",
+			RouteTableID: "tgw-rtb-0d7f9b0a",
+			Message:      "route table rtb1 has no attachments associated",
+		},
+		{
+			Severity:     SeverityWarning,
+			Check:        "blackhole",
+			TgwID:        "tgw-0d7f9b0a",
+			RouteTableID: "tgw-rtb-0d7f9b0a",
+			Message:      "route to pl-0a in route table rtb1 is a blackhole",
+		},
+		{
+			Severity:     SeverityInfo,
+			Check:        "empty-route-table",
+			TgwID:        "tgw-0d7f9b0a",
+			RouteTableID: "tgw-rtb-0d7f9b0c",
+			Message:      "route table rtb3 has no routes",
+		},
+		{
+			Severity:     SeverityInfo,
+			Check:        "unassociated-route-table",
+			TgwID:        "tgw-0d7f9b0a",
+			RouteTableID: "tgw-rtb-0d7f9b0c",
+			Message:      "route table rtb3 has no attachments associated",
+		},
+		{
+			Severity:     SeverityWarning,
+			Check:        "unassociated-attachment",
+			TgwID:        "tgw-0d7f9b0a",
+			AttachmentID: "tgw-attach-0b",
+			Message:      "attachment tgw-attach-0b is a next hop but is not associated to any route table",
+		},
+		{
+			Severity:     SeverityWarning,
+			Check:        "unassociated-attachment",
+			TgwID:        "tgw-0d7f9b0a",
+			AttachmentID: "tgw-attach-0c",
+			Message:      "attachment tgw-attach-0c is a next hop but is not associated to any route table",
+		},
+	}
+	if diff := cmp.Diff(want, tgw.Lint()); diff != "" {
+		t.Errorf("Tgw.Lint() mismatch (-want +got):\n%s", diff)
+	}
+
+	// An attachment associated to a policy table is not reported as unassociated.
+	tgw.PolicyTables = []*TgwPolicyTable{{ID: "tgw-ptb-0a", Attachments: []*TgwAttachment{{ID: "tgw-attach-0c", ResourceID: "core-network-0c", Type: "peering"}}}}
+	if diff := cmp.Diff(want[:len(want)-1], tgw.Lint()); diff != "" {
+		t.Errorf("Tgw.Lint() with policy tables mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,105 @@
+package awsrouter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/rogerscuall/aws-router/ports"
+)
+
+// TgwPolicyTable is a policy table of the Tgw, used instead of a route table by the peerings with AWS Cloud WAN.
+// Attachments are the attachments associated to it.
+type TgwPolicyTable struct {
+	ID          string
+	Name        string
+	State       string
+	Attachments []*TgwAttachment
+}
+
+// CloudWan reports if the attachment is a peering with an AWS Cloud WAN core network.
+func (a *TgwAttachment) CloudWan() bool {
+	return (a.Type == "peering" || a.Type == "tgw-peering") && strings.HasPrefix(a.ResourceID, "core-network-")
+}
+
+// UpdatePolicyTables updates the PolicyTables of the Tgw with its policy tables and their associations.
+// On error the PolicyTables are left unknown.
+func (t *Tgw) UpdatePolicyTables(ctx context.Context, api ports.AWSRouter) error {
+	var tables []types.TransitGatewayPolicyTable
+	nextToken := ""
+	for {
+		result, err := ports.GetTgwPolicyTables(ctx, api, ports.TgwPolicyTableInputFilter(t.ID, nextToken))
+		if err != nil {
+			t.PolicyTables = nil
+			return fmt.Errorf("error retrieving the policy tables of %s: %w", t.Name, err)
+		}
+		tables = append(tables, result.TransitGatewayPolicyTables...)
+		nextToken = aws.StringValue(result.NextToken)
+		if nextToken == "" {
+			break
+		}
+	}
+	policyTables := []*TgwPolicyTable{}
+	for _, table := range tables {
+		pt := &TgwPolicyTable{ID: aws.StringValue(table.TransitGatewayPolicyTableId), State: string(table.State)}
+		pt.Name = pt.ID
+		if name, err := GetNamesFromTags(table.Tags); err == nil {
+			pt.Name = name
+		}
+		var associations []types.TransitGatewayPolicyTableAssociation
+		for {
+			result, err := ports.GetTgwPolicyTableAssociations(ctx, api, ports.TgwPolicyTableAssociationInputFilter(pt.ID, nextToken))
+			if err != nil {
+				t.PolicyTables = nil
+				return fmt.Errorf("error retrieving the associations of the policy table %s: %w", pt.ID, err)
+			}
+			associations = append(associations, result.Associations...)
+			nextToken = aws.StringValue(result.NextToken)
+			if nextToken == "" {
+				break
+			}
+		}
+		for _, association := range associations {
+			id := aws.StringValue(association.TransitGatewayAttachmentId)
+			pt.Attachments = append(pt.Attachments, &TgwAttachment{
+				ID:         id,
+				Name:       t.GetAttachmentName(id),
+				ResourceID: aws.StringValue(association.ResourceId),
+				Type:       string(association.ResourceType),
+			})
+		}
+		sort.Slice(pt.Attachments, func(i, j int) bool { return pt.Attachments[i].ID < pt.Attachments[j].ID })
+		policyTables = append(policyTables, pt)
+	}
+	sort.Slice(policyTables, func(i, j int) bool { return policyTables[i].ID < policyTables[j].ID })
+	t.PolicyTables = policyTables
+	return nil
+}
+
+// PolicyTable returns the policy table associated to the attachment att, or nil if it has none or they are unknown.
+func (t *Tgw) PolicyTable(att *TgwAttachment) *TgwPolicyTable {
+	for _, pt := range t.PolicyTables {
+		for _, a := range pt.Attachments {
+			if a.ID == att.ID {
+				return pt
+			}
+		}
+	}
+	return nil
+}
+
+// leavesToCloudWan returns the error of a walk that leaves the Tgw to a Cloud WAN core network through att,
+// or nil if att is not a Cloud WAN peering and it is not associated to a policy table.
+func (t *Tgw) leavesToCloudWan(att *TgwAttachment) error {
+	pt := t.PolicyTable(att)
+	switch {
+	case pt != nil:
+		return fmt.Errorf("%w %s via policy table %s", ErrTgwCloudWan, att.ResourceID, pt.Name)
+	case att.CloudWan():
+		return fmt.Errorf("%w %s", ErrTgwCloudWan, att.ResourceID)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package awsrouter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTgw_UpdatePolicyTables(t *testing.T) {
+	tgw := &Tgw{ID: "tgw-0a", Name: "core"}
+	if err := tgw.UpdatePolicyTables(context.Background(), TgwDescriberImpl{}); err != nil {
+		t.Fatalf("UpdatePolicyTables() error = %v", err)
+	}
+	want := []*TgwPolicyTable{
+		{
+			ID:    "tgw-ptb-0a",
+			Name:  "cloudwan",
+			State: "available",
+			Attachments: []*TgwAttachment{
+				{ID: "tgw-attach-0w", ResourceID: "core-network-0a", Type: "tgw-peering"},
+			},
+		},
+		{ID: "tgw-ptb-0b", Name: "tgw-ptb-0b", State: "available"},
+	}
+	if diff := cmp.Diff(want, tgw.PolicyTables); diff != "" {
+		t.Errorf("PolicyTables mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want[0], tgw.PolicyTable(&TgwAttachment{ID: "tgw-attach-0w"})); diff != "" {
+		t.Errorf("PolicyTable() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTgwAttachment_CloudWan(t *testing.T) {
+	tests := []struct {
+		att  *TgwAttachment
+		want bool
+	}{
+		{att: &TgwAttachment{ResourceID: "core-network-0a", Type: "peering"}, want: true},
+		{att: &TgwAttachment{ResourceID: "core-network-0a", Type: "tgw-peering"}, want: true},
+		{att: &TgwAttachment{ResourceID: "tgw-0b", Type: "peering"}, want: false},
+		{att: &TgwAttachment{ResourceID: "vpc-0a", Type: "vpc"}, want: false},
+	}
+	for _, tt := range tests {
+		if got := tt.att.CloudWan(); got != tt.want {
+			t.Errorf("%s %s CloudWan() = %v, want %v", tt.att.Type, tt.att.ResourceID, got, tt.want)
+		}
+	}
+}
+
+func TestTgw_WalkVerdictCloudWan(t *testing.T) {
+	tests := []struct {
+		name         string
+		policyTables []*TgwPolicyTable
+		wantErr      string
+	}{
+		{
+			name:         "with policy table",
+			policyTables: []*TgwPolicyTable{{ID: "tgw-ptb-0a", Name: "cloudwan", Attachments: []*TgwAttachment{{ID: "tgw-attach-0s"}}}},
+			wantErr:      "awsrouter: leaves to Cloud WAN core network core-network-0s via policy table cloudwan",
+		},
+		{
+			name:    "without policy tables",
+			wantErr: "awsrouter: leaves to Cloud WAN core network core-network-0s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tgw := whatIfTgws()[0]
+			// vpc-s is a peering with a Cloud WAN core network.
+			for _, rt := range tgw.RouteTables {
+				for _, att := range rt.Attachments {
+					if att.ID == "tgw-attach-0s" {
+						att.ResourceID, att.Type = "core-network-0s", "peering"
+					}
+				}
+				for _, route := range rt.Routes {
+					for i := range route.TransitGatewayAttachments {
+						if aws.StringValue(route.TransitGatewayAttachments[i].TransitGatewayAttachmentId) == "tgw-attach-0s" {
+							route.TransitGatewayAttachments[i].ResourceId = aws.String("core-network-0s")
+							route.TransitGatewayAttachments[i].ResourceType = "peering"
+						}
+					}
+				}
+			}
+			tgw.PolicyTables = tt.policyTables
+			attPath := NewAttPath()
+			attPath.Tgw = tgw
+			err := attPath.Walk(context.TODO(), nil, net.ParseIP("10.0.0.10"), net.ParseIP("10.9.0.10"))
+			if !errors.Is(err, ErrTgwCloudWan) || err.Error() != tt.wantErr {
+				t.Errorf("Walk() error = %v, want %s", err, tt.wantErr)
+			}
+			verdict, path := tgw.WalkVerdict(net.ParseIP("10.0.0.10"), net.ParseIP("10.9.0.10"))
+			if verdict != FlowCloudWan {
+				t.Errorf("WalkVerdict() verdict = %s, want %s", verdict, FlowCloudWan)
+			}
+			if diff := cmp.Diff([]string{"tgw-attach-0a", "tgw-attach-0s"}, path); diff != "" {
+				t.Errorf("WalkVerdict() path mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
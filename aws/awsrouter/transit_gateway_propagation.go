@@ -0,0 +1,82 @@
+package awsrouter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// ExplainPropagation explains why the route table rt has, or does not have, a propagated route to prefix.
+// The attachments that propagate prefix are found from the propagated routes of all the route tables of the Tgw,
+// and the Propagations of rt, when known, tell if the propagation to rt is enabled.
+func (t *Tgw) ExplainPropagation(rt *TgwRouteTable, prefix string) []string {
+	var reasons []string
+	for _, route := range rt.Routes {
+		if aws.StringValue(route.DestinationCidrBlock) != prefix {
+			continue
+		}
+		if route.Type == types.TransitGatewayRouteTypeStatic {
+			reasons = append(reasons, fmt.Sprintf("the route to %s in route table %s is static, it takes precedence over any propagated route", prefix, rt.Name))
+			for _, att := range t.propagatingAttachments(prefix) {
+				if propagates, _ := rt.HasPropagation(att); propagates {
+					reasons = append(reasons, fmt.Sprintf("attachment %s propagates %s to route table %s but the static route overrides it", t.attachmentName(att), prefix, rt.Name))
+				}
+			}
+			return reasons
+		}
+		for _, att := range route.TransitGatewayAttachments {
+			id := aws.StringValue(att.TransitGatewayAttachmentId)
+			switch propagates, known := rt.HasPropagation(id); {
+			case !known:
+				reasons = append(reasons, fmt.Sprintf("the route to %s in route table %s is propagated by attachment %s", prefix, rt.Name, t.attachmentName(id)))
+			case propagates:
+				reasons = append(reasons, fmt.Sprintf("the route to %s in route table %s is propagated by attachment %s, its propagation is enabled", prefix, rt.Name, t.attachmentName(id)))
+			default:
+				reasons = append(reasons, fmt.Sprintf("the route to %s in route table %s is propagated by attachment %s but its propagation is not enabled, the route is stale", prefix, rt.Name, t.attachmentName(id)))
+			}
+		}
+		return reasons
+	}
+	atts := t.propagatingAttachments(prefix)
+	if len(atts) == 0 {
+		return []string{fmt.Sprintf("no attachment propagates %s to any route table of %s", prefix, t.Name)}
+	}
+	for _, att := range atts {
+		if propagates, known := rt.HasPropagation(att); known && propagates {
+			reasons = append(reasons, fmt.Sprintf("attachment %s propagates to route table %s but the route to %s is missing", t.attachmentName(att), rt.Name, prefix))
+			continue
+		}
+		var names []string
+		for _, other := range t.PropagatingRouteTables(att) {
+			names = append(names, other.Name)
+		}
+		reasons = append(reasons, fmt.Sprintf("attachment %s propagates %s to %s but not to route table %s", t.attachmentName(att), prefix, noneIfEmpty(strings.Join(names, ", ")), rt.Name))
+	}
+	return reasons
+}
+
+// propagatingAttachments returns the sorted IDs of the attachments with a propagated route to prefix in any route table.
+func (t *Tgw) propagatingAttachments(prefix string) []string {
+	var ids []string
+	for _, rt := range t.RouteTables {
+		for _, route := range rt.Routes {
+			if route.Type != types.TransitGatewayRouteTypePropagated || aws.StringValue(route.DestinationCidrBlock) != prefix {
+				continue
+			}
+			for _, att := range route.TransitGatewayAttachments {
+				ids = append(ids, aws.StringValue(att.TransitGatewayAttachmentId))
+			}
+		}
+	}
+	return sortedUnique(ids)
+}
+
+// attachmentName returns the name of the attachment, or its ID if it has no name.
+func (t *Tgw) attachmentName(attachmentID string) string {
+	if name := t.GetAttachmentName(attachmentID); name != "" {
+		return name
+	}
+	return attachmentID
+}
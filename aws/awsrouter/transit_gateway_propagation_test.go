@@ -0,0 +1,187 @@
+package awsrouter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/go-cmp/cmp"
+)
+
+// propagationTgw returns the TGW of whatIfTgws where the propagations of spokes are known:
+// a propagates, b does not and its route is stale, and the route to s is static.
+func propagationTgw() *Tgw {
+	tgw := whatIfTgws()[0]
+	spokes := tgw.RouteTables[0]
+	spokes.Propagations = []*TgwAttachment{spokes.Attachments[0]}
+	spokes.Routes[2] = diffRoute("10.9.0.0/16", "active", "tgw-attach-0s")
+	spokes.Routes[2].Type = types.TransitGatewayRouteTypeStatic
+	return tgw
+}
+
+func TestTgwRouteTable_UpdatePropagations(t *testing.T) {
+	rt := &TgwRouteTable{ID: "tgw-rtb-0b7ddaf4d87a0e6a0"}
+	if _, known := rt.HasPropagation("tgw-attach-080f3014bd52ec95f"); known {
+		t.Fatalf("HasPropagation() known before UpdatePropagations")
+	}
+	if err := newTgwFromRouteTables(rt).UpdateTgwRouteTablesPropagations(context.Background(), TgwDescriberImpl{}); err != nil {
+		t.Fatalf("UpdateTgwRouteTablesPropagations() error = %v", err)
+	}
+	want := []*TgwAttachment{{ID: "tgw-attach-080f3014bd52ec95f", ResourceID: "vpc-0af25be733475a425", Type: "vpc"}}
+	if diff := cmp.Diff(want, rt.Propagations); diff != "" {
+		t.Errorf("Propagations mismatch (-want +got):\n%s", diff)
+	}
+	if propagates, known := rt.HasPropagation("tgw-attach-080f3014bd52ec96f"); propagates || !known {
+		t.Errorf("HasPropagation() of a disabled propagation = %v, %v, want false, true", propagates, known)
+	}
+}
+
+// throttledPropagations is a TgwDescriberImpl where the propagations of the route table rtID can not be retrieved.
+type throttledPropagations struct {
+	TgwDescriberImpl
+	rtID string
+}
+
+func (t throttledPropagations) GetTransitGatewayRouteTablePropagations(ctx context.Context, params *ec2.GetTransitGatewayRouteTablePropagationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayRouteTablePropagationsOutput, error) {
+	if aws.StringValue(params.TransitGatewayRouteTableId) == t.rtID {
+		return nil, errors.New("Throttling: Rate exceeded")
+	}
+	return t.TgwDescriberImpl.GetTransitGatewayRouteTablePropagations(ctx, params, optFns...)
+}
+
+func TestTgw_UpdateTgwRouteTablesPropagationsPartial(t *testing.T) {
+	failed := &TgwRouteTable{ID: "tgw-rtb-0a"}
+	updated := &TgwRouteTable{ID: "tgw-rtb-0b"}
+	err := newTgwFromRouteTables(failed, updated).UpdateTgwRouteTablesPropagations(context.Background(), throttledPropagations{rtID: failed.ID})
+	var partial *PartialRoutingError
+	if !errors.As(err, &partial) || !partial.Incomplete(failed.ID) || partial.Incomplete(updated.ID) {
+		t.Fatalf("UpdateTgwRouteTablesPropagations() error = %v, want %s incomplete", err, failed.ID)
+	}
+	if failed.Propagations != nil || len(updated.Propagations) != 1 {
+		t.Errorf("Propagations = %v and %v, want unknown and updated", failed.Propagations, updated.Propagations)
+	}
+}
+
+// newTgwFromRouteTables returns a Tgw with the route tables rts.
+func newTgwFromRouteTables(rts ...*TgwRouteTable) *Tgw {
+	return &Tgw{ID: "tgw-0a", Name: "core", RouteTables: rts}
+}
+
+func TestTgw_PropagatingRouteTables(t *testing.T) {
+	tgw := propagationTgw()
+	tests := []struct {
+		attachment string
+		want       []string
+	}{
+		// spokes is known from its Propagations, shared is inferred from its routes.
+		{attachment: "tgw-attach-0a", want: []string{"spokes", "shared"}},
+		{attachment: "tgw-attach-0b", want: []string{"shared"}},
+		{attachment: "tgw-attach-0s", want: []string{"shared"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.attachment, func(t *testing.T) {
+			var got []string
+			for _, rt := range tgw.PropagatingRouteTables(tt.attachment) {
+				got = append(got, rt.Name)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("PropagatingRouteTables() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTgw_ExplainPropagation(t *testing.T) {
+	tests := []struct {
+		name   string
+		tgw    func() *Tgw
+		rt     int
+		prefix string
+		want   []string
+	}{
+		{
+			name:   "Propagated",
+			tgw:    propagationTgw,
+			prefix: "10.0.0.0/16",
+			want:   []string{"the route to 10.0.0.0/16 in route table spokes is propagated by attachment vpc-a, its propagation is enabled"},
+		},
+		{
+			name:   "Stale",
+			tgw:    propagationTgw,
+			prefix: "10.1.0.0/16",
+			want:   []string{"the route to 10.1.0.0/16 in route table spokes is propagated by attachment vpc-b but its propagation is not enabled, the route is stale"},
+		},
+		{
+			name:   "Unknown Propagations",
+			tgw:    propagationTgw,
+			rt:     1,
+			prefix: "10.1.0.0/16",
+			want:   []string{"the route to 10.1.0.0/16 in route table shared is propagated by attachment vpc-b"},
+		},
+		{
+			name:   "Static",
+			tgw:    propagationTgw,
+			prefix: "10.9.0.0/16",
+			want:   []string{"the route to 10.9.0.0/16 in route table spokes is static, it takes precedence over any propagated route"},
+		},
+		{
+			name: "Missing",
+			tgw: func() *Tgw {
+				tgw := propagationTgw()
+				tgw.RouteTables[0].Routes = tgw.RouteTables[0].Routes[1:]
+				return tgw
+			},
+			prefix: "10.0.0.0/16",
+			want:   []string{"attachment vpc-a propagates to route table spokes but the route to 10.0.0.0/16 is missing"},
+		},
+		{
+			name: "Not Propagated",
+			tgw: func() *Tgw {
+				tgw := propagationTgw()
+				tgw.RouteTables[0].Routes = tgw.RouteTables[0].Routes[:1]
+				return tgw
+			},
+			prefix: "10.1.0.0/16",
+			want:   []string{"attachment vpc-b propagates 10.1.0.0/16 to shared but not to route table spokes"},
+		},
+		{
+			name:   "No Attachment",
+			tgw:    propagationTgw,
+			prefix: "10.5.0.0/16",
+			want:   []string{"no attachment propagates 10.5.0.0/16 to any route table of core"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tgw := tt.tgw()
+			if diff := cmp.Diff(tt.want, tgw.ExplainPropagation(tgw.RouteTables[tt.rt], tt.prefix)); diff != "" {
+				t.Errorf("ExplainPropagation() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTgw_LintPropagationWithoutRoutes(t *testing.T) {
+	tgw := propagationTgw()
+	tgw.RouteTables[1].Propagations = []*TgwAttachment{{ID: "tgw-attach-0c", ResourceID: "vpn-0c", Type: "vpn"}}
+	var got []Finding
+	for _, f := range tgw.Lint() {
+		if f.Check == "propagation-without-routes" {
+			got = append(got, f)
+		}
+	}
+	want := []Finding{{
+		Severity:     SeverityInfo,
+		Check:        "propagation-without-routes",
+		TgwID:        "tgw-0a",
+		RouteTableID: "tgw-rtb-0s",
+		AttachmentID: "tgw-attach-0c",
+		Message:      "attachment tgw-attach-0c propagates to route table shared but has no routes in it",
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Lint() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,220 @@
+package awsrouter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/rogerscuall/aws-router/ports"
+)
+
+// PrefixList is a managed prefix list referenced by a route.
+// A reference counts MaxEntries routes against the route quotas, not the entries in use.
+type PrefixList struct {
+	ID         string
+	Name       string
+	MaxEntries int
+}
+
+// UpdatePrefixLists updates the PrefixLists of the Tgw with the prefix lists referenced by its routes.
+// On error the PrefixLists are left unknown, every reference then counts as one route.
+func (t *Tgw) UpdatePrefixLists(ctx context.Context, api ports.AWSRouter) error {
+	ids := t.prefixListIDs()
+	t.PrefixLists = []*PrefixList{}
+	if len(ids) == 0 {
+		return nil
+	}
+	nextToken := ""
+	for {
+		result, err := ports.GetPrefixLists(ctx, api, ports.PrefixListInputFilter(ids, nextToken))
+		if err != nil {
+			t.PrefixLists = nil
+			return fmt.Errorf("error retrieving the prefix lists of %s: %w", t.Name, err)
+		}
+		for _, pl := range result.PrefixLists {
+			t.PrefixLists = append(t.PrefixLists, &PrefixList{
+				ID:         aws.StringValue(pl.PrefixListId),
+				Name:       aws.StringValue(pl.PrefixListName),
+				MaxEntries: int(aws.Int32Value(pl.MaxEntries)),
+			})
+		}
+		nextToken = aws.StringValue(result.NextToken)
+		if nextToken == "" {
+			break
+		}
+	}
+	sort.Slice(t.PrefixLists, func(i, j int) bool { return t.PrefixLists[i].ID < t.PrefixLists[j].ID })
+	return nil
+}
+
+// prefixListIDs returns the sorted IDs of the prefix lists referenced by the routes of the Tgw.
+func (t *Tgw) prefixListIDs() []string {
+	var ids []string
+	for _, rt := range t.RouteTables {
+		for _, route := range rt.Routes {
+			if route.PrefixListId != nil {
+				ids = append(ids, *route.PrefixListId)
+			}
+		}
+	}
+	return sortedUnique(ids)
+}
+
+// prefixList returns the prefix list with the ID id, or nil if it is unknown.
+func (t *Tgw) prefixList(id string) *PrefixList {
+	for _, pl := range t.PrefixLists {
+		if pl.ID == id {
+			return pl
+		}
+	}
+	return nil
+}
+
+// routeEntries returns the number of routes of rt counted by the quotas, a prefix list counts its MaxEntries.
+func (t *Tgw) routeEntries(rt *TgwRouteTable) int {
+	n := 0
+	for _, route := range rt.Routes {
+		if route.PrefixListId == nil {
+			n++
+			continue
+		}
+		if pl := t.prefixList(*route.PrefixListId); pl != nil {
+			n += pl.MaxEntries
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// Quota is the name of an AWS quota of a Transit Gateway.
+type Quota string
+
+const (
+	// QuotaRoutesPerTgw is the routes across all the route tables of a Transit Gateway.
+	QuotaRoutesPerTgw Quota = "routes-per-tgw"
+	// QuotaRoutesPerRouteTable is the routes of a route table, limited in AWS by the routes of the Transit Gateway.
+	QuotaRoutesPerRouteTable Quota = "routes-per-route-table"
+	// QuotaRouteTablesPerTgw is the route tables of a Transit Gateway.
+	QuotaRouteTablesPerTgw Quota = "route-tables-per-tgw"
+	// QuotaAttachmentsPerTgw is the attachments of a Transit Gateway. The use is a lower bound, see QuotaUsages.
+	QuotaAttachmentsPerTgw Quota = "attachments-per-tgw"
+)
+
+// Limits are the values of the quotas and the percentages of use that raise a warning or a critical level.
+type Limits struct {
+	RoutesPerTgw        int
+	RoutesPerRouteTable int
+	RouteTablesPerTgw   int
+	AttachmentsPerTgw   int
+	WarningPercent      float64
+	CriticalPercent     float64
+}
+
+// DefaultLimits returns the default AWS quotas of a Transit Gateway, with a warning at 80% and critical at 95%.
+func DefaultLimits() Limits {
+	return Limits{
+		RoutesPerTgw:        RoutesPerTgwQuota,
+		RoutesPerRouteTable: RoutesPerTgwQuota,
+		RouteTablesPerTgw:   20,
+		AttachmentsPerTgw:   5000,
+		WarningPercent:      80,
+		CriticalPercent:     95,
+	}
+}
+
+// QuotaLevel is how close a quota is to its limit.
+type QuotaLevel string
+
+const (
+	// QuotaOK is below the warning percentage.
+	QuotaOK QuotaLevel = "ok"
+	// QuotaWarning is at or above the warning percentage.
+	QuotaWarning QuotaLevel = "warning"
+	// QuotaCritical is at or above the critical percentage.
+	QuotaCritical QuotaLevel = "critical"
+)
+
+// QuotaUsage is the use of a quota by a Transit Gateway, or by one of its route tables.
+type QuotaUsage struct {
+	Quota          Quota
+	TgwID          string
+	TgwName        string
+	RouteTableID   string
+	RouteTableName string
+	Used           int
+	Limit          int
+	Level          QuotaLevel
+}
+
+// Percent returns the percentage of the limit used.
+func (u QuotaUsage) Percent() float64 {
+	if u.Limit <= 0 {
+		return 0
+	}
+	return float64(u.Used) * 100 / float64(u.Limit)
+}
+
+// PrefixListUsage is how many routes a prefix list adds to the quotas of a Transit Gateway.
+// References is the number of routes to the prefix list and Entries the routes counted for all of them.
+type PrefixListUsage struct {
+	PrefixList
+	References int
+	Entries    int
+}
+
+// QuotaUsages returns the use of the quotas of the Tgw, first the ones of the Tgw and then the routes of each route table.
+// The attachments are counted with Attachments, so an attachment that is not associated, does not propagate and is not
+// the next hop of a route is missing: the use of QuotaAttachmentsPerTgw is a lower bound.
+func (t *Tgw) QuotaUsages(limits Limits) []QuotaUsage {
+	usage := func(quota Quota, used, limit int) QuotaUsage {
+		u := QuotaUsage{Quota: quota, TgwID: t.ID, TgwName: t.Name, Used: used, Limit: limit}
+		u.Level = limits.level(u.Percent())
+		return u
+	}
+	result := []QuotaUsage{
+		usage(QuotaAttachmentsPerTgw, len(t.Attachments()), limits.AttachmentsPerTgw),
+		usage(QuotaRouteTablesPerTgw, len(t.RouteTables), limits.RouteTablesPerTgw),
+		usage(QuotaRoutesPerTgw, t.RouteCount(), limits.RoutesPerTgw),
+	}
+	for _, rt := range t.RouteTables {
+		u := usage(QuotaRoutesPerRouteTable, t.routeEntries(rt), limits.RoutesPerRouteTable)
+		u.RouteTableID, u.RouteTableName = rt.ID, rt.Name
+		result = append(result, u)
+	}
+	return result
+}
+
+// PrefixListUsages returns the routes added by each prefix list referenced by the Tgw, sorted by ID.
+// A prefix list that is not in PrefixLists has MaxEntries 1, like it is counted by the quotas.
+func (t *Tgw) PrefixListUsages() []PrefixListUsage {
+	var result []PrefixListUsage
+	for _, id := range t.prefixListIDs() {
+		u := PrefixListUsage{PrefixList: PrefixList{ID: id, MaxEntries: 1}}
+		if pl := t.prefixList(id); pl != nil {
+			u.PrefixList = *pl
+		}
+		for _, rt := range t.RouteTables {
+			for _, route := range rt.Routes {
+				if aws.StringValue(route.PrefixListId) == id {
+					u.References++
+				}
+			}
+		}
+		u.Entries = u.References * u.MaxEntries
+		result = append(result, u)
+	}
+	return result
+}
+
+// level returns the level of a quota used at percent.
+func (l Limits) level(percent float64) QuotaLevel {
+	switch {
+	case percent >= l.CriticalPercent:
+		return QuotaCritical
+	case percent >= l.WarningPercent:
+		return QuotaWarning
+	}
+	return QuotaOK
+}
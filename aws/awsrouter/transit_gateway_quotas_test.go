@@ -0,0 +1,54 @@
+package awsrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// quotaTgw returns a copy of csvTgw, where rtb1 has a route and a reference to the prefix list pl-0a.
+func quotaTgw(t *testing.T) *Tgw {
+	tgws, err := CopyTgws([]*Tgw{csvTgw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tgws[0]
+}
+
+func TestTgw_UpdatePrefixLists(t *testing.T) {
+	tgw := quotaTgw(t)
+	if got := tgw.RouteCount(); got != 2 {
+		t.Errorf("RouteCount() without prefix lists = %v, want 2", got)
+	}
+	if err := tgw.UpdatePrefixLists(context.Background(), TgwDescriberImpl{}); err != nil {
+		t.Fatalf("UpdatePrefixLists() error = %v", err)
+	}
+	want := []*PrefixList{{ID: "pl-0a", Name: "offices", MaxEntries: 25}}
+	if diff := cmp.Diff(want, tgw.PrefixLists); diff != "" {
+		t.Errorf("PrefixLists mismatch (-want +got):\n%s", diff)
+	}
+	if got := tgw.RouteCount(); got != 26 {
+		t.Errorf("RouteCount() = %v, want 26", got)
+	}
+}
+
+func TestTgw_QuotaUsages(t *testing.T) {
+	tgw := quotaTgw(t)
+	tgw.PrefixLists = []*PrefixList{{ID: "pl-0a", Name: "offices", MaxEntries: 25}}
+	limits := Limits{RoutesPerTgw: 100, RoutesPerRouteTable: 30, RouteTablesPerTgw: 2, AttachmentsPerTgw: 10, WarningPercent: 80, CriticalPercent: 95}
+	want := []QuotaUsage{
+		{Quota: QuotaAttachmentsPerTgw, TgwID: "tgw-0d7f9b0a", TgwName: "testA", Used: 2, Limit: 10, Level: QuotaOK},
+		{Quota: QuotaRouteTablesPerTgw, TgwID: "tgw-0d7f9b0a", TgwName: "testA", Used: 2, Limit: 2, Level: QuotaCritical},
+		{Quota: QuotaRoutesPerTgw, TgwID: "tgw-0d7f9b0a", TgwName: "testA", Used: 26, Limit: 100, Level: QuotaOK},
+		{Quota: QuotaRoutesPerRouteTable, TgwID: "tgw-0d7f9b0a", TgwName: "testA", RouteTableID: "tgw-rtb-0d7f9b0a", RouteTableName: "rtb1", Used: 26, Limit: 30, Level: QuotaWarning},
+		{Quota: QuotaRoutesPerRouteTable, TgwID: "tgw-0d7f9b0a", TgwName: "testA", RouteTableID: "tgw-rtb-0d7f9b0b", RouteTableName: "rtb2", Used: 0, Limit: 30, Level: QuotaOK},
+	}
+	if diff := cmp.Diff(want, tgw.QuotaUsages(limits)); diff != "" {
+		t.Errorf("QuotaUsages() mismatch (-want +got):\n%s", diff)
+	}
+	wantPrefixLists := []PrefixListUsage{{PrefixList: PrefixList{ID: "pl-0a", Name: "offices", MaxEntries: 25}, References: 1, Entries: 25}}
+	if diff := cmp.Diff(wantPrefixLists, tgw.PrefixListUsages()); diff != "" {
+		t.Errorf("PrefixListUsages() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,138 @@
+package awsrouter
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// routeIndex is a binary prefix trie built from the routes of a TgwRouteTable.
+// IPv4 and IPv6 prefixes are kept in separate tries, a lookup only walks the bits of its own address family.
+// The index stores positions in the routes slice, so it is only valid for the slice it was built from.
+type routeIndex struct {
+	v4     *routeIndexNode
+	v6     *routeIndexNode
+	routes []types.TransitGatewayRoute
+}
+
+// routeIndexNode is a node in the routeIndex trie.
+// Only the nodes that terminate a prefix have a route, the rest have route set to -1.
+type routeIndexNode struct {
+	children [2]*routeIndexNode
+	route    int
+	prefix   *net.IPNet
+}
+
+func newRouteIndexNode() *routeIndexNode {
+	return &routeIndexNode{route: -1}
+}
+
+// newRouteIndex parses every destination CIDR in routes once and builds the trie.
+// Routes without a DestinationCidrBlock (e.g. prefix list routes) are not indexed.
+// When two routes have the same prefix the first one is kept, this matches the behavior of a linear scan.
+func newRouteIndex(routes []types.TransitGatewayRoute) (*routeIndex, error) {
+	idx := &routeIndex{
+		v4:     newRouteIndexNode(),
+		v6:     newRouteIndexNode(),
+		routes: routes,
+	}
+	for i, route := range routes {
+		if route.DestinationCidrBlock == nil {
+			continue
+		}
+		_, prefix, err := net.ParseCIDR(*route.DestinationCidrBlock)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the CIDR %w", err)
+		}
+		node := idx.root(prefix.IP)
+		key := indexKey(prefix.IP)
+		ones, _ := prefix.Mask.Size()
+		for bit := 0; bit < ones; bit++ {
+			b := keyBit(key, bit)
+			if node.children[b] == nil {
+				node.children[b] = newRouteIndexNode()
+			}
+			node = node.children[b]
+		}
+		if node.route == -1 {
+			node.route = i
+			node.prefix = prefix
+		}
+	}
+	return idx, nil
+}
+
+// validFor returns true if the index was built from the same routes slice.
+// A route changed in place is not detected, see BuildRouteIndex.
+func (idx *routeIndex) validFor(routes []types.TransitGatewayRoute) bool {
+	if idx == nil || len(idx.routes) != len(routes) {
+		return false
+	}
+	if len(routes) == 0 {
+		return true
+	}
+	return &idx.routes[0] == &routes[0]
+}
+
+// root returns the trie for the address family of ip.
+func (idx *routeIndex) root(ip net.IP) *routeIndexNode {
+	if ip.To4() != nil {
+		return idx.v4
+	}
+	return idx.v6
+}
+
+// longestMatch returns the position of the most specific route that contains ip and its parsed prefix.
+// If no route contains ip it returns -1 and a nil prefix.
+func (idx *routeIndex) longestMatch(ip net.IP) (int, *net.IPNet) {
+	key := indexKey(ip)
+	if key == nil {
+		return -1, nil
+	}
+	node := idx.root(ip)
+	best, bestPrefix := node.route, node.prefix
+	for bit := 0; bit < len(key)*8; bit++ {
+		node = node.children[keyBit(key, bit)]
+		if node == nil {
+			break
+		}
+		if node.route != -1 {
+			best, bestPrefix = node.route, node.prefix
+		}
+	}
+	return best, bestPrefix
+}
+
+// exactMatch returns the position of the route with exactly the given prefix, or -1 if there is none.
+func (idx *routeIndex) exactMatch(prefix net.IPNet) int {
+	key := indexKey(prefix.IP)
+	if key == nil {
+		return -1
+	}
+	ones, bits := prefix.Mask.Size()
+	if bits != len(key)*8 {
+		return -1
+	}
+	node := idx.root(prefix.IP)
+	for bit := 0; bit < ones; bit++ {
+		node = node.children[keyBit(key, bit)]
+		if node == nil {
+			return -1
+		}
+	}
+	return node.route
+}
+
+// indexKey returns the bytes used to walk the trie, 4 bytes for IPv4 and 16 bytes for IPv6.
+func indexKey(ip net.IP) []byte {
+	if v4 := ip.To4(); v4 != nil {
+		return v4
+	}
+	return ip.To16()
+}
+
+// keyBit returns the bit at position i of key, counting from the most significant bit.
+func keyBit(key []byte, i int) int {
+	return int(key[i/8]>>(7-uint(i%8))) & 1
+}
@@ -0,0 +1,207 @@
+package awsrouter
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// bestRouteToIPLinear is the linear scan used before the route index, kept as the reference for tests and benchmarks.
+func bestRouteToIPLinear(t TgwRouteTable, ipAddress net.IP) (types.TransitGatewayRoute, error) {
+	var mask net.IPMask
+	result := types.TransitGatewayRoute{}
+	for _, route := range t.Routes {
+		_, subnet, err := net.ParseCIDR(*route.DestinationCidrBlock)
+		if err != nil {
+			return types.TransitGatewayRoute{}, fmt.Errorf("error parsing the CIDR %w", err)
+		}
+		if subnet.Contains(ipAddress) {
+			currentMaskSize, _ := subnet.Mask.Size()
+			if mask == nil {
+				mask = subnet.Mask
+				result = route
+			} else {
+				maskSize, _ := mask.Size()
+				if currentMaskSize > maskSize {
+					mask = subnet.Mask
+					result = route
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// randomRoutes returns n IPv4 routes with random prefixes between /8 and /28 and the default route.
+func randomRoutes(r *rand.Rand, n int) []types.TransitGatewayRoute {
+	routes := []types.TransitGatewayRoute{
+		{DestinationCidrBlock: aws.String("0.0.0.0/0"), State: "active", Type: "static"},
+	}
+	for i := 0; i < n; i++ {
+		ip := net.IPv4(10, byte(r.Intn(4)), byte(r.Intn(256)), byte(r.Intn(256)))
+		ones := 8 + r.Intn(21)
+		prefix := net.IPNet{IP: ip.Mask(net.CIDRMask(ones, 32)), Mask: net.CIDRMask(ones, 32)}
+		routes = append(routes, types.TransitGatewayRoute{
+			DestinationCidrBlock: aws.String(prefix.String()),
+			State:                "active",
+			Type:                 "propagated",
+		})
+	}
+	return routes
+}
+
+func randomIPs(r *rand.Rand, n int) []net.IP {
+	var ips []net.IP
+	for i := 0; i < n; i++ {
+		ips = append(ips, net.IPv4(10, byte(r.Intn(4)), byte(r.Intn(256)), byte(r.Intn(256))))
+	}
+	return ips
+}
+
+func TestRouteIndex_longestMatch(t *testing.T) {
+	routes := []types.TransitGatewayRoute{
+		{DestinationCidrBlock: aws.String("10.0.0.0/16")},
+		{DestinationCidrBlock: aws.String("10.0.1.0/24")},
+		{DestinationCidrBlock: aws.String("10.0.1.0/24")},
+		{DestinationCidrBlock: aws.String("2001:db8::/32")},
+		{DestinationCidrBlock: aws.String("2001:db8:1::/48")},
+		{PrefixListId: aws.String("pl-0123456789")},
+	}
+	idx, err := newRouteIndex(routes)
+	if err != nil {
+		t.Fatalf("newRouteIndex() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		ip   net.IP
+		want int
+	}{
+		{name: "IPv4 Most Specific", ip: net.ParseIP("10.0.1.1"), want: 1},
+		{name: "IPv4 Less Specific", ip: net.ParseIP("10.0.2.1"), want: 0},
+		{name: "IPv4 No Match", ip: net.ParseIP("192.168.0.1"), want: -1},
+		{name: "IPv6 Most Specific", ip: net.ParseIP("2001:db8:1::1"), want: 4},
+		{name: "IPv6 Less Specific", ip: net.ParseIP("2001:db8:2::1"), want: 3},
+		{name: "IPv6 No Match", ip: net.ParseIP("2001:db9::1"), want: -1},
+		{name: "Nil IP", ip: nil, want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := idx.longestMatch(tt.ip); got != tt.want {
+				t.Errorf("routeIndex.longestMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteIndex_exactMatch(t *testing.T) {
+	routes := []types.TransitGatewayRoute{
+		{DestinationCidrBlock: aws.String("10.0.0.0/16")},
+		{DestinationCidrBlock: aws.String("10.0.1.0/24")},
+		{DestinationCidrBlock: aws.String("2001:db8::/32")},
+	}
+	idx, err := newRouteIndex(routes)
+	if err != nil {
+		t.Fatalf("newRouteIndex() error = %v", err)
+	}
+	tests := []struct {
+		name   string
+		prefix string
+		want   int
+	}{
+		{name: "IPv4", prefix: "10.0.1.0/24", want: 1},
+		{name: "IPv4 Not Present", prefix: "10.0.0.0/24", want: -1},
+		{name: "IPv6", prefix: "2001:db8::/32", want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, prefix, _ := net.ParseCIDR(tt.prefix)
+			if got := idx.exactMatch(*prefix); got != tt.want {
+				t.Errorf("routeIndex.exactMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTgwRouteTable_BuildRouteIndex(t *testing.T) {
+	rt := &TgwRouteTable{
+		ID:     "tgw-rtb-123456789",
+		Routes: []types.TransitGatewayRoute{{DestinationCidrBlock: aws.String("123")}},
+	}
+	if err := rt.BuildRouteIndex(); err == nil {
+		t.Errorf("TgwRouteTable.BuildRouteIndex() expected an error for an invalid CIDR")
+	}
+	rt.Routes = listSearchTransitGatewayRoutesOutput.Routes
+	if err := rt.BuildRouteIndex(); err != nil {
+		t.Fatalf("TgwRouteTable.BuildRouteIndex() error = %v", err)
+	}
+	// Replacing the routes invalidates the index.
+	rt.Routes = []types.TransitGatewayRoute{{DestinationCidrBlock: aws.String("10.0.2.0/24")}}
+	got, err := rt.BestRouteToIP(net.ParseIP("10.0.2.1"))
+	if err != nil {
+		t.Fatalf("TgwRouteTable.BestRouteToIP() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, rt.Routes[0]) {
+		t.Errorf("TgwRouteTable.BestRouteToIP() = %v, want %v", got, rt.Routes[0])
+	}
+}
+
+func TestBestRouteToIPMatchesLinear(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	rt := TgwRouteTable{Routes: randomRoutes(r, 500)}
+	if err := rt.BuildRouteIndex(); err != nil {
+		t.Fatalf("TgwRouteTable.BuildRouteIndex() error = %v", err)
+	}
+	for _, ip := range randomIPs(r, 1000) {
+		want, _ := bestRouteToIPLinear(rt, ip)
+		got, err := rt.BestRouteToIP(ip)
+		if err != nil {
+			t.Fatalf("TgwRouteTable.BestRouteToIP() error = %v", err)
+		}
+		if *got.DestinationCidrBlock != *want.DestinationCidrBlock {
+			t.Errorf("TgwRouteTable.BestRouteToIP(%v) = %v, want %v", ip, *got.DestinationCidrBlock, *want.DestinationCidrBlock)
+		}
+	}
+}
+
+func benchmarkRouteTable(b *testing.B, n int) (TgwRouteTable, []net.IP) {
+	r := rand.New(rand.NewSource(1))
+	rt := TgwRouteTable{Routes: randomRoutes(r, n)}
+	if err := rt.BuildRouteIndex(); err != nil {
+		b.Fatal(err)
+	}
+	return rt, randomIPs(r, 1024)
+}
+
+func BenchmarkBestRouteToIP(b *testing.B) {
+	for _, n := range []int{100, 1000, 5000} {
+		rt, ips := benchmarkRouteTable(b, n)
+		b.Run(fmt.Sprintf("Index/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				rt.BestRouteToIP(ips[i%len(ips)])
+			}
+		})
+		b.Run(fmt.Sprintf("Linear/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				bestRouteToIPLinear(rt, ips[i%len(ips)])
+			}
+		})
+	}
+}
+
+func BenchmarkFindBestRoutePrefix(b *testing.B) {
+	var rts []*TgwRouteTable
+	for i := 0; i < 10; i++ {
+		rt, _ := benchmarkRouteTable(b, 1000)
+		rts = append(rts, &rt)
+	}
+	ips := randomIPs(rand.New(rand.NewSource(2)), 1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		findBestRoutePrefix(rts, ips[i%len(ips)])
+	}
+}
@@ -5,21 +5,32 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"os"
+	"strings"
 
 	"github.com/alexeyco/simpletable"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/fatih/color"
 )
 
 // TgwRouteTable holds the Route Table ID, a list of routes and other RouteTable info.
 // Represents a Route Table of a Transit Gateway in AWS.
+// After changing Routes call BuildRouteIndex, the lookups use the index of the routes.
 type TgwRouteTable struct {
 	ID          string
 	Name        string
 	Data        types.TransitGatewayRouteTable
 	Routes      []types.TransitGatewayRoute
 	Attachments []*TgwAttachment
+
+	// Propagations are the attachments with an enabled propagation to the route table.
+	// It is nil when the propagations were not collected, like in a snapshot of an older version.
+	Propagations []*TgwAttachment
+
+	// index is the prefix trie of Routes, built by BuildRouteIndex.
+	index *routeIndex
 }
 
 // Bytes returns the JSON representation of the TgwRouteTable as a slice of bytes.
@@ -28,38 +39,47 @@ func (t *TgwRouteTable) Bytes() []byte {
 	return b
 }
 
+// BuildRouteIndex builds the prefix trie used by the lookup functions from the current Routes.
+// It must be called after any change to Routes, also after changing a route in place like Routes[i] = route:
+// only a new slice or a new length are detected, and then the lookups build a temporary index on each call.
+func (t *TgwRouteTable) BuildRouteIndex() error {
+	idx, err := newRouteIndex(t.Routes)
+	if err != nil {
+		return fmt.Errorf("error building the index for route table %s: %w", t.ID, err)
+	}
+	t.index = idx
+	return nil
+}
+
+// lookupIndex returns the prefix trie for the routes in the TgwRouteTable.
+// If the stored index is missing or was built from a different Routes slice a new one is built, but not stored.
+func (t TgwRouteTable) lookupIndex() (*routeIndex, error) {
+	if t.index.validFor(t.Routes) {
+		return t.index, nil
+	}
+	return newRouteIndex(t.Routes)
+}
+
 // BestRouteToIP returns the best route to a given IP address for a given TgwRouteTable.
 // Only one route can be the best route, and is returned.
 // If no route is found, the function returns the empty TransitGatewayRoute.
 func (t TgwRouteTable) BestRouteToIP(ipAddress net.IP) (types.TransitGatewayRoute, error) {
-	// mask is the subnet mask
-	var mask net.IPMask
+	route, _, err := t.bestRouteToIP(ipAddress)
+	return route, err
+}
 
-	// result is the route table with the longest prefix match or the higher mask.
-	result := types.TransitGatewayRoute{}
-	for _, route := range t.Routes {
-		_, subnet, err := net.ParseCIDR(*route.DestinationCidrBlock)
-		if err != nil {
-			return types.TransitGatewayRoute{}, fmt.Errorf("error parsing the CIDR %w", err)
-		}
-		if subnet.Contains(ipAddress) {
-			// currentMask is the mask for the current route.
-			currentMask := subnet.Mask
-			// currentMaskSize is the mask length for the current route.
-			currentMaskSize, _ := currentMask.Size()
-			if mask == nil {
-				mask = currentMask
-				result = route
-			} else {
-				maskSize, _ := mask.Size()
-				if currentMaskSize > maskSize {
-					mask = currentMask
-					result = route
-				}
-			}
-		}
+// bestRouteToIP is like BestRouteToIP but it also returns the parsed prefix of the route.
+// The prefix is nil if no route is found.
+func (t TgwRouteTable) bestRouteToIP(ipAddress net.IP) (types.TransitGatewayRoute, *net.IPNet, error) {
+	idx, err := t.lookupIndex()
+	if err != nil {
+		return types.TransitGatewayRoute{}, nil, err
 	}
-	return result, nil
+	i, prefix := idx.longestMatch(ipAddress)
+	if i == -1 {
+		return types.TransitGatewayRoute{}, nil, nil
+	}
+	return t.Routes[i], prefix, nil
 }
 
 // newTgwRouteTable creates a TgwRouteTable from an AWS TGW Route Table.
@@ -86,11 +106,8 @@ func newTgwRouteTable(t types.TransitGatewayRouteTable) *TgwRouteTable {
 
 // Update the attachments of a TgwRouteTable.
 func (t *TgwRouteTable) UpdateAttachments(ctx context.Context, attachments *ec2.GetTransitGatewayRouteTableAssociationsOutput) error {
-	// get the attachments for the route table
-	if len(attachments.Associations) < 1 {
-		t.Attachments = []*TgwAttachment{}
-		return nil
-	}
+	// get the attachments for the route table, replacing the ones of a previous update
+	t.Attachments = make([]*TgwAttachment, 0, len(attachments.Associations))
 	for _, a := range attachments.Associations {
 		attType := fmt.Sprint(a.ResourceType)
 		newAttachment := &TgwAttachment{
@@ -103,6 +120,38 @@ func (t *TgwRouteTable) UpdateAttachments(ctx context.Context, attachments *ec2.
 	return nil
 }
 
+// UpdatePropagations updates the attachments that propagate routes to the TgwRouteTable.
+// Only the enabled propagations are kept, a propagation being enabled or disabled has no routes yet.
+func (t *TgwRouteTable) UpdatePropagations(propagations *ec2.GetTransitGatewayRouteTablePropagationsOutput) {
+	t.Propagations = []*TgwAttachment{}
+	if propagations == nil {
+		return
+	}
+	for _, p := range propagations.TransitGatewayRouteTablePropagations {
+		if p.State != types.TransitGatewayPropagationStateEnabled || p.TransitGatewayAttachmentId == nil {
+			continue
+		}
+		t.Propagations = append(t.Propagations, &TgwAttachment{
+			ID:         *p.TransitGatewayAttachmentId,
+			ResourceID: aws.StringValue(p.ResourceId),
+			Type:       fmt.Sprint(p.ResourceType),
+		})
+	}
+}
+
+// HasPropagation reports if the attachment propagates to the TgwRouteTable, and if the propagations are known.
+func (t *TgwRouteTable) HasPropagation(attachmentID string) (propagates bool, known bool) {
+	if t.Propagations == nil {
+		return false, false
+	}
+	for _, att := range t.Propagations {
+		if att.ID == attachmentID {
+			return true, true
+		}
+	}
+	return false, true
+}
+
 // TgwRouteTableSelectionPriority select the best route table from a list of TgwRouteTables to the specific destination.
 func TgwRouteTableSelectionPriority(rts []*TgwRouteTable, src net.IP) (*TgwRouteTable, error) {
 	var srcAttachment *TgwAttachment
@@ -125,12 +174,12 @@ func TgwRouteTableSelectionPriority(rts []*TgwRouteTable, src net.IP) (*TgwRoute
 			srcAttachment = getAttachmentsFromTgwRoute(r)[0]
 			break
 		case types.TransitGatewayRouteTypeStatic:
-			fmt.Println("Not implemented")
+			fmt.Fprintln(os.Stderr, "Not implemented")
 			// find if the attachment is associated with the same route table
 			// where the route is.
 		}
 	}
-	fmt.Println("Attachment is: ", srcAttachment.ResourceID)
+	fmt.Fprintln(os.Stderr, "Attachment is: ", srcAttachment.ResourceID)
 	return nil, nil
 }
 
@@ -140,18 +189,14 @@ func findBestRoutePrefix(rts []*TgwRouteTable, ipAddr net.IP) (net.IPNet, error)
 	var brp net.IPNet
 
 	for _, rt := range rts {
-		// r is the best route to an IP address
-		r, err := rt.BestRouteToIP(ipAddr)
+		// currentSubnet is the prefix of the best route to an IP address
+		_, currentSubnet, err := rt.bestRouteToIP(ipAddr)
 		if err != nil {
-			return net.IPNet{}, err
+			return net.IPNet{}, fmt.Errorf("error parsing the CIDR for %v. %w", rt.Data, err)
 		}
-		if r.DestinationCidrBlock == nil {
+		if currentSubnet == nil {
 			continue
 		}
-		_, currentSubnet, err := net.ParseCIDR(*r.DestinationCidrBlock)
-		if err != nil {
-			return net.IPNet{}, fmt.Errorf("error parsing the CIDR for %v. %w", rt.Data, err)
-		}
 		if brp.IP == nil {
 			brp = *currentSubnet
 		}
@@ -168,22 +213,21 @@ func findBestRoutePrefix(rts []*TgwRouteTable, ipAddr net.IP) (net.IPNet, error)
 func FilterRouteTableRoutesPerPrefix(rts []*TgwRouteTable, prefix net.IPNet) ([]TgwRouteTable, error) {
 	var result []TgwRouteTable
 	for _, rt := range rts {
-		for _, r := range rt.Routes {
-			_, currentSubnet, err := net.ParseCIDR(*r.DestinationCidrBlock)
-			if err != nil {
-				return nil, fmt.Errorf("error parsing the CIDR for %v. %w", rt.Data, err)
-			}
-			currentSubnetMask := currentSubnet.Mask.String()
-			if currentSubnet.IP.Equal(prefix.IP) && prefix.Mask.String() == currentSubnetMask {
-				// Create a new TgwRouteTable from the current route table.
-				newRt := TgwRouteTable{
-					ID:     rt.ID,
-					Name:   rt.Name,
-					Routes: []types.TransitGatewayRoute{r},
-				}
-				result = append(result, newRt)
-			}
+		idx, err := rt.lookupIndex()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the CIDR for %v. %w", rt.Data, err)
 		}
+		i := idx.exactMatch(prefix)
+		if i == -1 {
+			continue
+		}
+		// Create a new TgwRouteTable from the current route table.
+		newRt := TgwRouteTable{
+			ID:     rt.ID,
+			Name:   rt.Name,
+			Routes: []types.TransitGatewayRoute{rt.Routes[i]},
+		}
+		result = append(result, newRt)
 	}
 	return result, nil
 }
@@ -244,6 +288,17 @@ func (t *TgwRouteTable) PrintRoutesInTable() {
 		},
 	}
 	fmt.Println(table.String())
+	if t.Propagations != nil {
+		names := make([]string, 0, len(t.Propagations))
+		for _, att := range t.Propagations {
+			name := att.Name
+			if name == "" {
+				name = att.ID
+			}
+			names = append(names, name)
+		}
+		fmt.Printf("Propagations: %s\n", noneIfEmpty(strings.Join(names, ", ")))
+	}
 }
 
 // GetAttachmentName returns the name of the attachment that has the given ID.
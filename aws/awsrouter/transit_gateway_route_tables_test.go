@@ -406,6 +406,13 @@ func TestTgwRouteTable_UpdateAttachments(t *testing.T) {
 			if len(tr.Attachments) < 1 {
 				t.Errorf("Number of attachment is less than 1 %v, wantErr %v", len(tr.Attachments), tt.wantErr)
 			}
+			// A second update replaces the attachments instead of adding them again.
+			if err := tr.UpdateAttachments(tt.args.ctx, tt.args.api); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := len(tr.Attachments), len(tt.args.api.Associations); got != want {
+				t.Errorf("Number of attachments after a second update = %d, want %d", got, want)
+			}
 		})
 	}
 }
@@ -0,0 +1,250 @@
+package awsrouter
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// RoutesPerTgwQuota is the default AWS quota of routes, static and propagated, across all the route tables of a Transit Gateway.
+const RoutesPerTgwQuota = 10000
+
+// RouteSummary is a prefix that can replace static routes with the same next hops without changing the forwarding.
+// Routes are the destinations of the routes replaced, Prefix is one of them when the others are inside it.
+type RouteSummary struct {
+	RouteTableID   string
+	RouteTableName string
+	Prefix         string
+	Attachments    []string
+	Routes         []string
+}
+
+// Saved returns the number of routes removed by the summary.
+func (s RouteSummary) Saved() int {
+	return len(s.Routes) - 1
+}
+
+// SummarizeStaticRoutes returns the summaries of the static routes of all the route tables of the Tgw.
+func (t *Tgw) SummarizeStaticRoutes() ([]RouteSummary, error) {
+	var result []RouteSummary
+	for _, rt := range t.RouteTables {
+		summaries, err := rt.SummarizeStaticRoutes()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, summaries...)
+	}
+	return result, nil
+}
+
+// RouteCount returns the number of routes in all the route tables of the Tgw, the ones counted by RoutesPerTgwQuota.
+// A route to a prefix list counts the maximum entries of the prefix list.
+func (t *Tgw) RouteCount() int {
+	n := 0
+	for _, rt := range t.RouteTables {
+		n += t.routeEntries(rt)
+	}
+	return n
+}
+
+// SummarizeStaticRoutes finds the active static routes of the TgwRouteTable with the same next hops
+// that can be aggregated in fewer prefixes. A summary is only proposed when every address keeps its route:
+// the summary is exactly the union of the routes replaced, it is not the destination of another route,
+// and no route to other next hops is more specific than the summary but less specific than a route replaced,
+// because it would capture the traffic of that route. Routes to a prefix list are never summarized and,
+// as their prefixes are unknown, they are not checked.
+// The summaries are sorted by prefix, IPv4 first.
+func (t *TgwRouteTable) SummarizeStaticRoutes() ([]RouteSummary, error) {
+	groups := make(map[string][]net.IPNet)
+	for _, route := range t.Routes {
+		if route.DestinationCidrBlock == nil {
+			continue
+		}
+		_, prefix, err := net.ParseCIDR(*route.DestinationCidrBlock)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the CIDR %s of route table %s: %w", *route.DestinationCidrBlock, t.ID, err)
+		}
+		key := summaryKey(route)
+		groups[key] = append(groups[key], *prefix)
+	}
+
+	var result []RouteSummary
+	for key, prefixes := range groups {
+		if key == "" {
+			continue
+		}
+		var s summarizer
+		for other, otherPrefixes := range groups {
+			if other != key {
+				s.others = append(s.others, otherPrefixes...)
+			}
+		}
+		for _, root := range []net.IPNet{{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)}, {IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)}} {
+			cover := s.cover(root, s.inside(root, prefixes))
+			for _, summary := range cover {
+				replaced := replacedBy(summary, cover, prefixes)
+				if len(replaced) < 2 {
+					continue
+				}
+				sort.Slice(replaced, func(i, j int) bool { return lessPrefix(replaced[i], replaced[j]) })
+				routes := make([]string, 0, len(replaced))
+				for _, p := range replaced {
+					routes = append(routes, p.String())
+				}
+				result = append(result, RouteSummary{
+					RouteTableID:   t.ID,
+					RouteTableName: t.Name,
+					Prefix:         summary.String(),
+					Attachments:    strings.Split(key, ","),
+					Routes:         routes,
+				})
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Prefix != result[j].Prefix {
+			_, a, _ := net.ParseCIDR(result[i].Prefix)
+			_, b, _ := net.ParseCIDR(result[j].Prefix)
+			return lessPrefix(*a, *b)
+		}
+		return strings.Join(result[i].Attachments, ",") < strings.Join(result[j].Attachments, ",")
+	})
+	return result, nil
+}
+
+// summaryKey returns the sorted next hops of an active static route, or empty if the route can not be summarized.
+// The routes with the same key are summarized together.
+func summaryKey(route types.TransitGatewayRoute) string {
+	if route.Type != types.TransitGatewayRouteTypeStatic || route.State != types.TransitGatewayRouteStateActive {
+		return ""
+	}
+	var ids []string
+	for _, att := range route.TransitGatewayAttachments {
+		ids = append(ids, aws.StringValue(att.TransitGatewayAttachmentId))
+	}
+	return strings.Join(sortedUnique(ids), ",")
+}
+
+// summarizer aggregates the prefixes of a group of routes, others are the destinations of the rest of the routes of the route table.
+type summarizer struct {
+	others []net.IPNet
+}
+
+// cover returns the fewest prefixes inside n that route the addresses of prefixes like they are routed now.
+// prefixes are the prefixes of the group inside n.
+func (s summarizer) cover(n net.IPNet, prefixes []net.IPNet) []net.IPNet {
+	if len(prefixes) == 0 {
+		return nil
+	}
+	for _, p := range prefixes {
+		if !equalPrefix(p, n) {
+			continue
+		}
+		if s.safe(n, prefixes) {
+			return []net.IPNet{n}
+		}
+		// The routes inside n are needed, a route to other next hops between them and n would capture their traffic.
+		var rest []net.IPNet
+		for _, q := range prefixes {
+			if !equalPrefix(q, n) {
+				rest = append(rest, q)
+			}
+		}
+		if len(rest) == 0 {
+			return []net.IPNet{n}
+		}
+		left, right := halves(n)
+		return append(append([]net.IPNet{n}, s.cover(left, s.inside(left, rest))...), s.cover(right, s.inside(right, rest))...)
+	}
+	left, right := halves(n)
+	l := s.cover(left, s.inside(left, prefixes))
+	r := s.cover(right, s.inside(right, prefixes))
+	if len(l) == 1 && len(r) == 1 && equalPrefix(l[0], left) && equalPrefix(r[0], right) && s.safe(n, prefixes) {
+		return []net.IPNet{n}
+	}
+	return append(l, r...)
+}
+
+// safe reports if n can replace prefixes: no other route is inside n and contains one of the prefixes.
+func (s summarizer) safe(n net.IPNet, prefixes []net.IPNet) bool {
+	for _, o := range s.others {
+		if !containsPrefix(n, o) {
+			continue
+		}
+		for _, p := range prefixes {
+			if containsPrefix(o, p) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// inside returns the prefixes contained in n.
+func (s summarizer) inside(n net.IPNet, prefixes []net.IPNet) []net.IPNet {
+	var result []net.IPNet
+	for _, p := range prefixes {
+		if containsPrefix(n, p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// replacedBy returns the prefixes replaced by summary, the ones inside it that are not inside a more specific prefix of cover.
+func replacedBy(summary net.IPNet, cover, prefixes []net.IPNet) []net.IPNet {
+	var result []net.IPNet
+next:
+	for _, p := range prefixes {
+		if !containsPrefix(summary, p) {
+			continue
+		}
+		for _, c := range cover {
+			if !equalPrefix(c, summary) && containsPrefix(summary, c) && containsPrefix(c, p) {
+				continue next
+			}
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// halves returns the two prefixes one bit longer than n, n must not be a host prefix.
+func halves(n net.IPNet) (net.IPNet, net.IPNet) {
+	ones, bits := n.Mask.Size()
+	mask := net.CIDRMask(ones+1, bits)
+	left := net.IPNet{IP: n.IP.Mask(mask), Mask: mask}
+	ip := make(net.IP, len(left.IP))
+	copy(ip, left.IP)
+	ip[ones/8] |= 0x80 >> (ones % 8)
+	return left, net.IPNet{IP: ip, Mask: mask}
+}
+
+// containsPrefix reports if a contains all the addresses of b.
+func containsPrefix(a, b net.IPNet) bool {
+	aOnes, aBits := a.Mask.Size()
+	bOnes, bBits := b.Mask.Size()
+	return aBits == bBits && aOnes <= bOnes && a.Contains(b.IP)
+}
+
+// lessPrefix orders the prefixes by address and then by length, IPv4 first.
+func lessPrefix(a, b net.IPNet) bool {
+	if len(a.IP) != len(b.IP) {
+		return len(a.IP) < len(b.IP)
+	}
+	if c := bytes.Compare(a.IP, b.IP); c != 0 {
+		return c < 0
+	}
+	aOnes, _ := a.Mask.Size()
+	bOnes, _ := b.Mask.Size()
+	return aOnes < bOnes
+}
+
+func equalPrefix(a, b net.IPNet) bool {
+	return containsPrefix(a, b) && containsPrefix(b, a)
+}
@@ -0,0 +1,124 @@
+package awsrouter
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+// staticRoute returns an active static route to cidr through the attachments.
+func staticRoute(cidr string, atts ...string) types.TransitGatewayRoute {
+	r := diffRoute(cidr, "active", atts...)
+	r.Type = types.TransitGatewayRouteTypeStatic
+	return r
+}
+
+func TestTgwRouteTable_SummarizeStaticRoutes(t *testing.T) {
+	blackhole := staticRoute("10.0.1.0/24")
+	blackhole.State = types.TransitGatewayRouteStateBlackhole
+	tests := []struct {
+		name   string
+		routes []types.TransitGatewayRoute
+		want   []RouteSummary
+	}{
+		{
+			name: "Siblings",
+			routes: []types.TransitGatewayRoute{
+				staticRoute("10.0.0.0/24", "tgw-attach-0a"),
+				staticRoute("10.0.1.0/24", "tgw-attach-0a"),
+				staticRoute("10.0.2.0/24", "tgw-attach-0a"),
+				staticRoute("10.0.3.0/24", "tgw-attach-0a"),
+				staticRoute("10.0.4.0/24", "tgw-attach-0a"),
+			},
+			want: []RouteSummary{
+				{Prefix: "10.0.0.0/22", Attachments: []string{"tgw-attach-0a"}, Routes: []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"}},
+			},
+		},
+		{
+			name: "Not Contiguous",
+			routes: []types.TransitGatewayRoute{
+				staticRoute("10.0.0.0/24", "tgw-attach-0a"),
+				staticRoute("10.0.2.0/24", "tgw-attach-0a"),
+			},
+		},
+		{
+			name: "Different Attachments",
+			routes: []types.TransitGatewayRoute{
+				staticRoute("10.0.0.0/24", "tgw-attach-0a"),
+				staticRoute("10.0.1.0/24", "tgw-attach-0b"),
+			},
+		},
+		{
+			name: "Contained",
+			routes: []types.TransitGatewayRoute{
+				staticRoute("10.0.0.0/16", "tgw-attach-0a"),
+				staticRoute("10.0.5.0/24", "tgw-attach-0a"),
+			},
+			want: []RouteSummary{
+				{Prefix: "10.0.0.0/16", Attachments: []string{"tgw-attach-0a"}, Routes: []string{"10.0.0.0/16", "10.0.5.0/24"}},
+			},
+		},
+		{
+			name: "Capture",
+			routes: []types.TransitGatewayRoute{
+				staticRoute("10.0.0.0/16", "tgw-attach-0a"),
+				staticRoute("10.0.5.0/24", "tgw-attach-0a"),
+				staticRoute("10.0.4.0/22", "tgw-attach-0b"),
+			},
+		},
+		{
+			name: "More Specific Route Kept",
+			routes: []types.TransitGatewayRoute{
+				staticRoute("10.0.0.0/24", "tgw-attach-0a"),
+				staticRoute("10.0.1.0/24", "tgw-attach-0a"),
+				staticRoute("10.0.1.128/25", "tgw-attach-0b"),
+			},
+			want: []RouteSummary{
+				{Prefix: "10.0.0.0/23", Attachments: []string{"tgw-attach-0a"}, Routes: []string{"10.0.0.0/24", "10.0.1.0/24"}},
+			},
+		},
+		{
+			name: "Summary Is Another Route",
+			routes: []types.TransitGatewayRoute{
+				staticRoute("10.0.0.0/24", "tgw-attach-0a"),
+				staticRoute("10.0.1.0/24", "tgw-attach-0a"),
+				diffRoute("10.0.0.0/23", "active", "tgw-attach-0c"),
+			},
+		},
+		{
+			name: "Blackhole And Propagated",
+			routes: []types.TransitGatewayRoute{
+				staticRoute("10.0.0.0/24", "tgw-attach-0a"),
+				blackhole,
+				diffRoute("10.1.0.0/24", "active", "tgw-attach-0a"),
+				diffRoute("10.1.1.0/24", "active", "tgw-attach-0a"),
+			},
+		},
+		{
+			name: "ECMP And IPv6",
+			routes: []types.TransitGatewayRoute{
+				staticRoute("10.0.0.0/24", "tgw-attach-0b", "tgw-attach-0a"),
+				staticRoute("10.0.1.0/24", "tgw-attach-0a", "tgw-attach-0b"),
+				staticRoute("2001:db8::/33", "tgw-attach-0a"),
+				staticRoute("2001:db8:8000::/33", "tgw-attach-0a"),
+			},
+			want: []RouteSummary{
+				{Prefix: "10.0.0.0/23", Attachments: []string{"tgw-attach-0a", "tgw-attach-0b"}, Routes: []string{"10.0.0.0/24", "10.0.1.0/24"}},
+				{Prefix: "2001:db8::/32", Attachments: []string{"tgw-attach-0a"}, Routes: []string{"2001:db8::/33", "2001:db8:8000::/33"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &TgwRouteTable{Routes: tt.routes}
+			got, err := rt.SummarizeStaticRoutes()
+			if err != nil {
+				t.Fatalf("SummarizeStaticRoutes() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("SummarizeStaticRoutes() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
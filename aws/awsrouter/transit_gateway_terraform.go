@@ -0,0 +1,233 @@
+package awsrouter
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// ExportTgwTerraform writes the HCL of the route tables of the Tgw with an import block for each resource:
+// aws_ec2_transit_gateway_route_table, aws_ec2_transit_gateway_route for the static routes,
+// aws_ec2_transit_gateway_prefix_list_reference for the static routes to a prefix list,
+// aws_ec2_transit_gateway_route_table_association and aws_ec2_transit_gateway_route_table_propagation.
+// Propagated routes are not written, they are created by the propagations. A blackhole static route has blackhole = true.
+// The resource names start with prefix, see TerraformPrefix, so the files of several Tgws can be in the same module.
+// When the propagations of a route table are unknown they are inferred from its propagated routes.
+func ExportTgwTerraform(w io.Writer, tgw *Tgw, prefix string) error {
+	tf := &terraformWriter{w: w, names: make(map[string]bool)}
+	tf.comment(fmt.Sprintf("Transit Gateway %s (%s).", tgw.Name, tgw.ID))
+	tf.comment("Propagated routes are not exported, they are created by the propagations.")
+	for _, rt := range tgw.RouteTables {
+		rtName := tf.resource("aws_ec2_transit_gateway_route_table", []string{prefix, rt.Name}, rt.ID, []terraformAttribute{
+			{"transit_gateway_id", hclString(tgw.ID)},
+		}, rt.Name)
+		rtRef := "aws_ec2_transit_gateway_route_table." + rtName + ".id"
+
+		for _, route := range staticRoutes(rt) {
+			attrs := []terraformAttribute{}
+			resourceType := "aws_ec2_transit_gateway_route"
+			destination := routeDestination(route)
+			if route.PrefixListId != nil {
+				resourceType = "aws_ec2_transit_gateway_prefix_list_reference"
+				attrs = append(attrs, terraformAttribute{"prefix_list_id", hclString(destination)})
+			} else {
+				attrs = append(attrs, terraformAttribute{"destination_cidr_block", hclString(destination)})
+			}
+			if route.State == types.TransitGatewayRouteStateBlackhole || len(route.TransitGatewayAttachments) == 0 {
+				attrs = append(attrs, terraformAttribute{"blackhole", "true"})
+			} else {
+				// A static route has one attachment, only propagated routes can be ECMP.
+				attrs = append(attrs, terraformAttribute{"transit_gateway_attachment_id", hclString(aws.StringValue(route.TransitGatewayAttachments[0].TransitGatewayAttachmentId))})
+			}
+			attrs = append(attrs, terraformAttribute{"transit_gateway_route_table_id", rtRef})
+			tf.resource(resourceType, []string{prefix, rt.Name, destination}, rt.ID+"_"+destination, attrs, "")
+		}
+
+		for _, att := range rt.Attachments {
+			tf.resource("aws_ec2_transit_gateway_route_table_association", []string{prefix, rt.Name, attachmentLabel(att)}, rt.ID+"_"+att.ID, []terraformAttribute{
+				{"transit_gateway_attachment_id", hclString(att.ID)},
+				{"transit_gateway_route_table_id", rtRef},
+			}, "")
+		}
+
+		propagations, inferred := tgw.routeTablePropagations(rt)
+		if inferred && len(propagations) > 0 {
+			tf.printf("\n")
+			tf.comment(fmt.Sprintf("The propagations of %s are inferred from its propagated routes.", rt.Name))
+		}
+		for _, att := range propagations {
+			tf.resource("aws_ec2_transit_gateway_route_table_propagation", []string{prefix, rt.Name, attachmentLabel(att)}, rt.ID+"_"+att.ID, []terraformAttribute{
+				{"transit_gateway_attachment_id", hclString(att.ID)},
+				{"transit_gateway_route_table_id", rtRef},
+			}, "")
+		}
+	}
+	return tf.err
+}
+
+// TerraformPrefix returns the prefix of the resource names of the Tgw when it is exported with tgws to the same module.
+// It is the name of the Tgw, followed by its ID if the name is empty or another Tgw of tgws has the same resource name.
+func TerraformPrefix(tgw *Tgw, tgws []*Tgw) string {
+	if tgw.Name == "" {
+		return tgw.ID
+	}
+	name := terraformName([]string{tgw.Name})
+	for _, other := range tgws {
+		if other != tgw && terraformName([]string{other.Name}) == name {
+			return tgw.Name + "_" + tgw.ID
+		}
+	}
+	return tgw.Name
+}
+
+// staticRoutes returns the active and blackhole static routes of rt, sorted by destination.
+func staticRoutes(rt *TgwRouteTable) []types.TransitGatewayRoute {
+	var routes []types.TransitGatewayRoute
+	for _, route := range rt.Routes {
+		if route.Type != types.TransitGatewayRouteTypeStatic {
+			continue
+		}
+		if route.State != types.TransitGatewayRouteStateActive && route.State != types.TransitGatewayRouteStateBlackhole {
+			continue
+		}
+		routes = append(routes, route)
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return lessDestination(routeDestination(routes[i]), routeDestination(routes[j]))
+	})
+	return routes
+}
+
+// routeDestination returns the CIDR of the route or, for a route to a prefix list, the ID of the prefix list.
+func routeDestination(route types.TransitGatewayRoute) string {
+	if route.PrefixListId != nil {
+		return *route.PrefixListId
+	}
+	return aws.StringValue(route.DestinationCidrBlock)
+}
+
+// lessDestination orders the CIDRs with lessPrefix and then the other destinations, like prefix lists, as strings.
+func lessDestination(a, b string) bool {
+	_, pa, errA := net.ParseCIDR(a)
+	_, pb, errB := net.ParseCIDR(b)
+	switch {
+	case errA == nil && errB == nil:
+		return lessPrefix(*pa, *pb)
+	case errA == nil || errB == nil:
+		return errA == nil
+	}
+	return a < b
+}
+
+// routeTablePropagations returns the attachments that propagate to rt sorted by ID, and if they were inferred
+// from the propagated routes because the Propagations of rt are unknown.
+func (t *Tgw) routeTablePropagations(rt *TgwRouteTable) ([]*TgwAttachment, bool) {
+	var result []*TgwAttachment
+	if rt.Propagations != nil {
+		result = append(result, rt.Propagations...)
+	} else {
+		for _, att := range t.Attachments() {
+			for _, route := range rt.Routes {
+				if route.Type == types.TransitGatewayRouteTypePropagated && routeUses(route, att.ID) {
+					result = append(result, att)
+					break
+				}
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, rt.Propagations == nil
+}
+
+// attachmentLabel returns the name of the attachment, or its ID if it has no name.
+func attachmentLabel(att *TgwAttachment) string {
+	if att.Name != "" {
+		return att.Name
+	}
+	return att.ID
+}
+
+// terraformAttribute is an attribute of a resource, value is already HCL.
+type terraformAttribute struct {
+	key   string
+	value string
+}
+
+// terraformWriter writes the resources, it keeps the first error and the names used so they are unique.
+type terraformWriter struct {
+	w     io.Writer
+	names map[string]bool
+	err   error
+}
+
+func (tf *terraformWriter) printf(format string, a ...interface{}) {
+	if tf.err != nil {
+		return
+	}
+	_, tf.err = fmt.Fprintf(tf.w, format, a...)
+}
+
+func (tf *terraformWriter) comment(text string) {
+	tf.printf("# %s\n", text)
+}
+
+// resource writes a resource of resourceType named after parts, with its import block of the ID id.
+// The = of the attributes are aligned like terraform fmt does. A non empty nameTag is written as the Name tag.
+// It returns the name of the resource.
+func (tf *terraformWriter) resource(resourceType string, parts []string, id string, attrs []terraformAttribute, nameTag string) string {
+	name := tf.uniqueName(resourceType, parts)
+	width := 0
+	for _, attr := range attrs {
+		if len(attr.key) > width {
+			width = len(attr.key)
+		}
+	}
+	tf.printf("\nresource %q %q {\n", resourceType, name)
+	for _, attr := range attrs {
+		tf.printf("  %-*s = %s\n", width, attr.key, attr.value)
+	}
+	if nameTag != "" {
+		tf.printf("\n  tags = {\n    Name = %s\n  }\n", hclString(nameTag))
+	}
+	tf.printf("}\n\nimport {\n  to = %s.%s\n  id = %s\n}\n", resourceType, name, hclString(id))
+	return name
+}
+
+// uniqueName returns a valid Terraform name from parts, not used before by a resource of the same type.
+func (tf *terraformWriter) uniqueName(resourceType string, parts []string) string {
+	name := terraformName(parts)
+	unique := name
+	for i := 2; tf.names[resourceType+"."+unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+	tf.names[resourceType+"."+unique] = true
+	return unique
+}
+
+// terraformName returns a valid Terraform name from parts joined by _, the invalid characters are replaced by _.
+func terraformName(parts []string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, strings.Join(parts, "_"))
+	if name == "" || (name[0] >= '0' && name[0] <= '9') || name[0] == '-' {
+		name = "_" + name
+	}
+	return name
+}
+
+// hclString returns s as a quoted HCL string, the template sequences are escaped.
+func hclString(s string) string {
+	s = strings.ReplaceAll(s, "${", "$${")
+	s = strings.ReplaceAll(s, "%{", "%%{")
+	return strconv.Quote(s)
+}
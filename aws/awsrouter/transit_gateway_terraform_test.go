@@ -0,0 +1,204 @@
+package awsrouter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExportTgwTerraform(t *testing.T) {
+	tgw := propagationTgw()
+	spokes := tgw.RouteTables[0]
+	blackhole := diffRoute("10.3.0.0/16", "blackhole")
+	blackhole.Type = types.TransitGatewayRouteTypeStatic
+	offices := diffRoute("", "active", "tgw-attach-0b")
+	offices.Type = types.TransitGatewayRouteTypeStatic
+	offices.DestinationCidrBlock = nil
+	offices.PrefixListId = aws.String("pl-0a")
+	deleted := diffRoute("10.4.0.0/16", "deleted", "tgw-attach-0b")
+	deleted.Type = types.TransitGatewayRouteTypeStatic
+	spokes.Routes = append(spokes.Routes, offices, blackhole, deleted)
+
+	var buf bytes.Buffer
+	if err := ExportTgwTerraform(&buf, tgw, tgw.Name); err != nil {
+		t.Fatalf("ExportTgwTerraform() error = %v", err)
+	}
+	want := `# Transit Gateway core (tgw-0a).
+# Propagated routes are not exported, they are created by the propagations.
+
+resource "aws_ec2_transit_gateway_route_table" "core_spokes" {
+  transit_gateway_id = "tgw-0a"
+
+  tags = {
+    Name = "spokes"
+  }
+}
+
+import {
+  to = aws_ec2_transit_gateway_route_table.core_spokes
+  id = "tgw-rtb-0a"
+}
+
+resource "aws_ec2_transit_gateway_route" "core_spokes_10_3_0_0_16" {
+  destination_cidr_block         = "10.3.0.0/16"
+  blackhole                      = true
+  transit_gateway_route_table_id = aws_ec2_transit_gateway_route_table.core_spokes.id
+}
+
+import {
+  to = aws_ec2_transit_gateway_route.core_spokes_10_3_0_0_16
+  id = "tgw-rtb-0a_10.3.0.0/16"
+}
+
+resource "aws_ec2_transit_gateway_route" "core_spokes_10_9_0_0_16" {
+  destination_cidr_block         = "10.9.0.0/16"
+  transit_gateway_attachment_id  = "tgw-attach-0s"
+  transit_gateway_route_table_id = aws_ec2_transit_gateway_route_table.core_spokes.id
+}
+
+import {
+  to = aws_ec2_transit_gateway_route.core_spokes_10_9_0_0_16
+  id = "tgw-rtb-0a_10.9.0.0/16"
+}
+
+resource "aws_ec2_transit_gateway_prefix_list_reference" "core_spokes_pl-0a" {
+  prefix_list_id                 = "pl-0a"
+  transit_gateway_attachment_id  = "tgw-attach-0b"
+  transit_gateway_route_table_id = aws_ec2_transit_gateway_route_table.core_spokes.id
+}
+
+import {
+  to = aws_ec2_transit_gateway_prefix_list_reference.core_spokes_pl-0a
+  id = "tgw-rtb-0a_pl-0a"
+}
+
+resource "aws_ec2_transit_gateway_route_table_association" "core_spokes_vpc-a" {
+  transit_gateway_attachment_id  = "tgw-attach-0a"
+  transit_gateway_route_table_id = aws_ec2_transit_gateway_route_table.core_spokes.id
+}
+
+import {
+  to = aws_ec2_transit_gateway_route_table_association.core_spokes_vpc-a
+  id = "tgw-rtb-0a_tgw-attach-0a"
+}
+
+resource "aws_ec2_transit_gateway_route_table_association" "core_spokes_vpc-b" {
+  transit_gateway_attachment_id  = "tgw-attach-0b"
+  transit_gateway_route_table_id = aws_ec2_transit_gateway_route_table.core_spokes.id
+}
+
+import {
+  to = aws_ec2_transit_gateway_route_table_association.core_spokes_vpc-b
+  id = "tgw-rtb-0a_tgw-attach-0b"
+}
+
+resource "aws_ec2_transit_gateway_route_table_propagation" "core_spokes_vpc-a" {
+  transit_gateway_attachment_id  = "tgw-attach-0a"
+  transit_gateway_route_table_id = aws_ec2_transit_gateway_route_table.core_spokes.id
+}
+
+import {
+  to = aws_ec2_transit_gateway_route_table_propagation.core_spokes_vpc-a
+  id = "tgw-rtb-0a_tgw-attach-0a"
+}
+
+resource "aws_ec2_transit_gateway_route_table" "core_shared" {
+  transit_gateway_id = "tgw-0a"
+
+  tags = {
+    Name = "shared"
+  }
+}
+
+import {
+  to = aws_ec2_transit_gateway_route_table.core_shared
+  id = "tgw-rtb-0s"
+}
+
+resource "aws_ec2_transit_gateway_route_table_association" "core_shared_vpc-s" {
+  transit_gateway_attachment_id  = "tgw-attach-0s"
+  transit_gateway_route_table_id = aws_ec2_transit_gateway_route_table.core_shared.id
+}
+
+import {
+  to = aws_ec2_transit_gateway_route_table_association.core_shared_vpc-s
+  id = "tgw-rtb-0s_tgw-attach-0s"
+}
+
+# The propagations of shared are inferred from its propagated routes.
+
+resource "aws_ec2_transit_gateway_route_table_propagation" "core_shared_vpc-a" {
+  transit_gateway_attachment_id  = "tgw-attach-0a"
+  transit_gateway_route_table_id = aws_ec2_transit_gateway_route_table.core_shared.id
+}
+
+import {
+  to = aws_ec2_transit_gateway_route_table_propagation.core_shared_vpc-a
+  id = "tgw-rtb-0s_tgw-attach-0a"
+}
+
+resource "aws_ec2_transit_gateway_route_table_propagation" "core_shared_vpc-b" {
+  transit_gateway_attachment_id  = "tgw-attach-0b"
+  transit_gateway_route_table_id = aws_ec2_transit_gateway_route_table.core_shared.id
+}
+
+import {
+  to = aws_ec2_transit_gateway_route_table_propagation.core_shared_vpc-b
+  id = "tgw-rtb-0s_tgw-attach-0b"
+}
+
+resource "aws_ec2_transit_gateway_route_table_propagation" "core_shared_vpc-s" {
+  transit_gateway_attachment_id  = "tgw-attach-0s"
+  transit_gateway_route_table_id = aws_ec2_transit_gateway_route_table.core_shared.id
+}
+
+import {
+  to = aws_ec2_transit_gateway_route_table_propagation.core_shared_vpc-s
+  id = "tgw-rtb-0s_tgw-attach-0s"
+}
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("ExportTgwTerraform() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTerraformWriter_uniqueName(t *testing.T) {
+	tf := &terraformWriter{names: make(map[string]bool)}
+	tests := []struct {
+		parts []string
+		want  string
+	}{
+		{parts: []string{"core", "10.0.0.0/16"}, want: "core_10_0_0_0_16"},
+		{parts: []string{"core", "10.0.0.0/16"}, want: "core_10_0_0_0_16_2"},
+		{parts: []string{"core", "10.0.0.0-16"}, want: "core_10_0_0_0-16"},
+		{parts: []string{"1st", "rtb"}, want: "_1st_rtb"},
+		{parts: []string{"héllo wörld"}, want: "h_llo_w_rld"},
+	}
+	for _, tt := range tests {
+		if got := tf.uniqueName("aws_ec2_transit_gateway_route", tt.parts); got != tt.want {
+			t.Errorf("uniqueName(%v) = %q, want %q", tt.parts, got, tt.want)
+		}
+	}
+}
+
+func TestTerraformPrefix(t *testing.T) {
+	tgws := []*Tgw{
+		{ID: "tgw-0a", Name: "core"},
+		{ID: "tgw-0b", Name: "edge"},
+		{ID: "tgw-0c", Name: "edge"},
+		{ID: "tgw-0d"},
+		{ID: "tgw-0e", Name: "edge west"},
+		{ID: "tgw-0f", Name: "edge_west"},
+	}
+	var got []string
+	for _, tgw := range tgws {
+		got = append(got, TerraformPrefix(tgw, tgws))
+	}
+	want := []string{"core", "edge_tgw-0b", "edge_tgw-0c", "tgw-0d", "edge west_tgw-0e", "edge_west_tgw-0f"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("TerraformPrefix() mismatch (-want +got):\n%s", diff)
+	}
+}
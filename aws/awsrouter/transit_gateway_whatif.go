@@ -0,0 +1,495 @@
+package awsrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"gopkg.in/yaml.v3"
+)
+
+// WhatIfAction is a change to the routing of a Transit Gateway that can be simulated with ApplyWhatIf.
+type WhatIfAction string
+
+const (
+	// WhatIfAddStaticRoute adds a static route to Destination through Attachment, or a blackhole route if Blackhole is set.
+	WhatIfAddStaticRoute WhatIfAction = "add-static-route"
+	// WhatIfRemoveStaticRoute removes the static route to Destination.
+	// If an attachment that propagates to RouteTable propagates Destination, its propagated route takes its place.
+	WhatIfRemoveStaticRoute WhatIfAction = "remove-static-route"
+	// WhatIfAssociate associates Attachment to RouteTable, replacing its current association.
+	WhatIfAssociate WhatIfAction = "associate"
+	// WhatIfDisassociate removes the association of Attachment.
+	WhatIfDisassociate WhatIfAction = "disassociate"
+	// WhatIfEnablePropagation propagates the routes of Attachment to RouteTable.
+	// The prefixes are the ones Attachment already propagates to other route tables of the TGW.
+	WhatIfEnablePropagation WhatIfAction = "enable-propagation"
+	// WhatIfDisablePropagation removes the routes propagated by Attachment from RouteTable.
+	WhatIfDisablePropagation WhatIfAction = "disable-propagation"
+	// WhatIfDeleteAttachment deletes Attachment: its association and propagated routes are removed
+	// and the static routes to it become blackholes, like AWS does.
+	WhatIfDeleteAttachment WhatIfAction = "delete-attachment"
+)
+
+// WhatIfChange is a change of a change file. Tgw and RouteTable are IDs or names,
+// Tgw can be empty when there is only one Transit Gateway.
+type WhatIfChange struct {
+	Action      WhatIfAction `yaml:"action"`
+	Tgw         string       `yaml:"tgw"`
+	RouteTable  string       `yaml:"route_table"`
+	Destination string       `yaml:"destination"`
+	Attachment  string       `yaml:"attachment"`
+	Blackhole   bool         `yaml:"blackhole"`
+}
+
+// WhatIfFlow is a flow between two IP addresses to check before and after the changes.
+type WhatIfFlow struct {
+	Tgw         string `yaml:"tgw"`
+	Source      string `yaml:"source"`
+	Destination string `yaml:"destination"`
+}
+
+// WhatIfFile is the content of a change file, the changes are applied in order.
+// If Flows is empty the flows between all the propagated prefixes are checked.
+type WhatIfFile struct {
+	Changes []WhatIfChange `yaml:"changes"`
+	Flows   []WhatIfFlow   `yaml:"flows"`
+}
+
+// ReadWhatIfFile reads a YAML change file.
+func ReadWhatIfFile(r io.Reader) (*WhatIfFile, error) {
+	var f WhatIfFile
+	if err := yaml.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("error reading the change file: %w", err)
+	}
+	return &f, nil
+}
+
+// CopyTgws returns a deep copy of tgws, with the route index of each route table.
+func CopyTgws(tgws []*Tgw) ([]*Tgw, error) {
+	result := make([]*Tgw, 0, len(tgws))
+	for _, tgw := range tgws {
+		c, err := NewTgwFromBytes(tgw.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// ApplyWhatIf returns a copy of tgws with the changes applied in order, tgws is not modified and AWS is not contacted.
+func ApplyWhatIf(tgws []*Tgw, changes []WhatIfChange) ([]*Tgw, error) {
+	result, err := CopyTgws(tgws)
+	if err != nil {
+		return nil, err
+	}
+	for i, change := range changes {
+		tgw, err := findTgw(result, change.Tgw)
+		if err != nil {
+			return nil, fmt.Errorf("error in change %d (%s): %w", i+1, change.Action, err)
+		}
+		if err := tgw.applyWhatIf(change); err != nil {
+			return nil, fmt.Errorf("error in change %d (%s): %w", i+1, change.Action, err)
+		}
+	}
+	for _, tgw := range result {
+		for _, rt := range tgw.RouteTables {
+			if err := rt.BuildRouteIndex(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// applyWhatIf applies one change to the Tgw.
+// The routes are changed in place and the route indexes are stale until ApplyWhatIf rebuilds them, so no lookup is done here.
+func (t *Tgw) applyWhatIf(change WhatIfChange) error {
+	var rt *TgwRouteTable
+	switch change.Action {
+	case WhatIfAddStaticRoute, WhatIfRemoveStaticRoute, WhatIfAssociate, WhatIfEnablePropagation, WhatIfDisablePropagation:
+		var err error
+		if rt, err = t.findRouteTable(change.RouteTable); err != nil {
+			return err
+		}
+	}
+	var att *TgwAttachment
+	switch change.Action {
+	case WhatIfAssociate, WhatIfDisassociate, WhatIfEnablePropagation, WhatIfDisablePropagation, WhatIfDeleteAttachment:
+		if att = t.findAttachment(change.Attachment); att == nil {
+			return fmt.Errorf("attachment %q not found in %s", change.Attachment, t.Name)
+		}
+	case WhatIfAddStaticRoute:
+		if !change.Blackhole {
+			if att = t.findAttachment(change.Attachment); att == nil {
+				return fmt.Errorf("attachment %q not found in %s", change.Attachment, t.Name)
+			}
+		}
+	}
+
+	switch change.Action {
+	case WhatIfAddStaticRoute:
+		if _, _, err := net.ParseCIDR(change.Destination); err != nil {
+			return fmt.Errorf("invalid destination %q: %w", change.Destination, err)
+		}
+		route := types.TransitGatewayRoute{
+			DestinationCidrBlock: aws.String(change.Destination),
+			Type:                 types.TransitGatewayRouteTypeStatic,
+			State:                types.TransitGatewayRouteStateBlackhole,
+		}
+		if att != nil {
+			route.State = types.TransitGatewayRouteStateActive
+			route.TransitGatewayAttachments = []types.TransitGatewayRouteAttachment{routeAttachment(att)}
+		}
+		// A static route replaces a propagated route to the same destination.
+		for i, r := range rt.Routes {
+			if aws.StringValue(r.DestinationCidrBlock) != change.Destination {
+				continue
+			}
+			if r.Type == types.TransitGatewayRouteTypeStatic {
+				return fmt.Errorf("route table %s already has a static route to %s", rt.Name, change.Destination)
+			}
+			rt.Routes[i] = route
+			return nil
+		}
+		rt.Routes = append(rt.Routes, route)
+	case WhatIfRemoveStaticRoute:
+		for i, r := range rt.Routes {
+			if aws.StringValue(r.DestinationCidrBlock) == change.Destination && r.Type == types.TransitGatewayRouteTypeStatic {
+				rt.Routes = append(rt.Routes[:i], rt.Routes[i+1:]...)
+				t.restorePropagatedRoute(rt, change.Destination)
+				return nil
+			}
+		}
+		return fmt.Errorf("route table %s has no static route to %s", rt.Name, change.Destination)
+	case WhatIfAssociate:
+		t.disassociate(att.ID)
+		rt.Attachments = append(rt.Attachments, att)
+	case WhatIfDisassociate:
+		if !t.disassociate(att.ID) {
+			return fmt.Errorf("attachment %s is not associated", att.ID)
+		}
+	case WhatIfEnablePropagation:
+		prefixes := t.propagatedPrefixes(att.ID)
+		if len(prefixes) == 0 {
+			return fmt.Errorf("attachment %s does not propagate to any route table, its prefixes are unknown", att.ID)
+		}
+	next:
+		for _, prefix := range prefixes {
+			for _, r := range rt.Routes {
+				if aws.StringValue(r.DestinationCidrBlock) == prefix {
+					continue next
+				}
+			}
+			rt.Routes = append(rt.Routes, types.TransitGatewayRoute{
+				DestinationCidrBlock:      aws.String(prefix),
+				Type:                      types.TransitGatewayRouteTypePropagated,
+				State:                     types.TransitGatewayRouteStateActive,
+				TransitGatewayAttachments: []types.TransitGatewayRouteAttachment{routeAttachment(att)},
+			})
+		}
+		if propagates, known := rt.HasPropagation(att.ID); known && !propagates {
+			rt.Propagations = append(rt.Propagations, att)
+		}
+	case WhatIfDisablePropagation:
+		removeRoutesTo(rt, att.ID, types.TransitGatewayRouteTypePropagated)
+		removePropagation(rt, att.ID)
+	case WhatIfDeleteAttachment:
+		t.disassociate(att.ID)
+		for _, rt := range t.RouteTables {
+			removeRoutesTo(rt, att.ID, types.TransitGatewayRouteTypePropagated)
+			removeRoutesTo(rt, att.ID, types.TransitGatewayRouteTypeStatic)
+			removePropagation(rt, att.ID)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", change.Action)
+	}
+	return nil
+}
+
+// findTgw returns the Tgw with the ID or name id, or the only Tgw if id is empty.
+func findTgw(tgws []*Tgw, id string) (*Tgw, error) {
+	if id == "" {
+		if len(tgws) == 1 {
+			return tgws[0], nil
+		}
+		return nil, fmt.Errorf("the transit gateway is required when there is more than one")
+	}
+	for _, tgw := range tgws {
+		if tgw.ID == id || tgw.Name == id {
+			return tgw, nil
+		}
+	}
+	return nil, fmt.Errorf("transit gateway %q not found", id)
+}
+
+// findRouteTable returns the route table with the ID or name id.
+func (t *Tgw) findRouteTable(id string) (*TgwRouteTable, error) {
+	for _, rt := range t.RouteTables {
+		if rt.ID == id || rt.Name == id {
+			return rt, nil
+		}
+	}
+	return nil, fmt.Errorf("route table %q not found in %s: %w", id, t.Name, ErrTgwRouteTableNotFound)
+}
+
+// findAttachment returns a copy of the attachment with the ID or name id, or nil if the Tgw has no such attachment.
+func (t *Tgw) findAttachment(id string) *TgwAttachment {
+	if id == "" {
+		return nil
+	}
+	for _, att := range t.Attachments() {
+		if att.ID == id || att.Name == id {
+			c := *att
+			return &c
+		}
+	}
+	return nil
+}
+
+// disassociate removes the association of the attachment and reports if it was associated.
+func (t *Tgw) disassociate(attachmentID string) bool {
+	for _, rt := range t.RouteTables {
+		for i, att := range rt.Attachments {
+			if att.ID == attachmentID {
+				rt.Attachments = append(rt.Attachments[:i], rt.Attachments[i+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// removePropagation removes the attachment from the Propagations of the route table, if they are known.
+func removePropagation(rt *TgwRouteTable, attachmentID string) {
+	for i, att := range rt.Propagations {
+		if att.ID == attachmentID {
+			rt.Propagations = append(rt.Propagations[:i], rt.Propagations[i+1:]...)
+			return
+		}
+	}
+}
+
+// propagatedPrefixes returns the sorted prefixes propagated by the attachment to any route table.
+func (t *Tgw) propagatedPrefixes(attachmentID string) []string {
+	var prefixes []string
+	for _, rt := range t.RouteTables {
+		for _, route := range rt.Routes {
+			if route.Type == types.TransitGatewayRouteTypePropagated && route.DestinationCidrBlock != nil && routeUses(route, attachmentID) {
+				prefixes = append(prefixes, *route.DestinationCidrBlock)
+			}
+		}
+	}
+	return sortedUnique(prefixes)
+}
+
+// restorePropagatedRoute adds the propagated route to destination of the attachments that propagate to the route table
+// and propagate destination to any route table, it is the route a static route replaced.
+func (t *Tgw) restorePropagatedRoute(rt *TgwRouteTable, destination string) {
+	var atts []types.TransitGatewayRouteAttachment
+	for _, att := range t.Attachments() {
+		if propagates, _ := rt.HasPropagation(att.ID); !propagates {
+			continue
+		}
+		for _, prefix := range t.propagatedPrefixes(att.ID) {
+			if prefix == destination {
+				atts = append(atts, routeAttachment(att))
+				break
+			}
+		}
+	}
+	if len(atts) == 0 {
+		return
+	}
+	rt.Routes = append(rt.Routes, types.TransitGatewayRoute{
+		DestinationCidrBlock:      aws.String(destination),
+		Type:                      types.TransitGatewayRouteTypePropagated,
+		State:                     types.TransitGatewayRouteStateActive,
+		TransitGatewayAttachments: atts,
+	})
+}
+
+// removeRoutesTo removes the attachment from the next hops of the routes of routeType.
+// A propagated route without next hops is removed, a static one becomes a blackhole.
+func removeRoutesTo(rt *TgwRouteTable, attachmentID string, routeType types.TransitGatewayRouteType) {
+	routes := rt.Routes[:0]
+	for _, route := range rt.Routes {
+		if route.Type != routeType || !routeUses(route, attachmentID) {
+			routes = append(routes, route)
+			continue
+		}
+		var atts []types.TransitGatewayRouteAttachment
+		for _, att := range route.TransitGatewayAttachments {
+			if aws.StringValue(att.TransitGatewayAttachmentId) != attachmentID {
+				atts = append(atts, att)
+			}
+		}
+		route.TransitGatewayAttachments = atts
+		if len(atts) == 0 {
+			if routeType == types.TransitGatewayRouteTypePropagated {
+				continue
+			}
+			route.State = types.TransitGatewayRouteStateBlackhole
+		}
+		routes = append(routes, route)
+	}
+	rt.Routes = routes
+}
+
+// routeUses reports if the attachment is a next hop of the route.
+func routeUses(route types.TransitGatewayRoute, attachmentID string) bool {
+	for _, att := range route.TransitGatewayAttachments {
+		if aws.StringValue(att.TransitGatewayAttachmentId) == attachmentID {
+			return true
+		}
+	}
+	return false
+}
+
+// routeAttachment returns the attachment as the next hop of a route.
+func routeAttachment(att *TgwAttachment) types.TransitGatewayRouteAttachment {
+	return types.TransitGatewayRouteAttachment{
+		TransitGatewayAttachmentId: aws.String(att.ID),
+		ResourceId:                 aws.String(att.ResourceID),
+		ResourceType:               types.TransitGatewayAttachmentResourceType(att.Type),
+	}
+}
+
+// FlowVerdict is the result of the walk of a flow.
+type FlowVerdict string
+
+const (
+	FlowReachable FlowVerdict = "reachable"
+	FlowBlackhole FlowVerdict = "blackhole"
+	FlowNoRoute   FlowVerdict = "no-route"
+	// FlowVpnDown is a flow sent to a VPN with all its tunnels down.
+	FlowVpnDown FlowVerdict = "vpn-down"
+	// FlowCloudWan is a flow that leaves the Tgw to a Cloud WAN core network, its routing is not known.
+	FlowCloudWan FlowVerdict = "cloud-wan"
+	// FlowUnreachable is a walk that failed for another reason, like a source without attachment or a loop.
+	FlowUnreachable FlowVerdict = "unreachable"
+)
+
+// FlowResult is the verdict of a flow before and after the changes, with the IDs of the attachments in each path.
+type FlowResult struct {
+	TgwID       string
+	TgwName     string
+	Source      string
+	Destination string
+	Before      FlowVerdict
+	After       FlowVerdict
+	BeforePath  []string
+	AfterPath   []string
+}
+
+// Changed reports if the verdict or the path of the flow changed.
+func (r FlowResult) Changed() bool {
+	return r.Before != r.After || strings.Join(r.BeforePath, ",") != strings.Join(r.AfterPath, ",")
+}
+
+// WalkVerdict walks the flow from src to dst in the Tgw offline and returns the verdict and the IDs of the attachments in the path.
+func (t *Tgw) WalkVerdict(src, dst net.IP) (FlowVerdict, []string) {
+	attPath := NewAttPath()
+	attPath.Tgw = t
+	err := attPath.Walk(context.TODO(), nil, src, dst)
+	path := make([]string, 0, len(attPath.Path))
+	for _, att := range attPath.Path {
+		path = append(path, att.ID)
+	}
+	switch {
+	case err == nil:
+		return FlowReachable, path
+	case errors.Is(err, ErrTgwRouteBlackhole):
+		return FlowBlackhole, path
+	case errors.Is(err, ErrTgwRouteTableRouteNotFound):
+		return FlowNoRoute, path
+	case errors.Is(err, ErrTgwVpnDown):
+		return FlowVpnDown, path
+	case errors.Is(err, ErrTgwCloudWan):
+		return FlowCloudWan, path
+	default:
+		return FlowUnreachable, path
+	}
+}
+
+// DefaultFlows returns the flows between the first address of every pair of different prefixes
+// propagated in the Tgw before or after the changes, after can be nil.
+func DefaultFlows(before, after *Tgw) []WhatIfFlow {
+	var prefixes []string
+	for _, tgw := range []*Tgw{before, after} {
+		if tgw == nil {
+			continue
+		}
+		for _, att := range tgw.Attachments() {
+			prefixes = append(prefixes, tgw.propagatedPrefixes(att.ID)...)
+		}
+	}
+	var ips []string
+	for _, prefix := range sortedUnique(prefixes) {
+		_, ipNet, err := net.ParseCIDR(prefix)
+		if err != nil {
+			continue
+		}
+		ip := make(net.IP, len(ipNet.IP))
+		copy(ip, ipNet.IP)
+		ip[len(ip)-1]++
+		ips = append(ips, ip.String())
+	}
+	var flows []WhatIfFlow
+	for _, src := range ips {
+		for _, dst := range ips {
+			if src != dst {
+				flows = append(flows, WhatIfFlow{Tgw: before.ID, Source: src, Destination: dst})
+			}
+		}
+	}
+	return flows
+}
+
+// WhatIf returns the verdict of the flows in before and after the changes.
+// If flows is empty the DefaultFlows of each Transit Gateway are used.
+// The results are sorted by TGW, source and destination.
+func WhatIf(before, after []*Tgw, flows []WhatIfFlow) ([]FlowResult, error) {
+	if len(flows) == 0 {
+		for _, b := range before {
+			a, _ := findTgw(after, b.ID)
+			flows = append(flows, DefaultFlows(b, a)...)
+		}
+	}
+	var results []FlowResult
+	for _, flow := range flows {
+		src, dst := net.ParseIP(flow.Source), net.ParseIP(flow.Destination)
+		if src == nil || dst == nil {
+			return nil, fmt.Errorf("invalid flow from %q to %q", flow.Source, flow.Destination)
+		}
+		b, err := findTgw(before, flow.Tgw)
+		if err != nil {
+			return nil, err
+		}
+		a, err := findTgw(after, b.ID)
+		if err != nil {
+			return nil, err
+		}
+		r := FlowResult{TgwID: b.ID, TgwName: b.Name, Source: flow.Source, Destination: flow.Destination}
+		r.Before, r.BeforePath = b.WalkVerdict(src, dst)
+		r.After, r.AfterPath = a.WalkVerdict(src, dst)
+		results = append(results, r)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].TgwID != results[j].TgwID {
+			return results[i].TgwID < results[j].TgwID
+		}
+		if results[i].Source != results[j].Source {
+			return results[i].Source < results[j].Source
+		}
+		return results[i].Destination < results[j].Destination
+	})
+	return results, nil
+}
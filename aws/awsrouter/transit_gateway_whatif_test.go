@@ -0,0 +1,201 @@
+package awsrouter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+// whatIfTgws returns a TGW where the VPCs a and b are associated to spokes and the shared services VPC s to shared,
+// all of them propagate to both route tables.
+func whatIfTgws() []*Tgw {
+	attA := &TgwAttachment{ID: "tgw-attach-0a", Name: "vpc-a", ResourceID: "vpc-tgw-attach-0a", Type: "vpc"}
+	attB := &TgwAttachment{ID: "tgw-attach-0b", Name: "vpc-b", ResourceID: "vpc-tgw-attach-0b", Type: "vpc"}
+	attS := &TgwAttachment{ID: "tgw-attach-0s", Name: "vpc-s", ResourceID: "vpc-tgw-attach-0s", Type: "vpc"}
+	routes := func() []types.TransitGatewayRoute {
+		return []types.TransitGatewayRoute{
+			diffRoute("10.0.0.0/16", "active", "tgw-attach-0a"),
+			diffRoute("10.1.0.0/16", "active", "tgw-attach-0b"),
+			diffRoute("10.9.0.0/16", "active", "tgw-attach-0s"),
+		}
+	}
+	return []*Tgw{
+		{
+			ID:   "tgw-0a",
+			Name: "core",
+			RouteTables: []*TgwRouteTable{
+				{ID: "tgw-rtb-0a", Name: "spokes", Attachments: []*TgwAttachment{attA, attB}, Routes: routes()},
+				{ID: "tgw-rtb-0s", Name: "shared", Attachments: []*TgwAttachment{attS}, Routes: routes()},
+			},
+		},
+	}
+}
+
+// changedFlows returns the flows that changed as "source>destination before>after".
+func changedFlows(results []FlowResult) []string {
+	var changed []string
+	for _, r := range results {
+		if r.Changed() {
+			changed = append(changed, r.Source+">"+r.Destination+" "+string(r.Before)+">"+string(r.After))
+		}
+	}
+	return changed
+}
+
+func TestWhatIf(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []WhatIfChange
+		flows   []WhatIfFlow
+		want    []string
+		wantErr string
+	}{
+		{
+			name:    "No Changes",
+			changes: nil,
+		},
+		{
+			name:    "Delete Attachment",
+			changes: []WhatIfChange{{Action: WhatIfDeleteAttachment, Attachment: "vpc-s"}},
+			want: []string{
+				"10.0.0.1>10.9.0.1 reachable>no-route",
+				"10.1.0.1>10.9.0.1 reachable>no-route",
+				"10.9.0.1>10.0.0.1 reachable>unreachable",
+				"10.9.0.1>10.1.0.1 reachable>unreachable",
+			},
+		},
+		{
+			name:    "Disable Propagation",
+			changes: []WhatIfChange{{Action: WhatIfDisablePropagation, Tgw: "core", RouteTable: "spokes", Attachment: "tgw-attach-0s"}},
+			want: []string{
+				"10.0.0.1>10.9.0.1 reachable>no-route",
+				"10.1.0.1>10.9.0.1 reachable>no-route",
+			},
+		},
+		{
+			name: "Blackhole Static Route",
+			changes: []WhatIfChange{
+				{Action: WhatIfAddStaticRoute, RouteTable: "tgw-rtb-0s", Destination: "10.0.0.0/16", Blackhole: true},
+			},
+			want: []string{
+				"10.9.0.1>10.0.0.1 reachable>blackhole",
+			},
+		},
+		{
+			name: "Static Route Removed",
+			changes: []WhatIfChange{
+				{Action: WhatIfAddStaticRoute, RouteTable: "shared", Destination: "10.5.0.0/16", Attachment: "vpc-a"},
+				{Action: WhatIfRemoveStaticRoute, RouteTable: "shared", Destination: "10.5.0.0/16"},
+			},
+			flows: []WhatIfFlow{{Source: "10.9.0.1", Destination: "10.5.0.1"}},
+		},
+		{
+			name: "Static Route Added",
+			changes: []WhatIfChange{
+				{Action: WhatIfAddStaticRoute, RouteTable: "shared", Destination: "10.5.0.0/16", Attachment: "vpc-a"},
+				{Action: WhatIfAddStaticRoute, RouteTable: "spokes", Destination: "10.5.0.0/16", Attachment: "vpc-a"},
+			},
+			flows: []WhatIfFlow{{Tgw: "core", Source: "10.9.0.1", Destination: "10.5.0.1"}},
+			want:  []string{"10.9.0.1>10.5.0.1 no-route>reachable"},
+		},
+		{
+			name:    "Disassociate",
+			changes: []WhatIfChange{{Action: WhatIfDisassociate, Attachment: "vpc-b"}},
+			want: []string{
+				"10.0.0.1>10.1.0.1 reachable>unreachable",
+				"10.1.0.1>10.0.0.1 reachable>no-route",
+				"10.1.0.1>10.9.0.1 reachable>no-route",
+				"10.9.0.1>10.1.0.1 reachable>unreachable",
+			},
+		},
+		{
+			name:    "Unknown Route Table",
+			changes: []WhatIfChange{{Action: WhatIfAddStaticRoute, RouteTable: "missing", Destination: "10.5.0.0/16", Blackhole: true}},
+			wantErr: `error in change 1 (add-static-route): route table "missing" not found`,
+		},
+		{
+			name:    "Missing Static Route",
+			changes: []WhatIfChange{{Action: WhatIfRemoveStaticRoute, RouteTable: "spokes", Destination: "10.0.0.0/16"}},
+			wantErr: "route table spokes has no static route to 10.0.0.0/16",
+		},
+		{
+			name: "Unknown Propagation Prefixes",
+			changes: []WhatIfChange{
+				{Action: WhatIfDeleteAttachment, Attachment: "vpc-s"},
+				{Action: WhatIfEnablePropagation, RouteTable: "spokes", Attachment: "tgw-attach-0s"},
+			},
+			wantErr: `error in change 2 (enable-propagation): attachment "tgw-attach-0s" not found`,
+		},
+		{
+			name:    "Unknown Action",
+			changes: []WhatIfChange{{Action: "rename", Attachment: "vpc-s"}},
+			wantErr: `unknown action "rename"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := whatIfTgws()
+			after, err := ApplyWhatIf(before, tt.changes)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ApplyWhatIf() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changes := DiffTgws(whatIfTgws(), before); len(changes) != 0 {
+				t.Errorf("ApplyWhatIf() modified the input: %+v", changes)
+			}
+			results, err := WhatIf(before, after, tt.flows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, changedFlows(results)); diff != "" {
+				t.Errorf("WhatIf() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApplyWhatIfRemoveStaticRoute(t *testing.T) {
+	tests := []struct {
+		name         string
+		propagations []*TgwAttachment
+		want         []string
+	}{
+		{
+			name:         "Propagated Route Restored",
+			propagations: []*TgwAttachment{{ID: "tgw-attach-0a"}, {ID: "tgw-attach-0s"}},
+		},
+		{
+			name:         "No Propagation",
+			propagations: []*TgwAttachment{{ID: "tgw-attach-0s"}},
+			want:         []string{"10.9.0.1>10.0.0.1 reachable>no-route"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := whatIfTgws()
+			before[0].RouteTables[1].Propagations = tt.propagations
+			// The static route replaces the route propagated by vpc-a, removing it brings it back if vpc-a propagates to shared.
+			after, err := ApplyWhatIf(before, []WhatIfChange{
+				{Action: WhatIfAddStaticRoute, RouteTable: "shared", Destination: "10.0.0.0/16", Blackhole: true},
+				{Action: WhatIfRemoveStaticRoute, RouteTable: "shared", Destination: "10.0.0.0/16"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, err := WhatIf(before, after, []WhatIfFlow{{Source: "10.9.0.1", Destination: "10.0.0.1"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, changedFlows(results)); diff != "" {
+				t.Errorf("WhatIf() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
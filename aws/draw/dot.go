@@ -0,0 +1,97 @@
+package draw
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// dotQuote returns s as a quoted DOT string.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// nodeText returns the label and the detail of a node in two lines.
+func nodeText(n *Node) string {
+	if n.Detail == "" || n.Detail == n.Label {
+		return n.Label
+	}
+	return n.Label + "\n" + n.Detail
+}
+
+// dotEdgeStyle returns the attributes of the edges of an EdgeKind.
+func dotEdgeStyle(kind EdgeKind) string {
+	attrs := []string{"color=" + dotQuote(svgColor(edgeColors[kind]))}
+	switch kind {
+	case EdgeAssociation:
+		attrs = append(attrs, "arrowhead=none")
+	case EdgePropagation:
+		attrs = append(attrs, "arrowhead=none", "style=dashed")
+	case EdgeTraffic:
+		attrs = append(attrs, "penwidth=2")
+	case EdgeEcmp:
+		attrs = append(attrs, "style=dashed")
+	case EdgeTransport:
+		attrs = append(attrs, "arrowhead=none", "style=dotted")
+	}
+	return "[" + strings.Join(attrs, ", ") + "]"
+}
+
+// WriteDOT writes the diagram in the Graphviz DOT language.
+// The TGW and the groups are clusters, Graphviz does its own layout so the coordinates of the Diagram are not used.
+// The output only depends on the order of the nodes and edges, so the same diagram is always written the same way.
+func WriteDOT(w io.Writer, d *Diagram) error {
+	var b strings.Builder
+	rankdir := "TB"
+	if d.LeftToRight {
+		rankdir = "LR"
+	}
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(d.Title))
+	fmt.Fprintf(&b, "  graph [label=%s, labelloc=t, rankdir=%s, fontname=\"sans-serif\"];\n", dotQuote(d.Title), rankdir)
+	b.WriteString("  node [shape=box, fontname=\"sans-serif\"];\n")
+
+	// Nodes inside a TGW or a group are written in their cluster.
+	written := make(map[*Node]bool)
+	writeNode := func(n *Node, indent string) {
+		attrs := "label=" + dotQuote(nodeText(n))
+		if c, ok := nodeColors[n.Kind]; ok {
+			attrs += fmt.Sprintf(", style=filled, fillcolor=%s", dotQuote(svgColor(c)))
+		}
+		fmt.Fprintf(&b, "%s%s [%s];\n", indent, dotQuote(n.ID), attrs)
+		written[n] = true
+	}
+	cluster := 0
+	writeCluster := func(label string, nodes []*Node) {
+		fmt.Fprintf(&b, "  subgraph cluster_%d {\n", cluster)
+		fmt.Fprintf(&b, "    label=%s;\n", dotQuote(label))
+		for _, n := range nodes {
+			writeNode(n, "    ")
+		}
+		b.WriteString("  }\n")
+		cluster++
+	}
+	for _, n := range d.Nodes {
+		if n.Kind == NodeTgw {
+			written[n] = true
+			writeCluster(n.Label, d.Children(n))
+		}
+	}
+	for _, g := range d.Groups {
+		writeCluster(g.Label, g.Nodes)
+	}
+	for _, n := range d.Nodes {
+		if !written[n] {
+			writeNode(n, "  ")
+		}
+	}
+	for _, e := range d.Edges {
+		if d.Node(e.From) == nil || d.Node(e.To) == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s -> %s %s;\n", dotQuote(e.From), dotQuote(e.To), dotEdgeStyle(e.Kind))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -7,10 +7,8 @@ import (
 
 	"github.com/rogerscuall/aws-router/aws/awsrouter"
 	"golang.org/x/image/font"
-	"golang.org/x/image/font/gofont/goregular"
 
 	"github.com/fogleman/gg"
-	"github.com/golang/freetype/truetype"
 )
 
 // type Router interface {
@@ -71,7 +69,8 @@ func CreateTgwContext(tgw awsrouter.Tgw) *gg.Context {
 	return dc
 }
 
-// DrawTgwFull draws a TGW and all the route tables
+// DrawTgwFull draws a TGW with all the route tables and attachments.
+// The lines between route tables and attachments are the associations and propagations, see TgwDiagram.
 func DrawTgwFull(tgw awsrouter.Tgw, folder fs.FileInfo) error {
 	// if the TGW has no Route Tables return an error
 	if len(tgw.RouteTables) == 0 {
@@ -81,32 +80,9 @@ func DrawTgwFull(tgw awsrouter.Tgw, folder fs.FileInfo) error {
 	if folder == nil || !folder.IsDir() {
 		return fmt.Errorf("folder is nil")
 	}
-
-	// initial x and y coordinates
-	x, y := float64(100), float64(100)
-	dc := CreateTgwContext(tgw)
-	// set the font
-	font, err := truetype.Parse(goregular.TTF)
-	if err != nil {
-		return err
-	}
-	face := truetype.NewFace(font, &truetype.Options{
-		Size: 40,
-	})
-	dc.SetFontFace(face)
-	DrawTgw(dc, x, y, tgw)
-	face = truetype.NewFace(font, &truetype.Options{
-		Size: 15,
-	})
-	dc.SetFontFace(face)
-	for i, rt := range tgw.RouteTables {
-		dc.Push()
-		x := float64(100 + routeMargin + i*(routeWidth+routeMargin))
-		y := float64(100 + routeMargin)
-		DrawTgwRouteTable(dc, x, y, rt.Name, face)
-		dc.Pop()
-	}
 	fileName := fmt.Sprintf("%s/%s.png", folder.Name(), tgw.Name)
-	dc.SavePNG(fileName)
+	if err := RenderPNG(TgwDiagram(tgw), fileName); err != nil {
+		return fmt.Errorf("error saving the drawing %w", err)
+	}
 	return nil
 }
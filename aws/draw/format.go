@@ -0,0 +1,89 @@
+package draw
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Format is the file format of a drawing.
+type Format string
+
+const (
+	FormatPNG     Format = "png"
+	FormatSVG     Format = "svg"
+	FormatDOT     Format = "dot"
+	FormatMermaid Format = "mermaid"
+)
+
+// ErrUnknownFormat is returned for a format that is not supported.
+var ErrUnknownFormat = errors.New("draw: unknown format")
+
+// Formats returns the names of all the supported formats.
+func Formats() []string {
+	return []string{string(FormatPNG), string(FormatSVG), string(FormatDOT), string(FormatMermaid)}
+}
+
+// ParseFormat returns the Format with the name s, the name is not case sensitive.
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(s))
+	switch f {
+	case FormatPNG, FormatSVG, FormatDOT, FormatMermaid:
+		return f, nil
+	}
+	return "", fmt.Errorf("%w %q, valid formats are %s", ErrUnknownFormat, s, strings.Join(Formats(), ", "))
+}
+
+// Extension returns the file extension used for the format, without the dot.
+func (f Format) Extension() string {
+	if f == FormatMermaid {
+		return "mmd"
+	}
+	return string(f)
+}
+
+// ContentType returns the MIME type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatPNG:
+		return "image/png"
+	case FormatSVG:
+		return "image/svg+xml"
+	case FormatDOT:
+		return "text/vnd.graphviz; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// Write draws the diagram in the given format in w.
+func Write(w io.Writer, d *Diagram, format Format) error {
+	switch format {
+	case FormatPNG:
+		return WritePNG(w, d)
+	case FormatSVG:
+		return WriteSVG(w, d)
+	case FormatDOT:
+		return WriteDOT(w, d)
+	case FormatMermaid:
+		return WriteMermaid(w, d)
+	}
+	return fmt.Errorf("%w %q", ErrUnknownFormat, format)
+}
+
+// Save draws the diagram in the given format and saves it in fileName.
+func Save(d *Diagram, format Format, fileName string) error {
+	if _, err := ParseFormat(string(format)); err != nil {
+		return err
+	}
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, d, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
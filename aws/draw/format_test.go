@@ -0,0 +1,230 @@
+package draw
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+)
+
+// smallTgw returns a TGW with one route table and one attachment associated and propagating to it.
+func smallTgw() awsrouter.Tgw {
+	tgw := layoutTgw()
+	rt := tgw.RouteTables[0]
+	rt.Attachments = rt.Attachments[:1]
+	rt.Routes = rt.Routes[:1]
+	tgw.RouteTables = tgw.RouteTables[:1]
+	return tgw
+}
+
+// smallPath returns the diagram of a path dropped by a blackhole route.
+func smallPath() *Diagram {
+	att := &awsrouter.TgwAttachment{ID: "tgw-attach-0a", Name: `vpc "a"`, ResourceID: "vpc-0a"}
+	attPath := &awsrouter.AttPath{
+		Tgw:  &awsrouter.Tgw{Name: "core"},
+		Path: []*awsrouter.TgwAttachment{att},
+		Hops: []awsrouter.PathHop{{RouteTableName: "prod", Prefix: "10.1.0.0/16", Blackhole: true}},
+	}
+	return PathDiagram(attPath, "10.0.0.1", "10.1.0.1", awsrouter.ErrTgwRouteBlackhole)
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Format
+		wantErr bool
+	}{
+		{name: "PNG", s: "png", want: FormatPNG},
+		{name: "Mermaid Upper Case", s: "Mermaid", want: FormatMermaid},
+		{name: "Unknown", s: "jpg", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormat(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFormat() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	tests := []struct {
+		name string
+		d    *Diagram
+		want string
+	}{
+		{
+			name: "Topology",
+			d:    TgwDiagram(smallTgw()),
+			want: `digraph "layout" {
+  graph [label="layout", labelloc=t, rankdir=TB, fontname="sans-serif"];
+  node [shape=box, fontname="sans-serif"];
+  subgraph cluster_0 {
+    label="layout";
+    "tgw-rtb-0a" [label="prod\ntgw-rtb-0a"];
+  }
+  subgraph cluster_1 {
+    label="VPC";
+    "tgw-attach-0a" [label="vpc-a\nvpc-0a"];
+  }
+  "tgw-rtb-0a" -> "tgw-attach-0a" [color="#1f4e78", arrowhead=none];
+  "tgw-attach-0a" -> "tgw-rtb-0a" [color="#388e3c", arrowhead=none, style=dashed];
+}
+`,
+		},
+		{
+			name: "Path",
+			d:    smallPath(),
+			want: `digraph "core: 10.0.0.1 -> 10.1.0.1" {
+  graph [label="core: 10.0.0.1 -> 10.1.0.1", labelloc=t, rankdir=LR, fontname="sans-serif"];
+  node [shape=box, fontname="sans-serif"];
+  subgraph cluster_0 {
+    label="Transit Gateway core";
+    "tgw-attach-0a" [label="vpc \"a\"\nvpc-0a"];
+    "hop-0" [label="prod\n10.1.0.0/16"];
+    "drop" [label="Drop\nblackhole route", style=filled, fillcolor="#f8cbcb"];
+  }
+  "src" [label="10.0.0.1\nsource", style=filled, fillcolor="#ddebf7"];
+  "src" -> "tgw-attach-0a" [color="#000000", penwidth=2];
+  "tgw-attach-0a" -> "hop-0" [color="#000000", penwidth=2];
+  "hop-0" -> "drop" [color="#000000", penwidth=2];
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteDOT(&buf, tt.d); err != nil {
+				t.Fatalf("WriteDOT() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriteDOT() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	tests := []struct {
+		name string
+		d    *Diagram
+		want string
+	}{
+		{
+			name: "Topology",
+			d:    TgwDiagram(smallTgw()),
+			want: `---
+title: "layout"
+---
+flowchart TB
+  subgraph n0 ["layout"]
+    n1["prod<br/>tgw-rtb-0a"]
+  end
+  subgraph g0 ["VPC"]
+    n2["vpc-a<br/>vpc-0a"]
+  end
+  n1 --- n2
+  n2 -.- n1
+`,
+		},
+		{
+			name: "Path",
+			d:    smallPath(),
+			want: `---
+title: "core: 10.0.0.1 -> 10.1.0.1"
+---
+flowchart LR
+  subgraph g0 ["Transit Gateway core"]
+    n1["vpc #quot;a#quot;<br/>vpc-0a"]
+    n2["prod<br/>10.1.0.0/16"]
+    n3["Drop<br/>blackhole route"]
+  end
+  n0["10.0.0.1<br/>source"]
+  n0 ==> n1
+  n1 ==> n2
+  n2 ==> n3
+  classDef endpoint fill:#ddebf7
+  class n0 endpoint
+  classDef drop fill:#f8cbcb
+  class n3 drop
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteMermaid(&buf, tt.d); err != nil {
+				t.Fatalf("WriteMermaid() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriteMermaid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	for _, d := range []*Diagram{TgwDiagram(layoutTgw()), smallPath()} {
+		var first, second bytes.Buffer
+		if err := WriteSVG(&first, d); err != nil {
+			t.Fatalf("WriteSVG() error = %v", err)
+		}
+		WriteSVG(&second, d)
+		if first.String() != second.String() {
+			t.Errorf("WriteSVG() is not deterministic")
+		}
+		// The output must be valid XML.
+		dec := xml.NewDecoder(&first)
+		for {
+			_, err := dec.Token()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("WriteSVG() invalid XML: %v", err)
+			}
+		}
+	}
+}
+
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+	d := TgwDiagram(layoutTgw())
+	for _, name := range Formats() {
+		format, _ := ParseFormat(name)
+		fileName := filepath.Join(dir, "layout."+format.Extension())
+		if err := Save(d, format, fileName); err != nil {
+			t.Errorf("Save() %s error = %v", format, err)
+			continue
+		}
+		if info, err := os.Stat(fileName); err != nil || info.Size() == 0 {
+			t.Errorf("Save() %s did not write %s", format, fileName)
+		}
+	}
+	if err := Save(d, Format("jpg"), filepath.Join(dir, "layout.jpg")); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Save() error = %v, want %v", err, ErrUnknownFormat)
+	}
+}
+
+func TestWriteDOTRouteTableOrder(t *testing.T) {
+	tgw := layoutTgw()
+	var want, got bytes.Buffer
+	WriteDOT(&want, TgwDiagram(tgw))
+	tgw.RouteTables[0], tgw.RouteTables[1] = tgw.RouteTables[1], tgw.RouteTables[0]
+	WriteDOT(&got, TgwDiagram(tgw))
+	if got.String() != want.String() {
+		t.Errorf("WriteDOT() depends on the order of the route tables:\n%v\nwant\n%v", got.String(), want.String())
+	}
+}
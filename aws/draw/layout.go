@@ -0,0 +1,371 @@
+package draw
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+)
+
+// NodeKind is the type of element represented by a Node.
+type NodeKind int
+
+const (
+	NodeTgw NodeKind = iota
+	NodeRouteTable
+	NodeAttachment
+	// NodeEndpoint is the source or destination IP of a path.
+	NodeEndpoint
+	// NodeDrop is where the traffic of a path is dropped.
+	NodeDrop
+)
+
+// EdgeKind is the relation represented by an Edge.
+type EdgeKind int
+
+const (
+	// EdgeAssociation goes from a route table to an attachment associated to it, drawn as a solid line.
+	EdgeAssociation EdgeKind = iota
+	// EdgePropagation goes from an attachment to a route table where it propagates routes, drawn as a dashed line.
+	EdgePropagation
+	// EdgeTraffic is the direction of the traffic in a path, drawn as an arrow.
+	EdgeTraffic
+	// EdgeEcmp goes from a route table to the ECMP next hops not followed by the path, drawn as a dashed arrow.
+	EdgeEcmp
+	// EdgeTransport goes from a Connect attachment to the attachment it runs over, drawn as a dotted line.
+	EdgeTransport
+)
+
+// edgeKinds are all the EdgeKind, in the order used by the legend.
+var edgeKinds = []EdgeKind{EdgeAssociation, EdgePropagation, EdgeTraffic, EdgeEcmp, EdgeTransport}
+
+// String returns the name of the EdgeKind used in the legend.
+func (k EdgeKind) String() string {
+	switch k {
+	case EdgeAssociation:
+		return "Association"
+	case EdgePropagation:
+		return "Propagation"
+	case EdgeTraffic:
+		return "Traffic"
+	case EdgeEcmp:
+		return "ECMP"
+	case EdgeTransport:
+		return "Transport"
+	}
+	return "Unknown"
+}
+
+// Rect is a rectangle in the diagram, X and Y are the top left corner.
+type Rect struct {
+	X, Y, Width, Height float64
+}
+
+// Overlaps returns true if both rectangles share some area.
+func (r Rect) Overlaps(o Rect) bool {
+	return r.X < o.X+o.Width && o.X < r.X+r.Width && r.Y < o.Y+o.Height && o.Y < r.Y+r.Height
+}
+
+// Contains returns true if o is inside r.
+func (r Rect) Contains(o Rect) bool {
+	return o.X >= r.X && o.Y >= r.Y && o.X+o.Width <= r.X+r.Width && o.Y+o.Height <= r.Y+r.Height
+}
+
+// Node is a box in the diagram.
+// Label is the main text, Detail is an optional second line.
+type Node struct {
+	Rect
+	ID     string
+	Label  string
+	Detail string
+	Kind   NodeKind
+}
+
+// Edge is a line between two nodes.
+type Edge struct {
+	From string
+	To   string
+	Kind EdgeKind
+}
+
+// Group is a box that contains nodes of the same type, like all the VPC attachments.
+type Group struct {
+	Rect
+	Label string
+	Nodes []*Node
+}
+
+// Diagram is the layout of a drawing, independent of the renderer.
+// All the coordinates are in pixels and every element is inside Width and Height.
+type Diagram struct {
+	Title  string
+	Width  float64
+	Height float64
+	Nodes  []*Node
+	Edges  []Edge
+	Groups []*Group
+	// Legend is the area reserved for the legend of the edge kinds in Edges.
+	Legend Rect
+	// LeftToRight is true when the diagram flows from left to right, like a path, instead of top to bottom.
+	LeftToRight bool
+}
+
+// Node returns the node with the given ID, or nil if it does not exist.
+func (d *Diagram) Node(id string) *Node {
+	for _, n := range d.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// Children returns the nodes drawn inside the node n, like the route tables of a TGW.
+func (d *Diagram) Children(n *Node) []*Node {
+	var children []*Node
+	for _, c := range d.Nodes {
+		if c != n && n.Rect.Contains(c.Rect) {
+			children = append(children, c)
+		}
+	}
+	return children
+}
+
+// edgeOffset separates the lines of an association and a propagation between the same nodes.
+const edgeOffset = 10
+
+// Anchors returns the start and end points of an edge between from and to.
+// The line goes from the side of from that faces to, into the side of to that faces from.
+func (e Edge) Anchors(from, to *Node) (x1, y1, x2, y2 float64) {
+	var offset float64
+	switch e.Kind {
+	case EdgeAssociation:
+		offset = -edgeOffset
+	case EdgePropagation:
+		offset = edgeOffset
+	}
+	x1, x2 = from.X+from.Width/2+offset, to.X+to.Width/2+offset
+	y1, y2 = from.Y+from.Height/2+offset, to.Y+to.Height/2+offset
+	switch {
+	case from.Y+from.Height <= to.Y:
+		return x1, from.Y + from.Height, x2, to.Y
+	case to.Y+to.Height <= from.Y:
+		return x1, from.Y, x2, to.Y + to.Height
+	case from.X+from.Width <= to.X:
+		return from.X + from.Width, y1, to.X, y2
+	case to.X+to.Width <= from.X:
+		return from.X, y1, to.X + to.Width, y2
+	}
+	return x1, y1, x2, y2
+}
+
+// EdgeKinds returns the kinds of edges used in the diagram, in the order of the EdgeKind constants.
+func (d *Diagram) EdgeKinds() []EdgeKind {
+	used := make(map[EdgeKind]bool)
+	for _, e := range d.Edges {
+		used[e.Kind] = true
+	}
+	var kinds []EdgeKind
+	for _, k := range edgeKinds {
+		if used[k] {
+			kinds = append(kinds, k)
+		}
+	}
+	return kinds
+}
+
+const (
+	// diagramMargin is the space between boxes and around the diagram.
+	diagramMargin = 50
+	// titleHeight is the space for the title of the TGW box.
+	titleHeight = 60
+	// attachmentWidth is the width of an attachment box.
+	attachmentWidth = 260
+	// attachmentHeight is the height of an attachment box.
+	attachmentHeight = 70
+	// groupTitleHeight is the space for the title of a group box.
+	groupTitleHeight = 40
+	// legendWidth is the width of the legend box.
+	legendWidth = 260
+	// legendRowHeight is the height of each entry in the legend.
+	legendRowHeight = 30
+	// minAttachmentsPerRow is the minimum number of attachments in a row of a group.
+	minAttachmentsPerRow = 4
+)
+
+// attachmentGroups are the groups of attachments in the order they are drawn.
+// The keys are the resource types returned by AWS.
+var attachmentGroups = []struct {
+	label string
+	types []string
+}{
+	{label: "VPC", types: []string{"vpc"}},
+	{label: "VPN", types: []string{"vpn"}},
+	{label: "Direct Connect", types: []string{"direct-connect-gateway"}},
+	{label: "Peering", types: []string{"peering", "tgw-peering"}},
+	// The peerings with a Cloud WAN core network are selected by attachmentGroupLabel.
+	{label: "Cloud WAN"},
+	{label: "Connect", types: []string{"connect"}},
+}
+
+// attachmentGroupLabel returns the label of the group of an attachment by its type, unknown types go to "Other".
+func attachmentGroupLabel(att *awsrouter.TgwAttachment) string {
+	if att.CloudWan() {
+		return "Cloud WAN"
+	}
+	for _, g := range attachmentGroups {
+		for _, t := range g.types {
+			if t == att.Type {
+				return g.label
+			}
+		}
+	}
+	return "Other"
+}
+
+// TgwDiagram builds the diagram of a TGW with all its route tables and attachments.
+// The TGW box contains the route tables and then the policy tables in a single row, the attachments are drawn below grouped by type.
+// Associations go from the route table or policy table to the attachment, propagations from the attachment to the route table.
+// Connect attachments show the BGP status of their peers and are linked to the attachment they run over.
+// The size of the diagram grows with the number of route tables and attachments, so nothing is cut.
+// Route tables and attachments are sorted by ID, so the same TGW always gives the same diagram.
+func TgwDiagram(tgw awsrouter.Tgw) *Diagram {
+	d := &Diagram{Title: tgw.Name}
+	tgw.RouteTables = append([]*awsrouter.TgwRouteTable(nil), tgw.RouteTables...)
+	sort.Slice(tgw.RouteTables, func(i, j int) bool { return tgw.RouteTables[i].ID < tgw.RouteTables[j].ID })
+	x, y := float64(diagramMargin), float64(diagramMargin)
+
+	// TGW, route tables and policy tables.
+	numRt := len(tgw.RouteTables) + len(tgw.PolicyTables)
+	tgwBox := &Node{
+		ID:    tgw.ID,
+		Label: tgw.Name,
+		Kind:  NodeTgw,
+		Rect: Rect{
+			X:      x,
+			Y:      y,
+			Width:  math.Max(float64(numRt*(routeWidth+routeMargin)+routeMargin), routeWidth+2*routeMargin),
+			Height: titleHeight + routeHeight + routeMargin,
+		},
+	}
+	d.Nodes = append(d.Nodes, tgwBox)
+	for i, rt := range tgw.RouteTables {
+		d.Nodes = append(d.Nodes, &Node{
+			ID:     rt.ID,
+			Label:  rt.Name,
+			Detail: rt.ID,
+			Kind:   NodeRouteTable,
+			Rect: Rect{
+				X:      x + routeMargin + float64(i*(routeWidth+routeMargin)),
+				Y:      y + titleHeight,
+				Width:  routeWidth,
+				Height: routeHeight,
+			},
+		})
+	}
+	for i, pt := range tgw.PolicyTables {
+		d.Nodes = append(d.Nodes, &Node{
+			ID:     pt.ID,
+			Label:  pt.Name,
+			Detail: "policy table",
+			Kind:   NodeRouteTable,
+			Rect: Rect{
+				X:      x + routeMargin + float64((len(tgw.RouteTables)+i)*(routeWidth+routeMargin)),
+				Y:      y + titleHeight,
+				Width:  routeWidth,
+				Height: routeHeight,
+			},
+		})
+	}
+	// Leave space below the TGW for the lines to spread.
+	y += tgwBox.Height + 2*diagramMargin
+
+	// Attachments grouped by type.
+	attachments := tgw.Attachments()
+	perRow := int(math.Max(minAttachmentsPerRow, math.Ceil(math.Sqrt(float64(len(attachments))))))
+	areaWidth := math.Max(tgwBox.Width, float64(perRow*(attachmentWidth+diagramMargin)+diagramMargin))
+	perRow = int((areaWidth - diagramMargin) / (attachmentWidth + diagramMargin))
+
+	labels := []string{}
+	for _, g := range attachmentGroups {
+		labels = append(labels, g.label)
+	}
+	labels = append(labels, "Other")
+	for _, label := range labels {
+		var members []*awsrouter.TgwAttachment
+		for _, att := range attachments {
+			if attachmentGroupLabel(att) == label {
+				members = append(members, att)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		rows := (len(members) + perRow - 1) / perRow
+		group := &Group{
+			Label: label,
+			Rect: Rect{
+				X:      x,
+				Y:      y,
+				Width:  areaWidth,
+				Height: float64(groupTitleHeight + rows*(attachmentHeight+diagramMargin)),
+			},
+		}
+		for i, att := range members {
+			text := att.Name
+			if text == "" {
+				text = att.ID
+			}
+			detail := att.ResourceID
+			if c := tgw.Connect(att); c != nil {
+				detail = fmt.Sprintf("BGP %d/%d peers up", c.PeersUp(), len(c.Peers))
+			}
+			node := &Node{
+				ID:     att.ID,
+				Label:  text,
+				Detail: detail,
+				Kind:   NodeAttachment,
+				Rect: Rect{
+					X:      x + diagramMargin + float64((i%perRow)*(attachmentWidth+diagramMargin)),
+					Y:      y + groupTitleHeight + float64((i/perRow)*(attachmentHeight+diagramMargin)),
+					Width:  attachmentWidth,
+					Height: attachmentHeight,
+				},
+			}
+			group.Nodes = append(group.Nodes, node)
+			d.Nodes = append(d.Nodes, node)
+		}
+		d.Groups = append(d.Groups, group)
+		y += group.Height + diagramMargin
+	}
+
+	// Associations and propagations.
+	for _, att := range attachments {
+		if rt := tgw.AssociatedRouteTable(att.ID); rt != nil {
+			d.Edges = append(d.Edges, Edge{From: rt.ID, To: att.ID, Kind: EdgeAssociation})
+		}
+		if pt := tgw.PolicyTable(att); pt != nil {
+			d.Edges = append(d.Edges, Edge{From: pt.ID, To: att.ID, Kind: EdgeAssociation})
+		}
+		for _, rt := range tgw.PropagatingRouteTables(att.ID) {
+			d.Edges = append(d.Edges, Edge{From: att.ID, To: rt.ID, Kind: EdgePropagation})
+		}
+	}
+	for _, c := range tgw.Connects {
+		if d.Node(c.ID) != nil && d.Node(c.TransportAttachmentID) != nil {
+			d.Edges = append(d.Edges, Edge{From: c.ID, To: c.TransportAttachmentID, Kind: EdgeTransport})
+		}
+	}
+
+	// Legend on the right of the TGW.
+	d.Legend = Rect{
+		X:      x + areaWidth + diagramMargin,
+		Y:      diagramMargin,
+		Width:  legendWidth,
+		Height: float64(groupTitleHeight + (len(d.EdgeKinds())+1)*legendRowHeight),
+	}
+	d.Width = d.Legend.X + d.Legend.Width + diagramMargin
+	d.Height = math.Max(y, d.Legend.Y+d.Legend.Height+diagramMargin)
+	return d
+}
@@ -0,0 +1,245 @@
+package draw
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+)
+
+// propagatedRoute returns a propagated route of the attachment att.
+func propagatedRoute(cidr string, att *awsrouter.TgwAttachment) types.TransitGatewayRoute {
+	return types.TransitGatewayRoute{
+		DestinationCidrBlock: aws.String(cidr),
+		State:                "active",
+		Type:                 "propagated",
+		TransitGatewayAttachments: []types.TransitGatewayRouteAttachment{
+			{
+				TransitGatewayAttachmentId: aws.String(att.ID),
+				ResourceId:                 aws.String(att.ResourceID),
+				ResourceType:               types.TransitGatewayAttachmentResourceType(att.Type),
+			},
+		},
+	}
+}
+
+func layoutTgw() awsrouter.Tgw {
+	vpcA := &awsrouter.TgwAttachment{ID: "tgw-attach-0a", Name: "vpc-a", ResourceID: "vpc-0a", Type: "vpc"}
+	vpcB := &awsrouter.TgwAttachment{ID: "tgw-attach-0b", ResourceID: "vpc-0b", Type: "vpc"}
+	vpn := &awsrouter.TgwAttachment{ID: "tgw-attach-0c", Name: "vpn-c", ResourceID: "vpn-0c", Type: "vpn"}
+	dx := &awsrouter.TgwAttachment{ID: "tgw-attach-0d", ResourceID: "dxgw-0d", Type: "direct-connect-gateway"}
+	return awsrouter.Tgw{
+		ID:   "tgw-0a",
+		Name: "layout",
+		RouteTables: []*awsrouter.TgwRouteTable{
+			{
+				ID:          "tgw-rtb-0a",
+				Name:        "prod",
+				Attachments: []*awsrouter.TgwAttachment{vpcA, vpn},
+				Routes: []types.TransitGatewayRoute{
+					propagatedRoute("10.0.0.0/16", vpcA),
+					propagatedRoute("10.1.0.0/16", vpcB),
+					propagatedRoute("192.168.0.0/16", dx),
+				},
+			},
+			{
+				ID:          "tgw-rtb-0b",
+				Name:        "dev",
+				Attachments: []*awsrouter.TgwAttachment{vpcB, dx},
+				Routes: []types.TransitGatewayRoute{
+					propagatedRoute("10.0.0.0/16", vpcA),
+				},
+			},
+		},
+	}
+}
+
+// checkDiagram verifies that no nodes overlap, except route tables inside the TGW, and everything is inside the canvas.
+func checkDiagram(t *testing.T, d *Diagram) {
+	t.Helper()
+	canvas := Rect{Width: d.Width, Height: d.Height}
+	for i, n := range d.Nodes {
+		if !canvas.Contains(n.Rect) {
+			t.Errorf("node %s %v is outside the canvas %v", n.ID, n.Rect, canvas)
+		}
+		if n.Rect.Overlaps(d.Legend) {
+			t.Errorf("node %s overlaps the legend", n.ID)
+		}
+		for _, o := range d.Nodes[i+1:] {
+			if n.Kind == NodeTgw || o.Kind == NodeTgw {
+				continue
+			}
+			if n.Rect.Overlaps(o.Rect) {
+				t.Errorf("node %s overlaps node %s", n.ID, o.ID)
+			}
+		}
+	}
+	for _, g := range d.Groups {
+		for _, n := range g.Nodes {
+			if !g.Rect.Contains(n.Rect) {
+				t.Errorf("node %s is outside the group %s", n.ID, g.Label)
+			}
+		}
+	}
+	if !canvas.Contains(d.Legend) {
+		t.Errorf("legend %v is outside the canvas %v", d.Legend, canvas)
+	}
+	for _, e := range d.Edges {
+		if d.Node(e.From) == nil || d.Node(e.To) == nil {
+			t.Errorf("edge %v has a missing node", e)
+		}
+	}
+}
+
+func TestTgwDiagram(t *testing.T) {
+	d := TgwDiagram(layoutTgw())
+	checkDiagram(t, d)
+
+	var groups []string
+	for _, g := range d.Groups {
+		var ids []string
+		for _, n := range g.Nodes {
+			ids = append(ids, n.ID)
+		}
+		groups = append(groups, fmt.Sprintf("%s:%v", g.Label, ids))
+	}
+	wantGroups := []string{
+		"VPC:[tgw-attach-0a tgw-attach-0b]",
+		"VPN:[tgw-attach-0c]",
+		"Direct Connect:[tgw-attach-0d]",
+	}
+	if !reflect.DeepEqual(groups, wantGroups) {
+		t.Errorf("TgwDiagram() groups = %v, want %v", groups, wantGroups)
+	}
+
+	wantEdges := []Edge{
+		{From: "tgw-rtb-0a", To: "tgw-attach-0a", Kind: EdgeAssociation},
+		{From: "tgw-attach-0a", To: "tgw-rtb-0a", Kind: EdgePropagation},
+		{From: "tgw-attach-0a", To: "tgw-rtb-0b", Kind: EdgePropagation},
+		{From: "tgw-rtb-0b", To: "tgw-attach-0b", Kind: EdgeAssociation},
+		{From: "tgw-attach-0b", To: "tgw-rtb-0a", Kind: EdgePropagation},
+		{From: "tgw-rtb-0a", To: "tgw-attach-0c", Kind: EdgeAssociation},
+		{From: "tgw-rtb-0b", To: "tgw-attach-0d", Kind: EdgeAssociation},
+		{From: "tgw-attach-0d", To: "tgw-rtb-0a", Kind: EdgePropagation},
+	}
+	if !reflect.DeepEqual(d.Edges, wantEdges) {
+		t.Errorf("TgwDiagram() edges = %v, want %v", d.Edges, wantEdges)
+	}
+	if got := d.Node("tgw-attach-0b").Label; got != "tgw-attach-0b" {
+		t.Errorf("TgwDiagram() label of an attachment without name = %v, want the ID", got)
+	}
+	if got, want := d.EdgeKinds(), []EdgeKind{EdgeAssociation, EdgePropagation}; !reflect.DeepEqual(got, want) {
+		t.Errorf("EdgeKinds() = %v, want %v", got, want)
+	}
+}
+
+func TestTgwDiagramConnect(t *testing.T) {
+	tgw := layoutTgw()
+	connect := &awsrouter.TgwAttachment{ID: "tgw-attach-0e", Name: "sdwan", ResourceID: "tgw-attach-0b", Type: "connect"}
+	tgw.RouteTables[1].Attachments = append(tgw.RouteTables[1].Attachments, connect)
+	tgw.Connects = []*awsrouter.TgwConnect{{
+		ID:                    "tgw-attach-0e",
+		TransportAttachmentID: "tgw-attach-0b",
+		Peers: []*awsrouter.TgwConnectPeer{
+			{ID: "tgw-connect-peer-0a", BgpSessions: []awsrouter.BgpSession{{Status: "up"}, {Status: "down"}}},
+			{ID: "tgw-connect-peer-0b", BgpSessions: []awsrouter.BgpSession{{Status: "down"}}},
+		},
+	}}
+	d := TgwDiagram(tgw)
+	checkDiagram(t, d)
+
+	if got, want := d.Node("tgw-attach-0e").Detail, "BGP 1/2 peers up"; got != want {
+		t.Errorf("TgwDiagram() detail of the Connect = %v, want %v", got, want)
+	}
+	want := Edge{From: "tgw-attach-0e", To: "tgw-attach-0b", Kind: EdgeTransport}
+	if got := d.Edges[len(d.Edges)-1]; got != want {
+		t.Errorf("TgwDiagram() last edge = %v, want %v", got, want)
+	}
+	if got, want := d.EdgeKinds(), []EdgeKind{EdgeAssociation, EdgePropagation, EdgeTransport}; !reflect.DeepEqual(got, want) {
+		t.Errorf("EdgeKinds() = %v, want %v", got, want)
+	}
+}
+
+func TestTgwDiagramCloudWan(t *testing.T) {
+	tgw := layoutTgw()
+	cloudWan := &awsrouter.TgwAttachment{ID: "tgw-attach-0w", ResourceID: "core-network-0w", Type: "peering"}
+	tgw.PolicyTables = []*awsrouter.TgwPolicyTable{{ID: "tgw-ptb-0a", Name: "cloudwan", Attachments: []*awsrouter.TgwAttachment{cloudWan}}}
+	d := TgwDiagram(tgw)
+	checkDiagram(t, d)
+
+	if n := d.Node("tgw-ptb-0a"); n == nil || !d.Node("tgw-0a").Rect.Contains(n.Rect) {
+		t.Errorf("TgwDiagram() policy table %v is not inside the TGW", n)
+	}
+	var group string
+	for _, g := range d.Groups {
+		for _, n := range g.Nodes {
+			if n.ID == "tgw-attach-0w" {
+				group = g.Label
+			}
+		}
+	}
+	if group != "Cloud WAN" {
+		t.Errorf("TgwDiagram() group of the Cloud WAN peering = %q, want Cloud WAN", group)
+	}
+	want := Edge{From: "tgw-ptb-0a", To: "tgw-attach-0w", Kind: EdgeAssociation}
+	found := false
+	for _, e := range d.Edges {
+		found = found || e == want
+	}
+	if !found {
+		t.Errorf("TgwDiagram() edges %v do not have %v", d.Edges, want)
+	}
+}
+
+func TestTgwDiagramSize(t *testing.T) {
+	small := TgwDiagram(layoutTgw())
+
+	// A TGW with many route tables and attachments must grow the canvas.
+	big := layoutTgw()
+	for i := 0; i < 20; i++ {
+		big.RouteTables = append(big.RouteTables, &awsrouter.TgwRouteTable{
+			ID:   fmt.Sprintf("tgw-rtb-1%02d", i),
+			Name: fmt.Sprintf("rt-%d", i),
+		})
+	}
+	rt := big.RouteTables[0]
+	for i := 0; i < 100; i++ {
+		rt.Attachments = append(rt.Attachments, &awsrouter.TgwAttachment{
+			ID:   fmt.Sprintf("tgw-attach-1%02d", i),
+			Type: "vpc",
+		})
+	}
+	d := TgwDiagram(big)
+	checkDiagram(t, d)
+	if d.Width <= small.Width || d.Height <= small.Height {
+		t.Errorf("TgwDiagram() size %vx%v did not grow from %vx%v", d.Width, d.Height, small.Width, small.Height)
+	}
+	if got := len(d.Nodes); got != 1+len(big.RouteTables)+104 {
+		t.Errorf("TgwDiagram() has %d nodes, want %d", got, 1+len(big.RouteTables)+104)
+	}
+}
+
+func TestEdgeAnchors(t *testing.T) {
+	top := &Node{Rect: Rect{X: 0, Y: 0, Width: 100, Height: 50}}
+	bottom := &Node{Rect: Rect{X: 200, Y: 100, Width: 100, Height: 50}}
+	tests := []struct {
+		name           string
+		edge           Edge
+		from, to       *Node
+		x1, y1, x2, y2 float64
+	}{
+		{name: "Down", edge: Edge{Kind: EdgeAssociation}, from: top, to: bottom, x1: 40, y1: 50, x2: 240, y2: 100},
+		{name: "Up", edge: Edge{Kind: EdgePropagation}, from: bottom, to: top, x1: 260, y1: 100, x2: 60, y2: 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x1, y1, x2, y2 := tt.edge.Anchors(tt.from, tt.to)
+			if x1 != tt.x1 || y1 != tt.y1 || x2 != tt.x2 || y2 != tt.y2 {
+				t.Errorf("Anchors() = %v %v %v %v, want %v %v %v %v", x1, y1, x2, y2, tt.x1, tt.y1, tt.x2, tt.y2)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package draw
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// mermaidText returns s escaped for a quoted Mermaid label.
+func mermaidText(s string) string {
+	r := strings.NewReplacer(`"`, "#quot;", "\n", "<br/>")
+	return `"` + r.Replace(s) + `"`
+}
+
+// mermaidArrows are the links used for each EdgeKind.
+var mermaidArrows = map[EdgeKind]string{
+	EdgeAssociation: "---",
+	EdgePropagation: "-.-",
+	EdgeTraffic:     "==>",
+	EdgeEcmp:        "-.->",
+	EdgeTransport:   "-. transport .-",
+}
+
+// mermaidClasses are the classes used for the nodes with a background color.
+var mermaidClasses = map[NodeKind]string{
+	NodeEndpoint: "endpoint",
+	NodeDrop:     "drop",
+}
+
+// WriteMermaid writes the diagram as a Mermaid flowchart.
+// The TGW and the groups are subgraphs, the nodes are named n0, n1... in the order of the diagram,
+// because the IDs of AWS resources are not always valid Mermaid IDs.
+func WriteMermaid(w io.Writer, d *Diagram) error {
+	var b strings.Builder
+	direction := "TB"
+	if d.LeftToRight {
+		direction = "LR"
+	}
+	// The title goes in the YAML front matter, that uses the same escaping as DOT.
+	fmt.Fprintf(&b, "---\ntitle: %s\n---\n", dotQuote(d.Title))
+	fmt.Fprintf(&b, "flowchart %s\n", direction)
+
+	ids := make(map[string]string, len(d.Nodes))
+	for i, n := range d.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+	}
+	written := make(map[*Node]bool)
+	writeNode := func(n *Node, indent string) {
+		fmt.Fprintf(&b, "%s%s[%s]\n", indent, ids[n.ID], mermaidText(nodeText(n)))
+		written[n] = true
+	}
+	for _, n := range d.Nodes {
+		if n.Kind != NodeTgw {
+			continue
+		}
+		written[n] = true
+		fmt.Fprintf(&b, "  subgraph %s [%s]\n", ids[n.ID], mermaidText(n.Label))
+		for _, c := range d.Children(n) {
+			writeNode(c, "    ")
+		}
+		b.WriteString("  end\n")
+	}
+	for i, g := range d.Groups {
+		fmt.Fprintf(&b, "  subgraph g%d [%s]\n", i, mermaidText(g.Label))
+		for _, n := range g.Nodes {
+			writeNode(n, "    ")
+		}
+		b.WriteString("  end\n")
+	}
+	for _, n := range d.Nodes {
+		if !written[n] {
+			writeNode(n, "  ")
+		}
+	}
+	for _, e := range d.Edges {
+		from, okFrom := ids[e.From]
+		to, okTo := ids[e.To]
+		if !okFrom || !okTo {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", from, mermaidArrows[e.Kind], to)
+	}
+
+	// Colors, in the same order as the NodeKind constants.
+	for _, kind := range []NodeKind{NodeEndpoint, NodeDrop} {
+		var members []string
+		for _, n := range d.Nodes {
+			if n.Kind == kind {
+				members = append(members, ids[n.ID])
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "  classDef %s fill:%s\n", mermaidClasses[kind], svgColor(nodeColors[kind]))
+		fmt.Fprintf(&b, "  class %s %s\n", strings.Join(members, ","), mermaidClasses[kind])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
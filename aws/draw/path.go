@@ -0,0 +1,181 @@
+package draw
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+)
+
+const (
+	// pathNodeWidth is the width of the boxes in a path diagram.
+	pathNodeWidth = 240
+	// pathNodeHeight is the height of the boxes in a path diagram.
+	pathNodeHeight = 70
+	// pathGap is the horizontal space between the columns of a path diagram, used by the arrows.
+	pathGap = 90
+)
+
+// pathLayout places the nodes of a path diagram in columns from left to right.
+// The main row has the path, the ECMP next hops not followed are below it in the same column.
+type pathLayout struct {
+	d     *Diagram
+	group *Group
+	col   int
+	rows  int
+	// last is the ID of the last node in the main row.
+	last string
+}
+
+// add places a node in the given column and row.
+// The node is added to the TGW group if inGroup is true.
+func (l *pathLayout) add(id, label, detail string, kind NodeKind, col, row int, inGroup bool) {
+	n := &Node{
+		ID:     id,
+		Label:  label,
+		Detail: detail,
+		Kind:   kind,
+		Rect: Rect{
+			X:      diagramMargin + float64(col*(pathNodeWidth+pathGap)),
+			Y:      diagramMargin + groupTitleHeight + float64(row*(pathNodeHeight+diagramMargin)),
+			Width:  pathNodeWidth,
+			Height: pathNodeHeight,
+		},
+	}
+	l.d.Nodes = append(l.d.Nodes, n)
+	if inGroup {
+		l.group.Nodes = append(l.group.Nodes, n)
+	}
+	if row+1 > l.rows {
+		l.rows = row + 1
+	}
+}
+
+// next adds a node in the main row of a new column, with an arrow from the last node of the main row.
+func (l *pathLayout) next(id, label, detail string, kind NodeKind, inGroup bool) {
+	l.add(id, label, detail, kind, l.col, 0, inGroup)
+	if l.last != "" {
+		l.d.Edges = append(l.d.Edges, Edge{From: l.last, To: id, Kind: EdgeTraffic})
+	}
+	l.last = id
+	l.col++
+}
+
+// attachmentLabel returns the name of the attachment, or the ID if it has no name.
+func attachmentLabel(att *awsrouter.TgwAttachment) string {
+	if att.Name != "" {
+		return att.Name
+	}
+	return att.ID
+}
+
+// dropDetail returns the reason of the drop for the error returned by AttPath.Walk.
+func dropDetail(dst string, walkErr error) (string, string) {
+	switch {
+	case errors.Is(walkErr, awsrouter.ErrTgwRouteBlackhole):
+		return "Drop", "blackhole route"
+	case errors.Is(walkErr, awsrouter.ErrTgwRouteTableRouteNotFound):
+		return "Drop", "no route to " + dst
+	case errors.Is(walkErr, awsrouter.ErrTgwVpnDown):
+		return "Drop", "VPN tunnels down"
+	}
+	return "Stopped", walkErr.Error()
+}
+
+// PathDiagram builds the diagram of a path walk from src to dst.
+// The path goes from left to right: the source IP, the source attachment, each route table consulted with the matched prefix,
+// the next hop attachment and the destination IP. The route tables and attachments are inside a box of the TGW.
+// ECMP next hops not followed by the walk are drawn below the one followed.
+// walkErr is the error returned by AttPath.Walk, if it is not nil the path ends in a drop node with the reason,
+// or in a Cloud WAN node outside the TGW box when the traffic leaves to a Cloud WAN core network.
+func PathDiagram(attPath *awsrouter.AttPath, src, dst string, walkErr error) *Diagram {
+	var tgwName string
+	if attPath.Tgw != nil {
+		tgwName = attPath.Tgw.Name
+	}
+	d := &Diagram{Title: fmt.Sprintf("%s: %s -> %s", tgwName, src, dst), LeftToRight: true}
+	l := &pathLayout{d: d, group: &Group{Label: "Transit Gateway " + tgwName}}
+
+	l.next("src", src, "source", NodeEndpoint, false)
+	firstCol := l.col
+	if len(attPath.Path) > 0 {
+		att := attPath.Path[0]
+		l.next(att.ID, attachmentLabel(att), att.ResourceID, NodeAttachment, true)
+	}
+	for i, hop := range attPath.Hops {
+		name := hop.RouteTableName
+		if name == "" {
+			name = hop.RouteTableID
+		}
+		prefix := hop.Prefix
+		if prefix == "" {
+			prefix = "no route"
+		}
+		l.next(fmt.Sprintf("hop-%d", i), name, prefix, NodeRouteTable, true)
+		if hop.Dropped() && !hop.VpnDown {
+			// The drop node is added below, after the VPN for a VPN that is down.
+			continue
+		}
+		rt, col := l.last, l.col
+		main := hop.NextHops[0]
+		if d.Node(main.ID) == nil {
+			l.next(main.ID, attachmentLabel(main), main.ResourceID, NodeAttachment, true)
+		} else {
+			// The next hop is already in the path when the walk reaches the destination.
+			d.Edges = append(d.Edges, Edge{From: rt, To: main.ID, Kind: EdgeTraffic})
+		}
+		for j, att := range hop.NextHops[1:] {
+			id := fmt.Sprintf("hop-%d-ecmp-%d", i, j+1)
+			l.add(id, attachmentLabel(att), att.ResourceID, NodeAttachment, col, j+1, true)
+			d.Edges = append(d.Edges, Edge{From: rt, To: id, Kind: EdgeEcmp})
+		}
+		if l.col == col && len(hop.NextHops) > 1 {
+			l.col++
+		}
+	}
+	cloudWan := errors.Is(walkErr, awsrouter.ErrTgwCloudWan)
+	if walkErr != nil && !cloudWan {
+		label, detail := dropDetail(dst, walkErr)
+		l.next("drop", label, detail, NodeDrop, true)
+	}
+	lastGroupCol := l.col - 1
+	switch {
+	case cloudWan:
+		// The routing continues in the core network, the peering before it shows the core network.
+		detail := "core network"
+		if len(attPath.Path) > 0 && attPath.Tgw != nil {
+			if pt := attPath.Tgw.PolicyTable(attPath.Path[len(attPath.Path)-1]); pt != nil {
+				detail = "via policy table " + pt.Name
+			}
+		}
+		l.next("cloud-wan", "Cloud WAN", detail, NodeEndpoint, false)
+	case walkErr == nil:
+		l.next("dst", dst, "destination", NodeEndpoint, false)
+	}
+
+	// The TGW box goes around the columns between the endpoints.
+	if len(l.group.Nodes) > 0 {
+		l.group.Rect = Rect{
+			X:      diagramMargin + float64(firstCol*(pathNodeWidth+pathGap)) - pathGap/2,
+			Y:      diagramMargin,
+			Width:  float64((lastGroupCol - firstCol + 1) * (pathNodeWidth + pathGap)),
+			Height: float64(groupTitleHeight + l.rows*(pathNodeHeight+diagramMargin)),
+		}
+		d.Groups = append(d.Groups, l.group)
+	}
+
+	right := diagramMargin + float64(l.col*(pathNodeWidth+pathGap)) - pathGap
+	d.Legend = Rect{
+		X:      right + pathGap,
+		Y:      diagramMargin,
+		Width:  legendWidth,
+		Height: float64(groupTitleHeight + (len(d.EdgeKinds())+1)*legendRowHeight),
+	}
+	d.Width = d.Legend.X + d.Legend.Width + diagramMargin
+	d.Height = math.Max(
+		diagramMargin+float64(groupTitleHeight+l.rows*(pathNodeHeight+diagramMargin))+diagramMargin,
+		d.Legend.Y+d.Legend.Height+diagramMargin,
+	)
+	return d
+}
@@ -0,0 +1,182 @@
+package draw
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+)
+
+func TestPathDiagram(t *testing.T) {
+	vpcA := &awsrouter.TgwAttachment{ID: "tgw-attach-0a", Name: "vpc-a", ResourceID: "vpc-0a", Type: "vpc"}
+	vpcB := &awsrouter.TgwAttachment{ID: "tgw-attach-0b", ResourceID: "vpc-0b", Type: "vpc"}
+	vpcC := &awsrouter.TgwAttachment{ID: "tgw-attach-0c", ResourceID: "vpc-0c", Type: "vpc"}
+	cloudWan := &awsrouter.TgwAttachment{ID: "tgw-attach-0w", ResourceID: "core-network-0w", Type: "peering"}
+	tgw := &awsrouter.Tgw{ID: "tgw-0a", Name: "layout"}
+	tests := []struct {
+		name    string
+		attPath *awsrouter.AttPath
+		walkErr error
+		want    []string
+		edges   []Edge
+	}{
+		{
+			name: "Reached",
+			attPath: &awsrouter.AttPath{
+				Tgw:  tgw,
+				Path: []*awsrouter.TgwAttachment{vpcA, vpcB},
+				Hops: []awsrouter.PathHop{
+					{RouteTableID: "tgw-rtb-0a", RouteTableName: "prod", Prefix: "10.1.0.0/16", NextHops: []*awsrouter.TgwAttachment{vpcB}},
+					{RouteTableID: "tgw-rtb-0b", Prefix: "10.1.0.0/16", NextHops: []*awsrouter.TgwAttachment{vpcB}},
+				},
+			},
+			want: []string{
+				"src:10.0.0.1/source",
+				"tgw-attach-0a:vpc-a/vpc-0a",
+				"hop-0:prod/10.1.0.0/16",
+				"tgw-attach-0b:tgw-attach-0b/vpc-0b",
+				"hop-1:tgw-rtb-0b/10.1.0.0/16",
+				"dst:10.1.0.1/destination",
+			},
+			edges: []Edge{
+				{From: "src", To: "tgw-attach-0a", Kind: EdgeTraffic},
+				{From: "tgw-attach-0a", To: "hop-0", Kind: EdgeTraffic},
+				{From: "hop-0", To: "tgw-attach-0b", Kind: EdgeTraffic},
+				{From: "tgw-attach-0b", To: "hop-1", Kind: EdgeTraffic},
+				{From: "hop-1", To: "tgw-attach-0b", Kind: EdgeTraffic},
+				{From: "hop-1", To: "dst", Kind: EdgeTraffic},
+			},
+		},
+		{
+			name: "ECMP",
+			attPath: &awsrouter.AttPath{
+				Tgw:  tgw,
+				Path: []*awsrouter.TgwAttachment{vpcA, vpcB},
+				Hops: []awsrouter.PathHop{
+					{RouteTableID: "tgw-rtb-0a", Prefix: "10.1.0.0/16", NextHops: []*awsrouter.TgwAttachment{vpcB, vpcC}},
+				},
+			},
+			want: []string{
+				"src:10.0.0.1/source",
+				"tgw-attach-0a:vpc-a/vpc-0a",
+				"hop-0:tgw-rtb-0a/10.1.0.0/16",
+				"tgw-attach-0b:tgw-attach-0b/vpc-0b",
+				"hop-0-ecmp-1:tgw-attach-0c/vpc-0c",
+				"dst:10.1.0.1/destination",
+			},
+			edges: []Edge{
+				{From: "src", To: "tgw-attach-0a", Kind: EdgeTraffic},
+				{From: "tgw-attach-0a", To: "hop-0", Kind: EdgeTraffic},
+				{From: "hop-0", To: "tgw-attach-0b", Kind: EdgeTraffic},
+				{From: "hop-0", To: "hop-0-ecmp-1", Kind: EdgeEcmp},
+				{From: "tgw-attach-0b", To: "dst", Kind: EdgeTraffic},
+			},
+		},
+		{
+			name: "Blackhole",
+			attPath: &awsrouter.AttPath{
+				Tgw:  tgw,
+				Path: []*awsrouter.TgwAttachment{vpcA},
+				Hops: []awsrouter.PathHop{
+					{RouteTableID: "tgw-rtb-0a", Prefix: "10.1.0.0/16", Blackhole: true},
+				},
+			},
+			walkErr: awsrouter.ErrTgwRouteBlackhole,
+			want: []string{
+				"src:10.0.0.1/source",
+				"tgw-attach-0a:vpc-a/vpc-0a",
+				"hop-0:tgw-rtb-0a/10.1.0.0/16",
+				"drop:Drop/blackhole route",
+			},
+			edges: []Edge{
+				{From: "src", To: "tgw-attach-0a", Kind: EdgeTraffic},
+				{From: "tgw-attach-0a", To: "hop-0", Kind: EdgeTraffic},
+				{From: "hop-0", To: "drop", Kind: EdgeTraffic},
+			},
+		},
+		{
+			name: "NoRoute",
+			attPath: &awsrouter.AttPath{
+				Tgw:  tgw,
+				Path: []*awsrouter.TgwAttachment{vpcA},
+				Hops: []awsrouter.PathHop{{RouteTableID: "tgw-rtb-0a"}},
+			},
+			walkErr: fmt.Errorf("walk: %w", awsrouter.ErrTgwRouteTableRouteNotFound),
+			want: []string{
+				"src:10.0.0.1/source",
+				"tgw-attach-0a:vpc-a/vpc-0a",
+				"hop-0:tgw-rtb-0a/no route",
+				"drop:Drop/no route to 10.1.0.1",
+			},
+			edges: []Edge{
+				{From: "src", To: "tgw-attach-0a", Kind: EdgeTraffic},
+				{From: "tgw-attach-0a", To: "hop-0", Kind: EdgeTraffic},
+				{From: "hop-0", To: "drop", Kind: EdgeTraffic},
+			},
+		},
+		{
+			name: "CloudWan",
+			attPath: &awsrouter.AttPath{
+				Tgw: &awsrouter.Tgw{
+					ID:           "tgw-0a",
+					Name:         "layout",
+					PolicyTables: []*awsrouter.TgwPolicyTable{{ID: "tgw-ptb-0a", Name: "cloudwan", Attachments: []*awsrouter.TgwAttachment{cloudWan}}},
+				},
+				Path: []*awsrouter.TgwAttachment{vpcA, cloudWan},
+				Hops: []awsrouter.PathHop{
+					{RouteTableID: "tgw-rtb-0a", Prefix: "10.1.0.0/16", NextHops: []*awsrouter.TgwAttachment{cloudWan}},
+				},
+			},
+			walkErr: fmt.Errorf("%w core-network-0w via policy table cloudwan", awsrouter.ErrTgwCloudWan),
+			want: []string{
+				"src:10.0.0.1/source",
+				"tgw-attach-0a:vpc-a/vpc-0a",
+				"hop-0:tgw-rtb-0a/10.1.0.0/16",
+				"tgw-attach-0w:tgw-attach-0w/core-network-0w",
+				"cloud-wan:Cloud WAN/via policy table cloudwan",
+			},
+			edges: []Edge{
+				{From: "src", To: "tgw-attach-0a", Kind: EdgeTraffic},
+				{From: "tgw-attach-0a", To: "hop-0", Kind: EdgeTraffic},
+				{From: "hop-0", To: "tgw-attach-0w", Kind: EdgeTraffic},
+				{From: "tgw-attach-0w", To: "cloud-wan", Kind: EdgeTraffic},
+			},
+		},
+		{
+			name:    "NoSource",
+			attPath: awsrouter.NewAttPath(),
+			walkErr: fmt.Errorf("error finding the attachment"),
+			want: []string{
+				"src:10.0.0.1/source",
+				"drop:Stopped/error finding the attachment",
+			},
+			edges: []Edge{
+				{From: "src", To: "drop", Kind: EdgeTraffic},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := PathDiagram(tt.attPath, "10.0.0.1", "10.1.0.1", tt.walkErr)
+			checkDiagram(t, d)
+			var got []string
+			for _, n := range d.Nodes {
+				got = append(got, fmt.Sprintf("%s:%s/%s", n.ID, n.Label, n.Detail))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PathDiagram() nodes = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(d.Edges, tt.edges) {
+				t.Errorf("PathDiagram() edges = %v, want %v", d.Edges, tt.edges)
+			}
+			for _, g := range d.Groups {
+				for _, id := range []string{"src", "dst", "cloud-wan"} {
+					if n := d.Node(id); n != nil && g.Rect.Overlaps(n.Rect) {
+						t.Errorf("PathDiagram() endpoint %s is inside the group %s", id, g.Label)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package draw
+
+import (
+	"image/color"
+	"io"
+	"math"
+	"os"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+var (
+	// groupColor is the background of the group boxes.
+	groupColor = color.RGBA{R: 0xF2, G: 0xF2, B: 0xF2, A: 0xFF}
+	// edgeColors are the colors of the lines for each EdgeKind.
+	edgeColors = map[EdgeKind]color.Color{
+		EdgeAssociation: color.RGBA{R: 0x1F, G: 0x4E, B: 0x78, A: 0xFF},
+		EdgePropagation: color.RGBA{R: 0x38, G: 0x8E, B: 0x3C, A: 0xFF},
+		EdgeTraffic:     color.Black,
+		EdgeEcmp:        color.RGBA{R: 0xE6, G: 0x7E, B: 0x22, A: 0xFF},
+		EdgeTransport:   color.RGBA{R: 0x7B, G: 0x1F, B: 0xA2, A: 0xFF},
+	}
+	// nodeColors are the background of the boxes for each NodeKind, white if not set.
+	nodeColors = map[NodeKind]color.Color{
+		NodeEndpoint: color.RGBA{R: 0xDD, G: 0xEB, B: 0xF7, A: 0xFF},
+		NodeDrop:     color.RGBA{R: 0xF8, G: 0xCB, B: 0xCB, A: 0xFF},
+	}
+)
+
+// arrowSize is the length of the arrow head of the edges with direction.
+const arrowSize = 12
+
+// newFace returns a font face of the Go regular font with the given size.
+func newFace(size float64) (font.Face, error) {
+	f, err := truetype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, err
+	}
+	return truetype.NewFace(f, &truetype.Options{Size: size}), nil
+}
+
+// fitString shortens s until it fits in width with the current font of dc.
+func fitString(dc *gg.Context, s string, width float64) string {
+	if w, _ := dc.MeasureString(s); w <= width {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 {
+		r = r[:len(r)-1]
+		if w, _ := dc.MeasureString(string(r) + "..."); w <= width {
+			break
+		}
+	}
+	return string(r) + "..."
+}
+
+// setEdgeStyle sets the color and dash of the lines of an EdgeKind.
+func setEdgeStyle(dc *gg.Context, kind EdgeKind) {
+	dc.SetColor(edgeColors[kind])
+	dc.SetLineWidth(2)
+	switch kind {
+	case EdgePropagation, EdgeEcmp:
+		dc.SetDash(10, 6)
+	case EdgeTransport:
+		dc.SetDash(3, 5)
+	default:
+		dc.SetDash()
+	}
+}
+
+// drawArrowHead draws the head of an arrow that ends in x2, y2.
+func drawArrowHead(dc *gg.Context, x1, y1, x2, y2 float64) {
+	angle := math.Atan2(y2-y1, x2-x1)
+	dc.SetDash()
+	dc.MoveTo(x2, y2)
+	dc.LineTo(x2-arrowSize*math.Cos(angle-math.Pi/6), y2-arrowSize*math.Sin(angle-math.Pi/6))
+	dc.LineTo(x2-arrowSize*math.Cos(angle+math.Pi/6), y2-arrowSize*math.Sin(angle+math.Pi/6))
+	dc.ClosePath()
+	dc.Fill()
+}
+
+// drawBox draws a node as a box with its label and detail centered.
+func drawBox(dc *gg.Context, n *Node, face, small font.Face) {
+	dc.SetDash()
+	dc.SetLineWidth(2)
+	dc.DrawRectangle(n.X, n.Y, n.Width, n.Height)
+	if c, ok := nodeColors[n.Kind]; ok {
+		dc.SetColor(c)
+	} else {
+		dc.SetColor(color.White)
+	}
+	dc.FillPreserve()
+	dc.SetColor(color.Black)
+	dc.Stroke()
+	textWidth := n.Width - 10
+	dc.SetFontFace(face)
+	if n.Detail == "" || n.Detail == n.Label {
+		dc.DrawStringAnchored(fitString(dc, n.Label, textWidth), n.X+n.Width/2, n.Y+n.Height/2, 0.5, 0.5)
+		return
+	}
+	dc.DrawStringAnchored(fitString(dc, n.Label, textWidth), n.X+n.Width/2, n.Y+n.Height/3, 0.5, 0.5)
+	dc.SetFontFace(small)
+	dc.DrawStringAnchored(fitString(dc, n.Detail, textWidth), n.X+n.Width/2, n.Y+2*n.Height/3, 0.5, 0.5)
+}
+
+// RenderPNG draws the diagram and saves it as a PNG in fileName.
+func RenderPNG(d *Diagram, fileName string) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	if err := WritePNG(f, d); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WritePNG draws the diagram as a PNG in w.
+func WritePNG(w io.Writer, d *Diagram) error {
+	dc := gg.NewContext(int(d.Width), int(d.Height))
+	dc.SetColor(color.White)
+	dc.Clear()
+
+	title, err := newFace(30)
+	if err != nil {
+		return err
+	}
+	face, err := newFace(15)
+	if err != nil {
+		return err
+	}
+	small, err := newFace(12)
+	if err != nil {
+		return err
+	}
+
+	// Groups
+	for _, g := range d.Groups {
+		dc.DrawRectangle(g.X, g.Y, g.Width, g.Height)
+		dc.SetColor(groupColor)
+		dc.Fill()
+		dc.SetColor(color.Black)
+		dc.SetFontFace(face)
+		dc.DrawStringAnchored(g.Label, g.X+10, g.Y+groupTitleHeight/2, 0, 0.5)
+	}
+
+	// The TGW is an outline with the title, the route tables are drawn inside.
+	for _, n := range d.Nodes {
+		if n.Kind != NodeTgw {
+			continue
+		}
+		dc.SetDash()
+		dc.SetLineWidth(2)
+		dc.SetColor(color.Black)
+		dc.DrawRectangle(n.X, n.Y, n.Width, n.Height)
+		dc.Stroke()
+		dc.SetFontFace(title)
+		dc.DrawStringAnchored(fitString(dc, n.Label, n.Width-20), n.X+10, n.Y+titleHeight/2, 0, 0.5)
+	}
+
+	// Edges are drawn before the boxes, so the boxes hide the end of the lines.
+	for _, e := range d.Edges {
+		from, to := d.Node(e.From), d.Node(e.To)
+		if from == nil || to == nil {
+			continue
+		}
+		x1, y1, x2, y2 := e.Anchors(from, to)
+		setEdgeStyle(dc, e.Kind)
+		dc.DrawLine(x1, y1, x2, y2)
+		dc.Stroke()
+		if e.Kind == EdgeTraffic || e.Kind == EdgeEcmp {
+			drawArrowHead(dc, x1, y1, x2, y2)
+		}
+	}
+
+	for _, n := range d.Nodes {
+		if n.Kind != NodeTgw {
+			drawBox(dc, n, face, small)
+		}
+	}
+
+	// Legend
+	l := d.Legend
+	dc.SetDash()
+	dc.SetLineWidth(1)
+	dc.SetColor(color.Black)
+	dc.DrawRectangle(l.X, l.Y, l.Width, l.Height)
+	dc.Stroke()
+	dc.SetFontFace(face)
+	dc.DrawStringAnchored("Legend", l.X+10, l.Y+groupTitleHeight/2, 0, 0.5)
+	for i, kind := range d.EdgeKinds() {
+		y := l.Y + groupTitleHeight + float64(i)*legendRowHeight + legendRowHeight/2
+		setEdgeStyle(dc, kind)
+		dc.DrawLine(l.X+10, y, l.X+70, y)
+		dc.Stroke()
+		dc.SetColor(color.Black)
+		dc.DrawStringAnchored(kind.String(), l.X+80, y, 0, 0.5)
+	}
+	return dc.EncodePNG(w)
+}
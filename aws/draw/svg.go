@@ -0,0 +1,112 @@
+package draw
+
+import (
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// svgCharWidth is the approximate width of a character relative to the font size, used to shorten long labels.
+const svgCharWidth = 0.6
+
+// svgColor returns the color in the #rrggbb notation.
+func svgColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// svgText returns s escaped and shortened to fit in width with the font size.
+func svgText(s string, width, size float64) string {
+	max := int(width / (size * svgCharWidth))
+	if r := []rune(s); len(r) > max && max > 3 {
+		s = string(r[:max-3]) + "..."
+	}
+	return html.EscapeString(s)
+}
+
+// svgEdgeStyle returns the attributes of the lines of an EdgeKind.
+func svgEdgeStyle(kind EdgeKind) string {
+	style := fmt.Sprintf(`stroke="%s" stroke-width="2"`, svgColor(edgeColors[kind]))
+	switch kind {
+	case EdgePropagation, EdgeEcmp:
+		style += ` stroke-dasharray="10,6"`
+	case EdgeTransport:
+		style += ` stroke-dasharray="3,5"`
+	}
+	if kind == EdgeTraffic || kind == EdgeEcmp {
+		style += fmt.Sprintf(` marker-end="url(#arrow-%d)"`, kind)
+	}
+	return style
+}
+
+// WriteSVG writes the diagram as an SVG image, with the same layout as RenderPNG.
+func WriteSVG(w io.Writer, d *Diagram) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g" font-family="sans-serif">`+"\n",
+		d.Width, d.Height, d.Width, d.Height)
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(d.Title))
+	b.WriteString("<defs>\n")
+	for _, kind := range []EdgeKind{EdgeTraffic, EdgeEcmp} {
+		fmt.Fprintf(&b, `<marker id="arrow-%d" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto"><path d="M0,0 L10,5 L0,10 z" fill="%s"/></marker>`+"\n",
+			kind, svgColor(edgeColors[kind]))
+	}
+	b.WriteString("</defs>\n")
+	fmt.Fprintf(&b, `<rect width="%g" height="%g" fill="#ffffff"/>`+"\n", d.Width, d.Height)
+
+	for _, g := range d.Groups {
+		fmt.Fprintf(&b, `<rect x="%g" y="%g" width="%g" height="%g" fill="%s"/>`+"\n", g.X, g.Y, g.Width, g.Height, svgColor(groupColor))
+		fmt.Fprintf(&b, `<text x="%g" y="%g" font-size="15" dominant-baseline="middle">%s</text>`+"\n",
+			g.X+10, g.Y+groupTitleHeight/2, svgText(g.Label, g.Width-20, 15))
+	}
+	for _, n := range d.Nodes {
+		if n.Kind != NodeTgw {
+			continue
+		}
+		fmt.Fprintf(&b, `<rect x="%g" y="%g" width="%g" height="%g" fill="none" stroke="#000000" stroke-width="2"/>`+"\n", n.X, n.Y, n.Width, n.Height)
+		fmt.Fprintf(&b, `<text x="%g" y="%g" font-size="30" dominant-baseline="middle">%s</text>`+"\n",
+			n.X+10, n.Y+titleHeight/2, svgText(n.Label, n.Width-20, 30))
+	}
+	for _, e := range d.Edges {
+		from, to := d.Node(e.From), d.Node(e.To)
+		if from == nil || to == nil {
+			continue
+		}
+		x1, y1, x2, y2 := e.Anchors(from, to)
+		fmt.Fprintf(&b, `<line x1="%g" y1="%g" x2="%g" y2="%g" %s/>`+"\n", x1, y1, x2, y2, svgEdgeStyle(e.Kind))
+	}
+	for _, n := range d.Nodes {
+		if n.Kind == NodeTgw {
+			continue
+		}
+		fill := color.Color(color.White)
+		if c, ok := nodeColors[n.Kind]; ok {
+			fill = c
+		}
+		fmt.Fprintf(&b, `<rect x="%g" y="%g" width="%g" height="%g" fill="%s" stroke="#000000" stroke-width="2"/>`+"\n",
+			n.X, n.Y, n.Width, n.Height, svgColor(fill))
+		cx := n.X + n.Width/2
+		if n.Detail == "" || n.Detail == n.Label {
+			fmt.Fprintf(&b, `<text x="%g" y="%g" font-size="15" text-anchor="middle" dominant-baseline="middle">%s</text>`+"\n",
+				cx, n.Y+n.Height/2, svgText(n.Label, n.Width-10, 15))
+			continue
+		}
+		fmt.Fprintf(&b, `<text x="%g" y="%g" font-size="15" text-anchor="middle" dominant-baseline="middle">%s</text>`+"\n",
+			cx, n.Y+n.Height/3, svgText(n.Label, n.Width-10, 15))
+		fmt.Fprintf(&b, `<text x="%g" y="%g" font-size="12" text-anchor="middle" dominant-baseline="middle">%s</text>`+"\n",
+			cx, n.Y+2*n.Height/3, svgText(n.Detail, n.Width-10, 12))
+	}
+
+	l := d.Legend
+	fmt.Fprintf(&b, `<rect x="%g" y="%g" width="%g" height="%g" fill="none" stroke="#000000" stroke-width="1"/>`+"\n", l.X, l.Y, l.Width, l.Height)
+	fmt.Fprintf(&b, `<text x="%g" y="%g" font-size="15" dominant-baseline="middle">Legend</text>`+"\n", l.X+10, l.Y+groupTitleHeight/2)
+	for i, kind := range d.EdgeKinds() {
+		y := l.Y + groupTitleHeight + float64(i)*legendRowHeight + legendRowHeight/2
+		fmt.Fprintf(&b, `<line x1="%g" y1="%g" x2="%g" y2="%g" %s/>`+"\n", l.X+10, y, l.X+70, y, svgEdgeStyle(kind))
+		fmt.Fprintf(&b, `<text x="%g" y="%g" font-size="15" dominant-baseline="middle">%s</text>`+"\n", l.X+80, y, kind)
+	}
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
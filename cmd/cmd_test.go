@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rogerscuall/aws-router/internal/fakeec2"
+	"github.com/rogerscuall/aws-router/internal/output"
+)
+
+// execute runs the command line args against a fake EC2 API of the topology in fileName and decodes
+// the result printed with -o json in v.
+func execute(t *testing.T, fileName string, v interface{}, args ...string) {
+	t.Helper()
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_CONFIG_FILE", "testdata/missing")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "testdata/missing")
+	topology, err := fakeec2.LoadTopology(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := fakeec2.Start(topology)
+	defer srv.Close()
+
+	var b bytes.Buffer
+	stdout = &b
+	defer func() { stdout = os.Stdout }()
+	rootCmd.SetArgs(append(args, "--endpoint", srv.URL, "-o", "json"))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("%s: %v", strings.Join(args, " "), err)
+	}
+	if err := json.Unmarshal(b.Bytes(), v); err != nil {
+		t.Fatalf("%s: error decoding %q: %v", strings.Join(args, " "), b.String(), err)
+	}
+}
+
+func TestCsvCmd(t *testing.T) {
+	folder := t.TempDir()
+	var got []output.Export
+	execute(t, "../internal/fakeec2/testdata/topology.yaml", &got, "csv", "--folder", folder)
+	want := []output.Export{
+		{TgwID: "tgw-0a", TgwName: "core", Kind: "csv", Location: filepath.Join(folder, "core_spokes.csv")},
+		{TgwID: "tgw-0a", TgwName: "core", Kind: "csv", Location: filepath.Join(folder, "core_shared.csv")},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("csv mismatch (-want +got):\n%s", diff)
+	}
+	data, err := os.ReadFile(filepath.Join(folder, "core_spokes.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "10.1.2.0/24") {
+		t.Errorf("core_spokes.csv = %s, want the route to 10.1.2.0/24", data)
+	}
+}
+
+func TestPathCmd(t *testing.T) {
+	var got []output.Path
+	execute(t, "../internal/fakeec2/testdata/topology.yaml", &got, "path", "10.1.0.10", "10.0.0.10")
+	if len(got) != 1 {
+		t.Fatalf("path = %+v, want the path of one Transit Gateway", got)
+	}
+	var hops []string
+	for _, hop := range got[0].Hops {
+		hops = append(hops, hop.ID)
+	}
+	if diff := cmp.Diff([]string{"tgw-attach-0b", "tgw-attach-0a"}, hops); diff != "" || got[0].Error != "" {
+		t.Errorf("path hops mismatch (-want +got):\n%s, error %q", diff, got[0].Error)
+	}
+}
+
+func TestExplainCmd(t *testing.T) {
+	var got output.Explanation
+	execute(t, "../internal/fakeec2/testdata/topology.yaml", &got, "explain", "spokes", "10.1.2.10")
+	if got.TgwName != "core" || got.RouteTableID != "tgw-rtb-0a" || got.Route == nil || got.Route.Destination != "10.1.2.0/24" {
+		t.Errorf("explain = %+v, want the route to 10.1.2.0/24 of spokes", got)
+	}
+	if len(got.Candidates) != 2 || len(got.Reasons) == 0 {
+		t.Errorf("explain candidates = %+v, reasons = %v, want 10.1.2.0/24 and 10.1.0.0/16 with the reasons", got.Candidates, got.Reasons)
+	}
+}
+
+func TestExportTerraformCmd(t *testing.T) {
+	folder := t.TempDir()
+	var got []output.Export
+	execute(t, "testdata/same_name.yaml", &got, "export", "terraform", "--folder", folder)
+	want := []output.Export{
+		{TgwID: "tgw-0a", TgwName: "core", Kind: "terraform", Location: filepath.Join(folder, "core.tf")},
+		{TgwID: "tgw-0b", TgwName: "core", Kind: "terraform", Location: filepath.Join(folder, "core (2).tf")},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("export terraform mismatch (-want +got):\n%s", diff)
+	}
+	for _, export := range got {
+		data, err := os.ReadFile(export.Location)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := `"core_` + export.TgwID + `_spokes"`
+		if !strings.Contains(string(data), name) {
+			t.Errorf("%s = %s, want the route table %s", export.Location, data, name)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright © 2022 Roger Gomez rogerscuall@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// csvCmd represents the csv command
+var csvCmd = &cobra.Command{
+	Use:   "csv",
+	Short: "Export all route tables to CSV",
+	Long: `Each route table is exported to a separate CSV named <transit gateway>_<route table>.csv,
+with a numeric suffix when two route tables get the same name.
+With --combined all route tables are exported to a single routes.csv, with the Transit Gateway and Route Table as extra columns.
+ECMP routes have one row per attachment. By default the files are stored on the folder csv, it is created if it does not exist.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		folderName, _ := cmd.Flags().GetString("folder")
+		delimiter, _ := cmd.Flags().GetString("delimiter")
+		combined, _ := cmd.Flags().GetBool("combined")
+		comma, err := parseDelimiter(delimiter)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(folderName, 0755); err != nil {
+			return fmt.Errorf("error creating folder: %w", err)
+		}
+
+		progress("Exporting AWS routing to CSV")
+		tgws, err := app.UpdateRouting(context.TODO())
+		if err != nil {
+			return err
+		}
+
+		var exports []output.Export
+		if combined {
+			fileName := filepath.Join(folderName, "routes.csv")
+			err := writeCsv(fileName, comma, func(w *csv.Writer) error {
+				return awsrouter.ExportTgwRoutesCsv(w, tgws)
+			})
+			if err != nil {
+				return err
+			}
+			exports = append(exports, output.Export{Kind: "csv", Location: fileName})
+		} else {
+			// Route tables with the same name, or names that are the same once sanitized, get a numeric suffix.
+			usedFiles := make(map[string]struct{})
+			for _, tgw := range tgws {
+				for _, rt := range tgw.RouteTables {
+					fileName := filepath.Join(folderName, awsrouter.UniqueFileName(tgw.Name+"_"+rt.Name, usedFiles)+".csv")
+					err := writeCsv(fileName, comma, func(w *csv.Writer) error {
+						return awsrouter.ExportRouteTableRoutesCsv(w, tgw, *rt)
+					})
+					if err != nil {
+						return err
+					}
+					exports = append(exports, output.Export{TgwID: tgw.ID, TgwName: tgw.Name, Kind: "csv", Location: fileName})
+				}
+			}
+		}
+		for _, export := range exports {
+			progress("CSV saved:", export.Location)
+		}
+		if outputFormat != output.FormatTable {
+			return printResult(exports)
+		}
+		return nil
+	},
+}
+
+// parseDelimiter returns the rune used as CSV delimiter, `\t` and "tab" are accepted for a tab.
+func parseDelimiter(delimiter string) (rune, error) {
+	if delimiter == `\t` || delimiter == "tab" {
+		return '\t', nil
+	}
+	if utf8.RuneCountInString(delimiter) != 1 {
+		return 0, fmt.Errorf("the delimiter must be a single character, got %q", delimiter)
+	}
+	r, _ := utf8.DecodeRuneInString(delimiter)
+	return r, nil
+}
+
+// writeCsv creates fileName and calls export with a csv.Writer that uses comma as delimiter.
+func writeCsv(fileName string, comma rune, export func(w *csv.Writer) error) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating csv: %w", err)
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	w.Comma = comma
+	if err := export(w); err != nil {
+		return fmt.Errorf("error exporting %s: %w", fileName, err)
+	}
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error exporting %s: %w", fileName, err)
+	}
+	return f.Close()
+}
+
+func init() {
+	rootCmd.AddCommand(csvCmd)
+
+	csvCmd.Flags().String("folder", "csv", "folder where the CSV files are stored")
+	csvCmd.Flags().String("delimiter", ",", `field delimiter, use "\t" or "tab" for tab separated files`)
+	csvCmd.Flags().Bool("combined", false, "export all route tables to a single CSV")
+}
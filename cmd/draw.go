@@ -23,26 +23,26 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/rogerscuall/aws-router/aws/awsrouter"
 	"github.com/rogerscuall/aws-router/aws/draw"
+	"github.com/rogerscuall/aws-router/internal/output"
 	"github.com/spf13/cobra"
 )
 
 // drawCmd represents the draw command
 var drawCmd = &cobra.Command{
 	Use:   "draw",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+	Short: "Draw the topology of each Transit Gateway",
+	Long: `Each Transit Gateway is drawn in a separate PNG in the folder drawings, it is created if it does not exist.
+The drawing has the route tables inside the Transit Gateway and the attachments below, grouped by type.
+Solid lines are associations, from the route table to the attachment.
+Dashed lines are propagations, from the attachment to the route table.
+With --format the drawings can also be saved as SVG, Graphviz DOT or Mermaid, the text formats are always
+written in the same order so they can be kept in git.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		var err error
 		defer func() {
@@ -50,23 +50,51 @@ to quickly create a Cobra application.`,
 				cobra.CheckErr(err)
 			}
 		}()
-		fmt.Println("Downloading routing information from AWS")
-		cfg, err := config.LoadDefaultConfig(context.TODO())
-		client := ec2.NewFromConfig(cfg)
-		tgws, err := awsrouter.UpdateRouting(context.TODO(), client)
+		formatName, _ := cmd.Flags().GetString("format")
+		format, err := draw.ParseFormat(formatName)
+		if err != nil {
+			return
+		}
+		progress("Downloading routing information from AWS")
+		tgws, err := app.UpdateRouting(context.TODO())
+		if err != nil {
+			return
+		}
 		folder, err := os.Stat("drawings")
 		// if folder does not exist, create it
 		if os.IsNotExist(err) {
 			err = os.Mkdir("drawings", 0755)
 			if err != nil {
-				fmt.Println(err)
+				app.ErrorLog.Println(err)
 			}
+			folder, err = os.Stat("drawings")
+		}
+		if err != nil {
+			return
 		}
+		exports := make([]output.Export, 0, len(tgws))
+		// Transit Gateways with the same name, or names that are the same once sanitized, get a numeric suffix.
+		usedFiles := make(map[string]struct{})
 		for _, tgw := range tgws {
-			if err := draw.DrawTgwFull(*tgw, folder); err != nil {
-				fmt.Println("Error drawing tgw: ", tgw.Name)
-				fmt.Println(err)
+			if len(tgw.RouteTables) == 0 {
+				progress("No Route Tables found in Transit Gateway:", tgw.Name)
+				continue
 			}
+			fileName := filepath.Join(folder.Name(), awsrouter.UniqueFileName(tgw.Name, usedFiles)+"."+format.Extension())
+			if err := draw.Save(draw.TgwDiagram(*tgw), format, fileName); err != nil {
+				app.ErrorLog.Println("Error drawing tgw:", tgw.Name, err)
+				continue
+			}
+			progress("Drawing saved:", fileName)
+			exports = append(exports, output.Export{
+				TgwID:    tgw.ID,
+				TgwName:  tgw.Name,
+				Kind:     string(format),
+				Location: fileName,
+			})
+		}
+		if outputFormat != output.FormatTable {
+			err = printResult(exports)
 		}
 	},
 }
@@ -74,6 +102,8 @@ to quickly create a Cobra application.`,
 func init() {
 	rootCmd.AddCommand(drawCmd)
 
+	drawCmd.Flags().String("format", string(draw.FormatPNG), "format of the drawings: "+strings.Join(draw.Formats(), ", "))
+
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
@@ -0,0 +1,91 @@
+/*
+Copyright © 2022 Roger Gomez rogerscuall@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// driftCmd represents the drift command
+var driftCmd = &cobra.Command{
+	Use:   "drift <intent file>",
+	Short: "Compare the routing with a declared intent and report the differences",
+	Long: `Compares the route tables declared in an intent file with the routing in AWS and reports the static routes,
+associations and propagations that are in AWS but not declared (unexpected), declared but not in AWS (missing),
+and the static routes with a different next hop (changed). The route tables of a Transit Gateway that are not declared are unexpected.
+The intent file is a Terraform state in JSON, like the one of the Terraform written by export terraform, or a YAML file
+with route_tables, each one with tgw, route_table, static_routes, associations and propagations.
+In the YAML file a list that is not set is not compared. With --fail the command exits with an error when there is drift.
+With --snapshot the data saved by the sync command is used and AWS is not contacted.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.TODO()
+		snapshot, _ := cmd.Flags().GetBool("snapshot")
+		fail, _ := cmd.Flags().GetBool("fail")
+
+		f, err := os.Open(args[0])
+		cobra.CheckErr(err)
+		intent, err := awsrouter.ReadIntent(f)
+		f.Close()
+		cobra.CheckErr(err)
+
+		tgws, err := loadRouting(ctx, snapshot)
+		cobra.CheckErr(err)
+		drifts, err := awsrouter.FindDrift(tgws, intent)
+		cobra.CheckErr(err)
+
+		result := make([]output.Drift, 0, len(drifts))
+		for _, d := range drifts {
+			result = append(result, output.NewDrift(d))
+		}
+		if outputFormat != output.FormatTable {
+			cobra.CheckErr(printResult(result))
+		} else if len(result) == 0 {
+			fmt.Println("No drift")
+		} else {
+			for _, d := range result {
+				if where := strings.TrimSpace(d.TgwName + " " + d.RouteTableName); where != "" {
+					fmt.Printf("%s: %s\n", where, d.Message)
+					continue
+				}
+				fmt.Println(d.Message)
+			}
+		}
+		if fail && len(result) > 0 {
+			cobra.CheckErr(fmt.Errorf("%d differences with the intent", len(result)))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(driftCmd)
+
+	driftCmd.Flags().Bool("snapshot", false, "use the routing saved by the sync command instead of AWS")
+	driftCmd.Flags().Bool("fail", false, "exit with an error when there is drift")
+}
@@ -27,6 +27,7 @@ import (
 	"os"
 
 	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -35,7 +36,8 @@ var excelCmd = &cobra.Command{
 	Use:   "excel",
 	Short: "Export all route tables to excel",
 	Long: `Each Transit Gateway will have a separate Excel and each route table will have a separate sheet.
-By default all excel are stored on the folder excel. The folder has to exist.`,
+By default all excel are stored on the folder excel, it is created if it does not exist.
+Route table names that are not valid sheet names are shortened or changed, the original name is in the first row of the sheet.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		var err error
 		ctx := context.TODO()
@@ -44,30 +46,41 @@ By default all excel are stored on the folder excel. The folder has to exist.`,
 				app.ErrorLog.Println(err)
 			}
 		}()
-		fmt.Println("Exporting AWS routing to Excel")
-		folderName := "excel"
+		progress("Exporting AWS routing to Excel")
+		folderName, _ := cmd.Flags().GetString("folder")
 		tgws, err := app.UpdateRouting(ctx)
-		var folder os.FileInfo
-		if folder, err = os.Stat(folderName); err != nil {
-			err = os.Mkdir(folderName, 0755)
-			if err != nil {
-				fmt.Println("Error creating folder:", err)
-				return // Exit if there's an error
-			}
-			fmt.Println("Folder created:", folderName)
-			folder, _ = os.Stat(folderName)
+		if err != nil {
+			return
 		}
-		err = awsrouter.ExportTgwRoutesExcel(tgws, folder)
+		if err = os.MkdirAll(folderName, 0755); err != nil {
+			err = fmt.Errorf("error creating folder: %w", err)
+			return // Exit if there's an error
+		}
+		workbooks, err := awsrouter.ExportTgwRoutesExcel(tgws, folderName)
 		if err != nil {
-			fmt.Println(err)
+			return
+		}
+		exports := make([]output.Export, 0, len(workbooks))
+		for _, workbook := range workbooks {
+			progress("Excel saved:", workbook.Path)
+			for _, sheet := range workbook.Sheets {
+				if sheet.Sheet != sheet.RouteTableName {
+					progress("\tRoute Table", sheet.RouteTableName, "("+sheet.RouteTableID+")", "exported to sheet", sheet.Sheet)
+				}
+			}
+			exports = append(exports, output.NewExcelExport(workbook))
+		}
+		if outputFormat != output.FormatTable {
+			err = printResult(exports)
 		}
-
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(excelCmd)
 
+	excelCmd.Flags().String("folder", "excel", "folder where the Excel files are stored")
+
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
@@ -0,0 +1,127 @@
+/*
+Copyright © 2022 Roger Gomez rogerscuall@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// explainCmd represents the explain command
+var explainCmd = &cobra.Command{
+	Use:   "explain <route table> <prefix>",
+	Short: "Explain why a route is, or is not, in a route table",
+	Long: `Explains how a route table routes a prefix: the route used for the prefix, all the routes that cover it
+or are inside it, if the route is propagated or static and which attachment advertised it.
+When there is no route, the attachments that have the prefix are listed with the route tables where they propagate.
+The route table is selected by ID or name, --tgw selects the Transit Gateway when the name is not unique.
+The prefix can be an IP address, it is used as a /32.
+With --snapshot the data saved by the sync command is used and AWS is not contacted.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.TODO()
+		snapshot, _ := cmd.Flags().GetBool("snapshot")
+		tgwID, _ := cmd.Flags().GetString("tgw")
+
+		prefix, err := parsePrefix(args[1])
+		cobra.CheckErr(err)
+		tgws, err := loadRouting(ctx, snapshot)
+		cobra.CheckErr(err)
+		tgw, rt, err := awsrouter.FindRouteTable(tgws, tgwID, args[0])
+		cobra.CheckErr(err)
+		e, err := tgw.ExplainRoute(rt, prefix)
+		cobra.CheckErr(err)
+
+		x := output.NewExplanation(e)
+		if outputFormat != output.FormatTable {
+			cobra.CheckErr(printResult(x))
+			return
+		}
+		fmt.Printf("%s in route table %s of %s\n", x.Prefix, x.RouteTableName, x.TgwName)
+		if x.Route == nil {
+			fmt.Println("Route: none")
+		} else {
+			fmt.Println("Route:", routeLine(*x.Route))
+		}
+		fmt.Println("Candidates:")
+		for _, r := range x.Candidates {
+			fmt.Println("  " + routeLine(r))
+		}
+		fmt.Println("Reasons:")
+		for _, reason := range x.Reasons {
+			fmt.Println("  " + reason)
+		}
+	},
+}
+
+// parsePrefix parses a CIDR or an IP address, used as a host prefix.
+func parsePrefix(s string) (net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return net.IPNet{}, fmt.Errorf("invalid prefix or IP address: %s", s)
+		}
+		if ip.To4() != nil {
+			return net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, prefix, err := net.ParseCIDR(s)
+	if err != nil {
+		return net.IPNet{}, fmt.Errorf("invalid prefix or IP address: %w", err)
+	}
+	return *prefix, nil
+}
+
+// routeLine returns a route in one line, like "10.0.0.0/16 propagated active via vpc-a".
+func routeLine(r output.Route) string {
+	var hops []string
+	for _, att := range r.Attachments {
+		name := att.Name
+		if name == "" {
+			name = att.ID
+		}
+		hops = append(hops, name)
+	}
+	destination := r.Destination
+	if destination == "" {
+		destination = r.PrefixListID
+	}
+	line := fmt.Sprintf("%s %s %s", destination, r.Type, r.State)
+	if len(hops) > 0 {
+		line += " via " + strings.Join(hops, ", ")
+	}
+	return line
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+
+	explainCmd.Flags().Bool("snapshot", false, "use the routing saved by the sync command instead of AWS")
+	explainCmd.Flags().String("tgw", "", "ID or name of the Transit Gateway of the route table")
+}
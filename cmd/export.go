@@ -0,0 +1,103 @@
+/*
+Copyright © 2022 Roger Gomez rogerscuall@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the routing as code",
+}
+
+// exportTerraformCmd represents the export terraform command
+var exportTerraformCmd = &cobra.Command{
+	Use:   "terraform",
+	Short: "Export the route tables as Terraform with import blocks",
+	Long: `Each Transit Gateway is exported to a separate file named <transit gateway>.tf with the resources
+aws_ec2_transit_gateway_route_table, aws_ec2_transit_gateway_route for the static routes,
+aws_ec2_transit_gateway_prefix_list_reference for the static routes to a prefix list,
+aws_ec2_transit_gateway_route_table_association and aws_ec2_transit_gateway_route_table_propagation,
+each one with an import block so terraform plan adopts the existing resources without changes.
+Propagated routes are not exported, they are created by the propagations. Blackhole static routes have blackhole = true.
+The resource names start with the name of the Transit Gateway, followed by its ID when the name is empty or shared
+with another Transit Gateway, so the files can be used in the same module. Files with the same name get a numeric suffix.
+By default the files are stored on the folder terraform, it is created if it does not exist.
+With --snapshot the data saved by the sync command is used and AWS is not contacted.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		folderName, _ := cmd.Flags().GetString("folder")
+		snapshot, _ := cmd.Flags().GetBool("snapshot")
+		if err := os.MkdirAll(folderName, 0755); err != nil {
+			return fmt.Errorf("error creating folder: %w", err)
+		}
+
+		tgws, err := loadRouting(context.TODO(), snapshot)
+		if err != nil {
+			return err
+		}
+		var exports []output.Export
+		usedFiles := make(map[string]struct{})
+		for _, tgw := range tgws {
+			fileName := filepath.Join(folderName, awsrouter.UniqueFileName(tgw.Name, usedFiles)+".tf")
+			if err := writeTerraform(fileName, tgw, awsrouter.TerraformPrefix(tgw, tgws)); err != nil {
+				return err
+			}
+			exports = append(exports, output.Export{TgwID: tgw.ID, TgwName: tgw.Name, Kind: "terraform", Location: fileName})
+			progress("Terraform saved:", fileName)
+		}
+		if outputFormat != output.FormatTable {
+			return printResult(exports)
+		}
+		return nil
+	},
+}
+
+// writeTerraform creates fileName with the Terraform of the tgw, the resource names start with prefix.
+func writeTerraform(fileName string, tgw *awsrouter.Tgw, prefix string) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating terraform file: %w", err)
+	}
+	defer f.Close()
+	if err := awsrouter.ExportTgwTerraform(f, tgw, prefix); err != nil {
+		return fmt.Errorf("error exporting %s: %w", fileName, err)
+	}
+	return f.Close()
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportTerraformCmd)
+
+	exportTerraformCmd.Flags().String("folder", "terraform", "folder where the Terraform files are stored")
+	exportTerraformCmd.Flags().Bool("snapshot", false, "use the routing saved by the sync command instead of AWS")
+}
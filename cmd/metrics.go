@@ -0,0 +1,92 @@
+/*
+Copyright © 2022 Roger Gomez rogerscuall@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rogerscuall/aws-router/internal/metrics"
+	"github.com/rogerscuall/aws-router/ports"
+	"github.com/spf13/cobra"
+)
+
+// metricsCmd represents the metrics command
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Print the Prometheus metrics of the routing",
+	Long: `Downloads the routing from AWS once and prints the metrics in the Prometheus text format:
+the routes of each route table by type and state, the blackhole routes, the attachments by type,
+the time of the sync and the calls, errors and latency of the AWS API.
+With --file the metrics are written to a file, to be read by the textfile collector of the node exporter.
+To scrape the metrics use the serve command, it exposes them in /metrics.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+		defer func() {
+			if err != nil {
+				app.ErrorLog.Println(err)
+			}
+		}()
+		fileName, _ := cmd.Flags().GetString("file")
+		m := metrics.NewCollector()
+		app.RouterClient = ports.NewInstrumentedRouter(app.RouterClient, m.ObserveAPICall)
+		progress("Downloading routing information from AWS")
+		tgws, partial, syncErr := app.SyncRouting(context.TODO())
+		if syncErr == nil && partial != nil {
+			// The gauges of the incomplete route tables are not exported, there is no previous sync to keep them from.
+			syncErr = partial
+		}
+		m.ObserveSync(tgws, time.Now(), syncErr)
+		if syncErr != nil {
+			app.ErrorLog.Println(syncErr)
+		}
+		var w io.Writer = os.Stdout
+		if fileName != "" {
+			// The file is renamed at the end, so the collector never reads a partial file.
+			var f *os.File
+			f, err = os.Create(fileName + ".tmp")
+			if err != nil {
+				err = fmt.Errorf("error creating the metrics file: %w", err)
+				return
+			}
+			defer func() {
+				if cerr := f.Close(); err == nil && cerr != nil {
+					err = cerr
+				}
+				if err == nil {
+					err = os.Rename(fileName+".tmp", fileName)
+				}
+			}()
+			w = f
+		}
+		err = m.Write(w)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(metricsCmd)
+
+	metricsCmd.Flags().String("file", "", "file where the metrics are written instead of stdout")
+}
@@ -25,26 +25,38 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/aws/draw"
+	"github.com/rogerscuall/aws-router/internal/output"
 	"github.com/spf13/cobra"
 )
 
 // pathCmd represents the path command
 var pathCmd = &cobra.Command{
-	Use:   "path",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+	Use:   "path <source IP> <destination IP>",
+	Short: "Find the path between two IP addresses in every Transit Gateway",
+	Long: `Walks the route tables of every Transit Gateway from the attachment of the source IP
+to the attachment of the destination IP and prints the attachments in the path.
+The VPN attachments in the path show how many tunnels are up and the routes learned with BGP,
+a path into a VPN without tunnels up is dropped. The Direct Connect gateways show their allowed prefixes
+and the Connect attachments the attachment they run over and the BGP status of their peers.
+A path into a peering with a Cloud WAN core network stops there, with the policy table of the peering,
+because the routing continues in the core network.
+With --draw the path of each Transit Gateway is also saved as a PNG in the folder drawings,
+showing each route table consulted with the matched prefix, the ECMP next hops and where the traffic is dropped.
+The format of the drawing is selected with --format.`,
+	Args: cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := context.TODO()
+		drawPath, _ := cmd.Flags().GetBool("draw")
+		formatName, _ := cmd.Flags().GetString("format")
+		format, err := draw.ParseFormat(formatName)
+		cobra.CheckErr(err)
 
-		fmt.Println("path called")
-		fmt.Println("args:", args)
 		srcIPAddress := net.ParseIP(args[0])
 		if srcIPAddress == nil {
 			app.ErrorLog.Println("invalid source IP address:", args[0])
@@ -57,24 +69,80 @@ to quickly create a Cobra application.`,
 		if err != nil {
 			app.ErrorLog.Println("error updating routing:", err)
 		}
+		if drawPath {
+			if err := os.MkdirAll("drawings", 0755); err != nil {
+				cobra.CheckErr(fmt.Errorf("error creating folder: %w", err))
+			}
+		}
+		paths := make([]output.Path, 0, len(tgws))
 		for _, tgw := range tgws {
-			fmt.Printf("Transit Gateway Name: %s\n", tgw.Name)
-			if len(tgw.RouteTables) > 0 {
-				tgw.UpdateTgwRouteTablesAttachments(context.TODO(), app.RouterClient)
-				tgwPath := awsrouter.NewAttPath()
-				tgwPath.Tgw = tgw
-				tgwPath.Walk(context.TODO(), app.RouterClient, srcIPAddress, dstIPAddress)
+			if len(tgw.RouteTables) == 0 {
+				progress("No Route Tables found in Transit Gateway:", tgw.Name)
+				continue
+			}
+			tgwPath := awsrouter.NewAttPath()
+			tgwPath.Tgw = tgw
+			err := tgwPath.Walk(context.TODO(), app.RouterClient, srcIPAddress, dstIPAddress)
+			path := output.NewPath(tgwPath, args[0], args[1], err)
+			if drawPath {
+				fileName := filepath.Join("drawings", awsrouter.SafeFileName(fmt.Sprintf("path-%s-%s-%s", tgw.Name, args[0], args[1]))+"."+format.Extension())
+				if drawErr := draw.Save(draw.PathDiagram(tgwPath, args[0], args[1], err), format, fileName); drawErr != nil {
+					app.ErrorLog.Println("Error drawing path:", tgw.Name, drawErr)
+				} else {
+					path.Drawing = fileName
+					progress("Drawing saved:", fileName)
+				}
+			}
+			paths = append(paths, path)
+			if outputFormat == output.FormatTable {
+				fmt.Printf("Transit Gateway Name: %s\n", tgw.Name)
 				fmt.Println("Path:", tgwPath.String())
-			} else {
-				fmt.Println("No Route Tables found")
+				printHybridHops(tgw, tgwPath.Path)
+				if path.Exit != "" {
+					fmt.Println("Exit:", path.Exit)
+				} else if err != nil {
+					app.ErrorLog.Println(err)
+				}
 			}
 		}
+		if outputFormat != output.FormatTable {
+			cobra.CheckErr(printResult(paths))
+		}
 	},
 }
 
+// printHybridHops prints the status of the VPN, Direct Connect gateway and Connect attachments in path.
+func printHybridHops(tgw *awsrouter.Tgw, path []*awsrouter.TgwAttachment) {
+	for _, att := range path {
+		if vpn := tgw.VpnConnection(att); vpn != nil {
+			status := ""
+			if vpn.Down() {
+				status = ", down"
+			}
+			fmt.Printf("  VPN %s: %d/%d tunnels up, %d routes learned with BGP%s\n", vpn.ID, vpn.TunnelsUp(), len(vpn.Tunnels), vpn.AcceptedRoutes(), status)
+		}
+		if dx := tgw.DxGateway(att); dx != nil {
+			fmt.Printf("  Direct Connect gateway %s: %s, allowed prefixes %s\n", dx.ID, dx.State, strings.Join(dx.AllowedPrefixes, ", "))
+		}
+		if c := tgw.Connect(att); c != nil {
+			fmt.Printf("  Connect %s over %s: %d/%d peers with BGP up\n", c.ID, c.TransportAttachmentID, c.PeersUp(), len(c.Peers))
+			for _, peer := range c.Peers {
+				var status []string
+				for _, session := range peer.BgpSessions {
+					status = append(status, session.Status)
+				}
+				fmt.Printf("    peer %s %s: BGP %s\n", peer.ID, peer.PeerAddress, strings.Join(status, ", "))
+			}
+		}
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(pathCmd)
 
+	pathCmd.Flags().Bool("draw", false, "save a drawing of the path of each Transit Gateway in the folder drawings")
+	pathCmd.Flags().String("format", string(draw.FormatPNG), "format of the drawings: "+strings.Join(draw.Formats(), ", "))
+
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
@@ -0,0 +1,115 @@
+/*
+Copyright © 2022 Roger Gomez rogerscuall@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// quotasCmd represents the quotas command
+var quotasCmd = &cobra.Command{
+	Use:   "quotas",
+	Short: "Report the use of the Transit Gateway quotas",
+	Long: `Compares the routes of each route table, the routes, route tables and attachments of each Transit Gateway
+with their quotas and reports the level of each one: ok, warning (at --warning percent) or critical (at --critical percent).
+A route to a prefix list counts the maximum entries of the prefix list, the routes added by each prefix list are listed.
+The attachments are the ones seen in the route tables and policy tables, an attachment that is not associated,
+does not propagate and is not the next hop of a route is not counted, so their use is a lower bound.
+The limits default to the AWS quotas, they can be changed with the flags or in the config file under the key quotas,
+for example quotas.routes_per_tgw. With --fail-on the command exits with an error when a quota reaches that level,
+to use it from alerting. With --snapshot the data saved by the sync command is used and AWS is not contacted.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.TODO()
+		snapshot, _ := cmd.Flags().GetBool("snapshot")
+		failOn, _ := cmd.Flags().GetString("fail-on")
+		if failOn != "" && failOn != string(awsrouter.QuotaWarning) && failOn != string(awsrouter.QuotaCritical) {
+			cobra.CheckErr(fmt.Errorf("invalid --fail-on %q, use warning or critical", failOn))
+		}
+		limits := awsrouter.Limits{
+			RoutesPerTgw:        viper.GetInt("quotas.routes_per_tgw"),
+			RoutesPerRouteTable: viper.GetInt("quotas.routes_per_route_table"),
+			RouteTablesPerTgw:   viper.GetInt("quotas.route_tables_per_tgw"),
+			AttachmentsPerTgw:   viper.GetInt("quotas.attachments_per_tgw"),
+			WarningPercent:      viper.GetFloat64("quotas.warning"),
+			CriticalPercent:     viper.GetFloat64("quotas.critical"),
+		}
+
+		tgws, err := loadRouting(ctx, snapshot)
+		cobra.CheckErr(err)
+		reports := make([]output.QuotaReport, 0, len(tgws))
+		for _, tgw := range tgws {
+			reports = append(reports, output.NewQuotaReport(tgw, limits))
+		}
+		if outputFormat != output.FormatTable {
+			cobra.CheckErr(printResult(reports))
+		} else {
+			for _, r := range reports {
+				fmt.Printf("%s: %s\n", r.TgwName, r.Level)
+				for _, u := range r.Usages {
+					quota := u.Quota
+					if u.RouteTableName != "" {
+						quota += " " + u.RouteTableName
+					}
+					fmt.Printf("  %-40s %6d / %-6d %5.1f%% %s\n", quota, u.Used, u.Limit, u.Percent, u.Level)
+				}
+				for _, pl := range r.PrefixLists {
+					fmt.Printf("  prefix list %s %s: %d references of %d entries, %d routes\n", pl.ID, pl.Name, pl.References, pl.MaxEntries, pl.Entries)
+				}
+			}
+		}
+		if failOn == "" {
+			return
+		}
+		for _, r := range reports {
+			if r.Level == string(awsrouter.QuotaCritical) || r.Level == failOn {
+				cobra.CheckErr(fmt.Errorf("the quotas of %s are at the %s level", r.TgwName, r.Level))
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(quotasCmd)
+
+	limits := awsrouter.DefaultLimits()
+	quotasCmd.Flags().Bool("snapshot", false, "use the routing saved by the sync command instead of AWS")
+	quotasCmd.Flags().String("fail-on", "", "exit with an error when a quota reaches this level, warning or critical")
+	quotasCmd.Flags().Int("routes-per-tgw", limits.RoutesPerTgw, "routes across all the route tables of a Transit Gateway")
+	quotasCmd.Flags().Int("routes-per-route-table", limits.RoutesPerRouteTable, "routes of a route table")
+	quotasCmd.Flags().Int("route-tables-per-tgw", limits.RouteTablesPerTgw, "route tables of a Transit Gateway")
+	quotasCmd.Flags().Int("attachments-per-tgw", limits.AttachmentsPerTgw, "attachments of a Transit Gateway")
+	quotasCmd.Flags().Float64("warning", limits.WarningPercent, "percentage of a quota that raises a warning")
+	quotasCmd.Flags().Float64("critical", limits.CriticalPercent, "percentage of a quota that is critical")
+	viper.BindPFlag("quotas.routes_per_tgw", quotasCmd.Flags().Lookup("routes-per-tgw"))
+	viper.BindPFlag("quotas.routes_per_route_table", quotasCmd.Flags().Lookup("routes-per-route-table"))
+	viper.BindPFlag("quotas.route_tables_per_tgw", quotasCmd.Flags().Lookup("route-tables-per-tgw"))
+	viper.BindPFlag("quotas.attachments_per_tgw", quotasCmd.Flags().Lookup("attachments-per-tgw"))
+	viper.BindPFlag("quotas.warning", quotasCmd.Flags().Lookup("warning"))
+	viper.BindPFlag("quotas.critical", quotasCmd.Flags().Lookup("critical"))
+}
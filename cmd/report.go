@@ -0,0 +1,87 @@
+/*
+Copyright © 2022 Roger Gomez rogerscuall@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rogerscuall/aws-router/internal/output"
+	"github.com/rogerscuall/aws-router/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Create an HTML report of all the Transit Gateways",
+	Long: `Creates a single HTML file with an overview of the Transit Gateways, the topology of each one,
+the attachments, the findings of the routing checks and all the route tables, that can be sorted and filtered.
+The file works offline, so it can be shared with people without AWS access or the CLI.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+		defer func() {
+			if err != nil {
+				app.ErrorLog.Println(err)
+			}
+		}()
+		fileName, _ := cmd.Flags().GetString("file")
+		progress("Downloading routing information from AWS")
+		tgws, err := app.UpdateRouting(context.TODO())
+		if err != nil {
+			return
+		}
+		f, err := os.Create(fileName)
+		if err != nil {
+			err = fmt.Errorf("error creating the report: %w", err)
+			return
+		}
+		if err = report.Write(f, tgws, time.Now()); err != nil {
+			f.Close()
+			return
+		}
+		if err = f.Close(); err != nil {
+			return
+		}
+		progress("Report saved:", fileName)
+		if outputFormat != output.FormatTable {
+			exports := make([]output.Export, 0, len(tgws))
+			for _, tgw := range tgws {
+				exports = append(exports, output.Export{
+					TgwID:    tgw.ID,
+					TgwName:  tgw.Name,
+					Kind:     "html",
+					Location: fileName,
+				})
+			}
+			err = printResult(exports)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().String("file", "report.html", "file where the report is saved")
+}
@@ -24,9 +24,14 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 
+	"github.com/rogerscuall/aws-router/adapters/db"
+	"github.com/rogerscuall/aws-router/adapters/recorder"
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
 	"github.com/rogerscuall/aws-router/internal/application"
+	"github.com/rogerscuall/aws-router/internal/output"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -35,19 +40,51 @@ import (
 var cfgFile string
 var app *application.Application
 
+// recording keeps the AWS responses when the --record flag is set, they are saved after the command runs.
+var recording *recorder.Recorder
+
+// outputFormat is the format selected with the --output flag, it is set before any command runs.
+var outputFormat output.Format
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "awsrouters",
 	Short: "Extracts all routing information from AWS and prints a table to console",
 	Long:  `Extracts all routing information from AWS and prints a table to console`,
-	// Uncomment the following line if your bare application
-	// has an action associated with it:
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		outputFormat, err = output.ParseFormat(viper.GetString("output"))
+		if err != nil {
+			return err
+		}
+		if endpoint := viper.GetString("endpoint"); endpoint != "" {
+			if err := app.Init(endpoint); err != nil {
+				return err
+			}
+		}
+		return setupRecording(cmd)
+	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		if recording == nil {
+			return nil
+		}
+		fileName, _ := cmd.Flags().GetString("record")
+		if err := recording.Save(fileName); err != nil {
+			return err
+		}
+		progress("AWS responses recorded to", fileName)
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := context.TODO()
 		tgws, err := app.UpdateRouting(ctx)
 		if err != nil {
 			app.ErrorLog.Println(err)
 		}
+		if outputFormat != output.FormatTable {
+			cobra.CheckErr(printResult(output.NewTgws(tgws)))
+			return
+		}
 		for _, tgw := range tgws {
 			fmt.Printf("Transit Gateway Name: %s\n", tgw.Name)
 			if len(tgw.RouteTables) > 0 {
@@ -78,18 +115,72 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.go-aws-routing.yaml)")
+	rootCmd.PersistentFlags().StringP("output", "o", string(output.FormatTable), fmt.Sprintf("output format, one of %v", output.Formats()))
+	viper.BindPFlag("output", rootCmd.PersistentFlags().Lookup("output"))
+	rootCmd.PersistentFlags().String("endpoint", "", "URL of the EC2 API, to use a local fake instead of AWS")
+	viper.BindPFlag("endpoint", rootCmd.PersistentFlags().Lookup("endpoint"))
+	rootCmd.PersistentFlags().String("record", "", "record the AWS responses to this fixture file")
+	rootCmd.PersistentFlags().StringSlice("scrub", nil, "scrub the recorded responses, any of accounts, ips")
+	rootCmd.PersistentFlags().String("replay", "", "answer from this fixture file instead of calling AWS")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 
 	app = application.NewApplication()
-	err := app.Init()
+	err := app.Init("")
 	if err != nil {
 		app.ErrorLog.Println(err)
 	}
 }
 
+// setupRecording wraps the AWS client of the application with a recorder or replaces it with a replayer,
+// as requested with the --record, --scrub and --replay flags.
+func setupRecording(cmd *cobra.Command) error {
+	record, _ := cmd.Flags().GetString("record")
+	scrub, _ := cmd.Flags().GetStringSlice("scrub")
+	replay, _ := cmd.Flags().GetString("replay")
+	if record != "" && replay != "" {
+		return fmt.Errorf("--record and --replay can not be used together")
+	}
+	if len(scrub) > 0 && record == "" {
+		return fmt.Errorf("--scrub requires --record")
+	}
+	if replay != "" {
+		replayer, err := recorder.LoadReplayer(replay)
+		if err != nil {
+			return err
+		}
+		app.RouterClient = replayer
+		return nil
+	}
+	if record == "" {
+		return nil
+	}
+	var accounts, ips bool
+	for _, s := range scrub {
+		switch s {
+		case "accounts":
+			accounts = true
+		case "ips":
+			ips = true
+		default:
+			return fmt.Errorf("unknown value %q for --scrub, use accounts or ips", s)
+		}
+	}
+	var scrubber *recorder.Scrubber
+	if accounts || ips {
+		var err error
+		scrubber, err = recorder.NewScrubber(accounts, ips)
+		if err != nil {
+			return err
+		}
+	}
+	recording = recorder.NewRecorder(app.RouterClient, scrubber)
+	app.RouterClient = recording
+	return nil
+}
+
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
 	if cfgFile != "" {
@@ -114,3 +205,31 @@ func initConfig() {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
+
+// stdout is where printResult writes the results, the tests replace it to read them.
+var stdout io.Writer = os.Stdout
+
+// printResult writes v to stdout in the format selected with the --output flag.
+// It should only be called when the format is not table.
+func printResult(v interface{}) error {
+	return output.Write(stdout, outputFormat, v)
+}
+
+// loadRouting returns the routing of the Transit Gateways, from AWS or, if snapshot is set, from the data saved by the sync command.
+func loadRouting(ctx context.Context, snapshot bool) ([]*awsrouter.Tgw, error) {
+	if !snapshot {
+		progress("Downloading routing information from AWS")
+		return app.UpdateRouting(ctx)
+	}
+	dbAdapterTgw, err := db.NewAdapter(fmt.Sprintf("%s_tgw", viper.GetString("db_name")))
+	if err != nil {
+		return nil, err
+	}
+	defer dbAdapterTgw.CloseDbConnection()
+	return application.LoadSnapshot(dbAdapterTgw)
+}
+
+// progress prints a progress message to stderr, so it never mixes with the result of a command.
+func progress(a ...interface{}) {
+	fmt.Fprintln(os.Stderr, a...)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2022 Roger Gomez rogerscuall@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/rogerscuall/aws-router/internal/metrics"
+	"github.com/rogerscuall/aws-router/internal/server"
+	"github.com/rogerscuall/aws-router/ports"
+	"github.com/spf13/cobra"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the routing of the Transit Gateways over a local HTTP JSON API",
+	Long: `Starts an HTTP server with a JSON API to list the Transit Gateways, route tables, routes and attachments,
+look up the best route to an IP, walk the path between two IPs and get the diagrams.
+The routing is kept in memory and downloaded again from AWS every --refresh, POST /api/v1/refresh downloads it on demand.
+The endpoints are listed in the README. The Prometheus metrics of the routing and of the AWS API calls are in /metrics.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		addr, _ := cmd.Flags().GetString("listen")
+		interval, _ := cmd.Flags().GetDuration("refresh")
+		if interval <= 0 {
+			cobra.CheckErr("the refresh interval must be greater than 0")
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		m := metrics.NewCollector()
+		app.RouterClient = ports.NewInstrumentedRouter(app.RouterClient, m.ObserveAPICall)
+		s := server.New(app, m)
+		progress("Downloading routing information from AWS")
+		if err := s.Refresh(ctx); err != nil {
+			app.ErrorLog.Println("error loading the routing:", err)
+		}
+		go s.Run(ctx, interval)
+
+		srv := &http.Server{Addr: addr, Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			srv.Shutdown(shutdownCtx)
+		}()
+		progress("Listening on", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			app.ErrorLog.Println(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("listen", "localhost:8080", "address where the API listens")
+	serveCmd.Flags().Duration("refresh", 5*time.Minute, "how often the routing is downloaded from AWS")
+}
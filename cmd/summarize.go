@@ -0,0 +1,77 @@
+/*
+Copyright © 2022 Roger Gomez rogerscuall@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// summarizeCmd represents the summarize command
+var summarizeCmd = &cobra.Command{
+	Use:   "summarize",
+	Short: "Suggest summaries of the static routes and the route quota headroom gained",
+	Long: `Finds the active static routes of each route table with the same next hop attachments that can be
+replaced by fewer prefixes without changing the forwarding: a summary is only proposed when it covers exactly
+the routes replaced and no route to another attachment would be captured by it.
+For each Transit Gateway the routes are compared with the routes quota (--quota) before and after the summaries.
+With --snapshot the data saved by the sync command is used and AWS is not contacted.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.TODO()
+		snapshot, _ := cmd.Flags().GetBool("snapshot")
+		quota, _ := cmd.Flags().GetInt("quota")
+
+		tgws, err := loadRouting(ctx, snapshot)
+		cobra.CheckErr(err)
+		result := make([]output.Summarization, 0, len(tgws))
+		for _, tgw := range tgws {
+			summaries, err := tgw.SummarizeStaticRoutes()
+			cobra.CheckErr(err)
+			result = append(result, output.NewSummarization(tgw, summaries, quota))
+		}
+		if outputFormat != output.FormatTable {
+			cobra.CheckErr(printResult(result))
+			return
+		}
+		for _, s := range result {
+			fmt.Printf("%s: %d routes, %d after the summaries, headroom %d -> %d of %d\n",
+				s.TgwName, s.Routes, s.RoutesAfter, s.Headroom, s.HeadroomAfter, s.Quota)
+			for _, summary := range s.Summaries {
+				fmt.Printf("  %s: %s via %s replaces %s\n",
+					summary.RouteTableName, summary.Prefix, strings.Join(summary.Attachments, ", "), strings.Join(summary.Routes, ", "))
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(summarizeCmd)
+
+	summarizeCmd.Flags().Bool("snapshot", false, "use the routing saved by the sync command instead of AWS")
+	summarizeCmd.Flags().Int("quota", awsrouter.RoutesPerTgwQuota, "routes quota of a Transit Gateway")
+}
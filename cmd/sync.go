@@ -26,6 +26,8 @@ import (
 	"fmt"
 
 	"github.com/rogerscuall/aws-router/adapters/db"
+	"github.com/rogerscuall/aws-router/internal/application"
+	"github.com/rogerscuall/aws-router/internal/output"
 	"github.com/rogerscuall/aws-router/ports"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -51,21 +53,33 @@ var syncCmd = &cobra.Command{
 		}
 		defer dbAdapterTgw.CloseDbConnection()
 		defer dbAdapterTgwRouteTable.CloseDbConnection()
-		fmt.Println("Downloading routing information from AWS")
+		progress("Downloading routing information from AWS")
 		ctx := context.TODO()
 		tgws, err := app.UpdateRouting(ctx)
 		if err != nil {
 			app.ErrorLog.Println(err)
 		}
-		fmt.Println("Saving routing information to DB")
+		progress("Saving routing information to DB")
+		if err = application.SaveSnapshot(dbAdapterTgw, tgws); err != nil {
+			app.ErrorLog.Println(err)
+			return
+		}
+		exports := make([]output.Export, 0, len(tgws))
 		for _, tgw := range tgws {
-			err = dbAdapterTgw.SetVal(tgw.ID, tgw.Bytes())
-			if err != nil {
-				app.ErrorLog.Println(err)
-			}
 			for _, rt := range tgw.RouteTables {
-				err = dbAdapterTgwRouteTable.SetVal(rt.ID, rt.Bytes())
+				if err = dbAdapterTgwRouteTable.SetVal(rt.ID, rt.Bytes()); err != nil {
+					app.ErrorLog.Println(err)
+				}
 			}
+			exports = append(exports, output.Export{
+				TgwID:    tgw.ID,
+				TgwName:  tgw.Name,
+				Kind:     "db",
+				Location: fmt.Sprintf("%s/%s", dbNameTgw, tgw.ID),
+			})
+		}
+		if outputFormat != output.FormatTable {
+			cobra.CheckErr(printResult(exports))
 		}
 	},
 }
@@ -0,0 +1,72 @@
+/*
+Copyright © 2022 Roger Gomez rogerscuall@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/internal/tui"
+	"github.com/rogerscuall/aws-router/ports"
+	"github.com/spf13/cobra"
+)
+
+// tuiCmd represents the tui command
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse the routing of the Transit Gateways interactively",
+	Long: `Opens a terminal UI to pick a Transit Gateway, drill into its route tables, filter the routes
+by prefix or IP, jump from a route to its attachment and look up the path between two IPs.
+With --snapshot the data saved by the sync command is used and AWS is not contacted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+		defer func() {
+			if err != nil {
+				app.ErrorLog.Println(err)
+			}
+		}()
+		ctx := context.TODO()
+		snapshot, _ := cmd.Flags().GetBool("snapshot")
+		var tgws []*awsrouter.Tgw
+		var api ports.AWSRouter
+		if !snapshot {
+			api = app.RouterClient
+		}
+		tgws, err = loadRouting(ctx, snapshot)
+		if err != nil {
+			return
+		}
+		if len(tgws) == 0 {
+			err = fmt.Errorf("no Transit Gateways found")
+			return
+		}
+		err = tea.NewProgram(tui.New(ctx, tgws, api), tea.WithAltScreen()).Start()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+
+	tuiCmd.Flags().Bool("snapshot", false, "use the routing saved by the sync command instead of AWS")
+}
@@ -24,15 +24,23 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/rogerscuall/aws-router/internal/output"
 	"github.com/spf13/cobra"
 )
 
+// version is the version of the awsrouters
+const version = "0.1.0"
+
 // versionCmd represents the version command
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "version of the awsrouters",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("version 0.1.0")
+		if outputFormat != output.FormatTable {
+			cobra.CheckErr(printResult(output.Version{Version: version}))
+			return
+		}
+		fmt.Println("version", version)
 	},
 }
 
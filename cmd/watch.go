@@ -0,0 +1,81 @@
+/*
+Copyright © 2022 Roger Gomez rogerscuall@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/rogerscuall/aws-router/adapters/db"
+	"github.com/rogerscuall/aws-router/internal/watch"
+	"github.com/rogerscuall/aws-router/ports"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Poll the routing and print the changes as JSON lines",
+	Long: `Downloads the routing from AWS every --interval and compares it with the previous poll.
+Every change (route added or removed, route that became a blackhole, next hops changed, association changed,
+attachment added or removed) is printed to stdout as a JSON line and, with --webhook, posted as JSON to the URL.
+The last routing seen is saved in the DB <db_name>_watch, so after a restart only the new changes are reported.
+The first run saves the current routing as the baseline and reports nothing.
+The changes not delivered to the webhook are posted first by the next poll, and a poll where a route table
+is incomplete is skipped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		webhook, _ := cmd.Flags().GetString("webhook")
+		once, _ := cmd.Flags().GetBool("once")
+		if interval <= 0 {
+			cobra.CheckErr("the interval must be greater than 0")
+		}
+		var dbAdapterWatch ports.DbPort
+		dbAdapterWatch, err := db.NewAdapter(fmt.Sprintf("%s_watch", viper.GetString("db_name")))
+		cobra.CheckErr(err)
+		defer dbAdapterWatch.CloseDbConnection()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		w := watch.New(app, dbAdapterWatch, os.Stdout, webhook)
+		if once {
+			if err := w.Poll(ctx); err != nil {
+				app.ErrorLog.Println(err)
+			}
+			return
+		}
+		progress("Watching the routing every", interval)
+		w.Run(ctx, interval)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().Duration("interval", 5*time.Minute, "how often the routing is downloaded from AWS")
+	watchCmd.Flags().String("webhook", "", "URL where each change is posted as JSON")
+	watchCmd.Flags().Bool("once", false, "poll once and exit, to run watch from cron")
+}
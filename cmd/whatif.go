@@ -0,0 +1,92 @@
+/*
+Copyright © 2022 Roger Gomez rogerscuall@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// whatifCmd represents the whatif command
+var whatifCmd = &cobra.Command{
+	Use:   "whatif <change file>",
+	Short: "Simulate changes to the routing and report the flows that change verdict",
+	Long: `Applies the changes of a YAML change file to a copy of the routing in memory and walks the flows
+before and after the changes, AWS is never modified. The changes are add-static-route, remove-static-route,
+associate, disassociate, enable-propagation, disable-propagation and delete-attachment.
+The flows are the ones in the change file or, if it has none, the flows between all the propagated prefixes.
+Only the flows that change verdict or path are printed, unless --all is set.
+With --snapshot the data saved by the sync command is used and AWS is not contacted.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.TODO()
+		snapshot, _ := cmd.Flags().GetBool("snapshot")
+		all, _ := cmd.Flags().GetBool("all")
+
+		f, err := os.Open(args[0])
+		cobra.CheckErr(err)
+		whatIf, err := awsrouter.ReadWhatIfFile(f)
+		f.Close()
+		cobra.CheckErr(err)
+
+		before, err := loadRouting(ctx, snapshot)
+		cobra.CheckErr(err)
+		after, err := awsrouter.ApplyWhatIf(before, whatIf.Changes)
+		cobra.CheckErr(err)
+		results, err := awsrouter.WhatIf(before, after, whatIf.Flows)
+		cobra.CheckErr(err)
+
+		flows := make([]output.FlowChange, 0, len(results))
+		for _, r := range results {
+			if all || r.Changed() {
+				flows = append(flows, output.NewFlowChange(r))
+			}
+		}
+		if outputFormat != output.FormatTable {
+			cobra.CheckErr(printResult(flows))
+			return
+		}
+		progress(fmt.Sprintf("%d changes applied, %d flows checked", len(whatIf.Changes), len(results)))
+		if len(flows) == 0 {
+			fmt.Println("No flow changes verdict")
+			return
+		}
+		for _, flow := range flows {
+			fmt.Printf("%s %s -> %s: %s -> %s\n", flow.TgwName, flow.Source, flow.Destination, flow.Before, flow.After)
+			fmt.Println("  before:", strings.Join(flow.BeforePath, " -> "))
+			fmt.Println("  after: ", strings.Join(flow.AfterPath, " -> "))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whatifCmd)
+
+	whatifCmd.Flags().Bool("snapshot", false, "use the routing saved by the sync command instead of AWS")
+	whatifCmd.Flags().Bool("all", false, "print also the flows that did not change")
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/directconnect"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/rogerscuall/aws-router/aws/awsrouter"
 	"github.com/rogerscuall/aws-router/ports"
@@ -18,39 +20,88 @@ type Application struct {
 	ErrorLog     *log.Logger
 }
 
+// NewApplication returns an Application with both loggers writing to stderr, stdout is reserved for the output of the commands.
 func NewApplication() *Application {
 	return &Application{
-		InfoLog:  log.New(nil, "INFO: ", log.Ldate|log.Ltime),
-		ErrorLog: log.New(nil, "ERROR: ", log.Ldate|log.Ltime),
+		InfoLog:  log.New(os.Stderr, "INFO: ", log.Ldate|log.Ltime),
+		ErrorLog: log.New(os.Stderr, "ERROR: ", log.Ldate|log.Ltime),
 	}
 }
 
 // Init will load the credentials into the application. If no credentials are found then an error will be returned.
-func (a *Application) Init() error {
+// If endpoint is not empty the EC2 and Direct Connect clients send the requests to it instead of the AWS endpoints of the region,
+// like a local fake of the EC2 API.
+func (a *Application) Init(endpoint string) error {
 	cfg, err := config.LoadDefaultConfig(context.TODO())
 	if err != nil {
 		return ErrNoDefaultAuthentication
 	}
-	a.RouterClient = ec2.NewFromConfig(cfg)
+	var optFns []func(*ec2.Options)
+	var dxOptFns []func(*directconnect.Options)
+	if endpoint != "" {
+		optFns = append(optFns, func(o *ec2.Options) {
+			o.EndpointResolver = ec2.EndpointResolverFromURL(endpoint)
+		})
+		dxOptFns = append(dxOptFns, func(o *directconnect.Options) {
+			o.EndpointResolver = directconnect.EndpointResolverFromURL(endpoint)
+		})
+	}
+	a.RouterClient = ports.NewClient(ec2.NewFromConfig(cfg, optFns...), directconnect.NewFromConfig(cfg, dxOptFns...))
 	return nil
 }
 
 // UpdateRouting will identify all the TGWs in a region. It will find all the route tables of the TGWs.
 // And it will update the routes on each route table.
+// The route tables where the routes, the associations or the propagations can not be retrieved are logged and left incomplete,
+// use SyncRouting to know which ones.
 func (app *Application) UpdateRouting(ctx context.Context) (tgws []*awsrouter.Tgw, err error) {
+	tgws, partial, err := app.SyncRouting(ctx)
+	if partial != nil {
+		app.ErrorLog.Println(partial)
+	}
+	return tgws, err
+}
+
+// SyncRouting is UpdateRouting returning the route tables that are incomplete because their routes, associations
+// or propagations could not be retrieved, partial is nil when the routing is complete.
+func (app *Application) SyncRouting(ctx context.Context) (tgws []*awsrouter.Tgw, partial *awsrouter.PartialRoutingError, err error) {
 	tgws, err = awsrouter.GetAllTgws(ctx, app.RouterClient)
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving Transit Gateways: %w", err)
+		return nil, nil, fmt.Errorf("error retrieving Transit Gateways: %w", err)
 	}
 	for _, tgw := range tgws {
-		if tgw.UpdateRouteTables(ctx, app.RouterClient); err != nil {
-			return nil, fmt.Errorf("error retrieving Transit Gateway Route Tables: %w", err)
+		if err := tgw.UpdateRouteTables(ctx, app.RouterClient); err != nil {
+			return nil, nil, fmt.Errorf("error retrieving Transit Gateway Route Tables: %w", err)
 		}
 	}
 	// Get all routes from all route tables
+	partial = &awsrouter.PartialRoutingError{}
 	for _, tgw := range tgws {
-		tgw.UpdateTgwRoutes(ctx, app.RouterClient)
-		tgw.UpdateTgwRouteTablesAttachments(ctx, app.RouterClient)
+		partial.Merge(tgw.UpdateTgwRoutes(ctx, app.RouterClient))
+		partial.Merge(tgw.UpdateTgwRouteTablesAttachments(ctx, app.RouterClient))
+		// Without the propagations the analyses infer them from the routes, the route tables are still reported as incomplete.
+		partial.Merge(tgw.UpdateTgwRouteTablesPropagations(ctx, app.RouterClient))
+		// Without the prefix lists each reference counts as one route in the quotas.
+		if err := tgw.UpdatePrefixLists(ctx, app.RouterClient); err != nil {
+			app.ErrorLog.Println(err)
+		}
+		// Without the VPN connections and Direct Connect gateways the attachments are not enriched.
+		if err := tgw.UpdateVpnConnections(ctx, app.RouterClient); err != nil {
+			app.ErrorLog.Println(err)
+		}
+		if err := tgw.UpdateDxGateways(ctx, app.RouterClient); err != nil {
+			app.ErrorLog.Println(err)
+		}
+		if err := tgw.UpdateConnects(ctx, app.RouterClient); err != nil {
+			app.ErrorLog.Println(err)
+		}
+		// Without the policy tables a path into a Cloud WAN peering is only recognised by its core network.
+		if err := tgw.UpdatePolicyTables(ctx, app.RouterClient); err != nil {
+			app.ErrorLog.Println(err)
+		}
+	}
+	if len(partial.RouteTables) == 0 {
+		partial = nil
 	}
-	return tgws, nil
+	return tgws, partial, nil
 }
@@ -0,0 +1,62 @@
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/ports"
+)
+
+// snapshotIndexKey is the key with the list of TGW IDs in a snapshot, each TGW is stored with its ID as key.
+const snapshotIndexKey = "tgw_ids"
+
+// SaveSnapshot stores the tgws in db, so they can be used later without access to AWS.
+// The TGWs of a previous snapshot that are not in tgws are not returned by LoadSnapshot anymore.
+func SaveSnapshot(db ports.DbPort, tgws []*awsrouter.Tgw) error {
+	ids := make([]string, 0, len(tgws))
+	for _, tgw := range tgws {
+		if err := db.SetVal(tgw.ID, tgw.Bytes()); err != nil {
+			return fmt.Errorf("error saving Transit Gateway %s: %w", tgw.ID, err)
+		}
+		ids = append(ids, tgw.ID)
+	}
+	b, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+	if err := db.SetVal(snapshotIndexKey, b); err != nil {
+		return fmt.Errorf("error saving the snapshot index: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot returns the TGWs stored in db by SaveSnapshot.
+// The error is ErrNoSnapshot if SaveSnapshot was never called on db.
+func LoadSnapshot(db ports.DbPort) ([]*awsrouter.Tgw, error) {
+	b, err := db.GetVal(snapshotIndexKey)
+	if errors.Is(err, ports.ErrKeyNotFound) {
+		return nil, ErrNoSnapshot
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading the snapshot index, run sync first: %w", err)
+	}
+	var ids []string
+	if err := json.Unmarshal(b, &ids); err != nil {
+		return nil, fmt.Errorf("error decoding the snapshot index: %w", err)
+	}
+	tgws := make([]*awsrouter.Tgw, 0, len(ids))
+	for _, id := range ids {
+		b, err := db.GetVal(id)
+		if err != nil {
+			return nil, fmt.Errorf("error reading Transit Gateway %s: %w", id, err)
+		}
+		tgw, err := awsrouter.NewTgwFromBytes(b)
+		if err != nil {
+			return nil, err
+		}
+		tgws = append(tgws, tgw)
+	}
+	return tgws, nil
+}
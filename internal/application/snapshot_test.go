@@ -0,0 +1,85 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/ports"
+)
+
+// memDb is a ports.DbPort in memory.
+type memDb map[string][]byte
+
+func (m memDb) CloseDbConnection() {}
+
+func (m memDb) GetVal(key string) ([]byte, error) {
+	v, ok := m[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ports.ErrKeyNotFound, key)
+	}
+	return v, nil
+}
+
+func (m memDb) SetVal(key string, val []byte) error {
+	m[key] = val
+	return nil
+}
+
+func (m memDb) Sync() {}
+
+func TestSnapshot(t *testing.T) {
+	tgws := []*awsrouter.Tgw{
+		{
+			ID:   "tgw-0a",
+			Name: "core",
+			Data: types.TransitGateway{TransitGatewayId: aws.String("tgw-0a"), State: "available"},
+			RouteTables: []*awsrouter.TgwRouteTable{
+				{
+					ID:          "tgw-rtb-0a",
+					Name:        "prod",
+					Attachments: []*awsrouter.TgwAttachment{{ID: "tgw-attach-0a", Name: "vpc-a", ResourceID: "vpc-0a", Type: "vpc"}},
+					Routes: []types.TransitGatewayRoute{
+						{
+							DestinationCidrBlock: aws.String("10.0.0.0/16"),
+							State:                "active",
+							Type:                 "propagated",
+							TransitGatewayAttachments: []types.TransitGatewayRouteAttachment{
+								{TransitGatewayAttachmentId: aws.String("tgw-attach-0a"), ResourceId: aws.String("vpc-0a"), ResourceType: "vpc"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{ID: "tgw-0b", Name: "edge"},
+	}
+	db := memDb{}
+	if _, err := LoadSnapshot(db); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("LoadSnapshot() on an empty DB error = %v, want %v", err, ErrNoSnapshot)
+	}
+	if err := SaveSnapshot(db, tgws); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+	got, err := LoadSnapshot(db)
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+	opts := cmp.Options{
+		cmpopts.IgnoreUnexported(awsrouter.TgwRouteTable{}, types.TransitGateway{}, types.TransitGatewayRoute{}, types.TransitGatewayRouteAttachment{}, types.TransitGatewayRouteTable{}),
+	}
+	if diff := cmp.Diff(tgws, got, opts); diff != "" {
+		t.Errorf("LoadSnapshot() mismatch (-want +got):\n%s", diff)
+	}
+	// The loaded route tables can be used for lookups.
+	route, err := got[0].RouteTables[0].BestRouteToIP(net.ParseIP("10.0.1.1"))
+	if err != nil || aws.StringValue(route.DestinationCidrBlock) != "10.0.0.0/16" {
+		t.Errorf("BestRouteToIP() on a loaded snapshot = %v, %v", aws.StringValue(route.DestinationCidrBlock), err)
+	}
+}
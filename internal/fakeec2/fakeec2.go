@@ -0,0 +1,573 @@
+/*
+Package fakeec2 is a fake of the EC2 query API for tests, it serves the Transit Gateways of a YAML topology.
+
+Only the operations used by ports.AWSRouter are implemented:
+DescribeTransitGateways, DescribeTransitGatewayRouteTables, SearchTransitGatewayRoutes,
+GetTransitGatewayRouteTableAssociations, GetTransitGatewayRouteTablePropagations, DescribeTransitGatewayAttachments
+DescribeManagedPrefixLists, DescribeVpnConnections, DescribeTransitGatewayConnects, DescribeTransitGatewayConnectPeers,
+DescribeTransitGatewayPolicyTables and GetTransitGatewayPolicyTableAssociations.
+The Direct Connect operation DescribeDirectConnectGatewayAssociations is answered in JSON on the same URL.
+All the results are returned in one page. A filter that is not implemented returns an InvalidParameterValue error,
+so a test does not pass by ignoring it. The requests are not authenticated.
+
+A real EC2 client is pointed to the fake with Application.Init:
+
+	srv := fakeec2.Start(topology)
+	defer srv.Close()
+	err := app.Init(srv.URL)
+*/
+package fakeec2
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+)
+
+// Server is an http.Handler that answers the EC2 requests from a Topology.
+type Server struct {
+	topology *Topology
+}
+
+// New returns a Server of the topology t.
+func New(t *Topology) *Server {
+	return &Server{topology: t}
+}
+
+// Start starts an HTTP server of the topology t, the caller should Close it.
+func Start(t *Topology) *httptest.Server {
+	return httptest.NewServer(New(t))
+}
+
+// apiError is an error returned to the client with an EC2 error code.
+type apiError struct {
+	Code    string
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if target := r.Header.Get("X-Amz-Target"); target != "" {
+		s.serveDirectConnect(w, r, target)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeError(w, &apiError{Code: "MalformedQueryString", Message: err.Error()})
+		return
+	}
+	var resp interface{}
+	var err error
+	switch action := r.Form.Get("Action"); action {
+	case "DescribeTransitGateways":
+		resp, err = s.describeTransitGateways(r.Form)
+	case "DescribeTransitGatewayRouteTables":
+		resp, err = s.describeTransitGatewayRouteTables(r.Form)
+	case "SearchTransitGatewayRoutes":
+		resp, err = s.searchTransitGatewayRoutes(r.Form)
+	case "GetTransitGatewayRouteTableAssociations":
+		resp, err = s.getTransitGatewayRouteTableAssociations(r.Form)
+	case "GetTransitGatewayRouteTablePropagations":
+		resp, err = s.getTransitGatewayRouteTablePropagations(r.Form)
+	case "DescribeTransitGatewayAttachments":
+		resp, err = s.describeTransitGatewayAttachments(r.Form)
+	case "DescribeManagedPrefixLists":
+		resp, err = s.describeManagedPrefixLists(r.Form)
+	case "DescribeVpnConnections":
+		resp, err = s.describeVpnConnections(r.Form)
+	case "DescribeTransitGatewayConnects":
+		resp, err = s.describeTransitGatewayConnects(r.Form)
+	case "DescribeTransitGatewayConnectPeers":
+		resp, err = s.describeTransitGatewayConnectPeers(r.Form)
+	case "DescribeTransitGatewayPolicyTables":
+		resp, err = s.describeTransitGatewayPolicyTables(r.Form)
+	case "GetTransitGatewayPolicyTableAssociations":
+		resp, err = s.getTransitGatewayPolicyTableAssociations(r.Form)
+	default:
+		err = &apiError{Code: "InvalidAction", Message: fmt.Sprintf("the action %s is not valid for this web service", action)}
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml;charset=UTF-8")
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) describeTransitGateways(form url.Values) (interface{}, error) {
+	ids := list(form, "TransitGatewayIds")
+	resp := describeTransitGatewaysResponse{RequestID: requestID}
+	for _, tgw := range s.topology.Tgws {
+		fields := map[string]string{"transit-gateway-id": tgw.ID, "state": "available", "owner-id": s.topology.AccountID, "tag:Name": tgw.Name}
+		ok, err := match(form, ids, tgw.ID, fields)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		resp.Tgws = append(resp.Tgws, tgwXML{
+			ID:      tgw.ID,
+			Arn:     fmt.Sprintf("arn:aws:ec2:%s:%s:transit-gateway/%s", s.topology.Region, s.topology.AccountID, tgw.ID),
+			State:   "available",
+			OwnerID: s.topology.AccountID,
+			Tags:    nameTags(tgw.Name),
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) describeTransitGatewayRouteTables(form url.Values) (interface{}, error) {
+	ids := list(form, "TransitGatewayRouteTableIds")
+	resp := describeTransitGatewayRouteTablesResponse{RequestID: requestID}
+	for _, tgw := range s.topology.Tgws {
+		for _, rt := range tgw.RouteTables {
+			fields := map[string]string{"transit-gateway-id": tgw.ID, "transit-gateway-route-table-id": rt.ID, "state": "available", "tag:Name": rt.Name}
+			ok, err := match(form, ids, rt.ID, fields)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			resp.RouteTables = append(resp.RouteTables, routeTableXML{
+				ID:                 rt.ID,
+				TgwID:              tgw.ID,
+				State:              "available",
+				DefaultAssociation: rt.DefaultAssociation,
+				DefaultPropagation: rt.DefaultPropagation,
+				Tags:               nameTags(rt.Name),
+			})
+		}
+	}
+	return resp, nil
+}
+
+func (s *Server) searchTransitGatewayRoutes(form url.Values) (interface{}, error) {
+	tgw, rt, err := s.routeTable(form.Get("TransitGatewayRouteTableId"))
+	if err != nil {
+		return nil, err
+	}
+	resp := searchTransitGatewayRoutesResponse{RequestID: requestID}
+	for _, route := range rt.Routes {
+		fields := map[string]string{"state": route.State, "type": route.Type, "route-search.exact-match": route.Destination, "prefix-list-id": route.PrefixListID}
+		ok, err := match(form, nil, "", fields)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		r := routeXML{Destination: route.Destination, PrefixListID: route.PrefixListID, Type: route.Type, State: route.State}
+		for _, id := range route.Attachments {
+			att := tgw.attachment(id)
+			r.Attachments = append(r.Attachments, routeAttachmentXML{ID: att.ID, ResourceID: att.ResourceID, ResourceType: att.Type})
+		}
+		resp.Routes = append(resp.Routes, r)
+	}
+	return resp, nil
+}
+
+func (s *Server) getTransitGatewayRouteTableAssociations(form url.Values) (interface{}, error) {
+	tgw, rt, err := s.routeTable(form.Get("TransitGatewayRouteTableId"))
+	if err != nil {
+		return nil, err
+	}
+	resp := getTransitGatewayRouteTableAssociationsResponse{RequestID: requestID}
+	for _, att := range tgw.Attachments {
+		if att.RouteTable != rt.ID {
+			continue
+		}
+		fields := map[string]string{"transit-gateway-attachment-id": att.ID, "resource-id": att.ResourceID, "resource-type": att.Type}
+		ok, err := match(form, nil, "", fields)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		resp.Associations = append(resp.Associations, associationXML{ID: att.ID, ResourceID: att.ResourceID, ResourceType: att.Type, State: "associated"})
+	}
+	return resp, nil
+}
+
+func (s *Server) getTransitGatewayRouteTablePropagations(form url.Values) (interface{}, error) {
+	tgw, rt, err := s.routeTable(form.Get("TransitGatewayRouteTableId"))
+	if err != nil {
+		return nil, err
+	}
+	resp := getTransitGatewayRouteTablePropagationsResponse{RequestID: requestID}
+	for _, att := range tgw.Attachments {
+		if !contains(att.Propagations, rt.ID) {
+			continue
+		}
+		fields := map[string]string{"transit-gateway-attachment-id": att.ID, "resource-id": att.ResourceID, "resource-type": att.Type, "state": "enabled"}
+		ok, err := match(form, nil, "", fields)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		resp.Propagations = append(resp.Propagations, propagationXML{ID: att.ID, ResourceID: att.ResourceID, ResourceType: att.Type, State: "enabled"})
+	}
+	return resp, nil
+}
+
+func (s *Server) describeTransitGatewayAttachments(form url.Values) (interface{}, error) {
+	ids := list(form, "TransitGatewayAttachmentIds")
+	resp := describeTransitGatewayAttachmentsResponse{RequestID: requestID}
+	for _, tgw := range s.topology.Tgws {
+		for _, att := range tgw.Attachments {
+			fields := map[string]string{
+				"transit-gateway-attachment-id": att.ID,
+				"transit-gateway-id":            tgw.ID,
+				"resource-id":                   att.ResourceID,
+				"resource-type":                 att.Type,
+				"resource-owner-id":             att.OwnerID,
+				"state":                         "available",
+				"association.transit-gateway-route-table-id": att.RouteTable,
+				"tag:Name": att.Name,
+			}
+			ok, err := match(form, ids, att.ID, fields)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			a := attachmentXML{
+				ID:            att.ID,
+				TgwID:         tgw.ID,
+				TgwOwnerID:    s.topology.AccountID,
+				ResourceOwner: att.OwnerID,
+				ResourceType:  att.Type,
+				ResourceID:    att.ResourceID,
+				State:         "available",
+				Tags:          nameTags(att.Name),
+			}
+			if att.RouteTable != "" {
+				a.Association = &attachmentAssociationXML{RouteTableID: att.RouteTable, State: "associated"}
+			}
+			resp.Attachments = append(resp.Attachments, a)
+		}
+	}
+	return resp, nil
+}
+
+func (s *Server) describeManagedPrefixLists(form url.Values) (interface{}, error) {
+	ids := list(form, "PrefixListId")
+	resp := describeManagedPrefixListsResponse{RequestID: requestID}
+	for _, pl := range s.topology.PrefixLists {
+		fields := map[string]string{"prefix-list-id": pl.ID, "prefix-list-name": pl.Name, "owner-id": s.topology.AccountID}
+		ok, err := match(form, ids, pl.ID, fields)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		resp.PrefixLists = append(resp.PrefixLists, prefixListXML{
+			ID:            pl.ID,
+			Name:          pl.Name,
+			AddressFamily: "IPv4",
+			MaxEntries:    pl.MaxEntries,
+			OwnerID:       s.topology.AccountID,
+			State:         "create-complete",
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) describeVpnConnections(form url.Values) (interface{}, error) {
+	ids := list(form, "VpnConnectionId")
+	resp := describeVpnConnectionsResponse{RequestID: requestID}
+	for _, tgw := range s.topology.Tgws {
+		for _, att := range tgw.Attachments {
+			if att.Type != "vpn" {
+				continue
+			}
+			fields := map[string]string{"vpn-connection-id": att.ResourceID, "transit-gateway-id": tgw.ID, "state": "available", "type": "ipsec.1"}
+			ok, err := match(form, ids, att.ResourceID, fields)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			vpn := vpnConnectionXML{ID: att.ResourceID, State: "available", Type: "ipsec.1", TgwID: tgw.ID}
+			for _, tunnel := range att.Tunnels {
+				vpn.Telemetry = append(vpn.Telemetry, vpnTelemetryXML(tunnel))
+			}
+			resp.VpnConnections = append(resp.VpnConnections, vpn)
+		}
+	}
+	return resp, nil
+}
+
+func (s *Server) describeTransitGatewayConnects(form url.Values) (interface{}, error) {
+	ids := list(form, "TransitGatewayAttachmentIds")
+	resp := describeTransitGatewayConnectsResponse{RequestID: requestID}
+	for _, tgw := range s.topology.Tgws {
+		for _, att := range tgw.Attachments {
+			if att.Type != "connect" {
+				continue
+			}
+			fields := map[string]string{
+				"transit-gateway-attachment-id":           att.ID,
+				"transit-gateway-id":                      tgw.ID,
+				"transport-transit-gateway-attachment-id": att.Transport,
+				"state": "available",
+			}
+			ok, err := match(form, ids, att.ID, fields)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			resp.Connects = append(resp.Connects, connectXML{
+				ID:          att.ID,
+				TransportID: att.Transport,
+				TgwID:       tgw.ID,
+				State:       "available",
+				Options:     connectOptionsXML{Protocol: "gre"},
+				Tags:        nameTags(att.Name),
+			})
+		}
+	}
+	return resp, nil
+}
+
+func (s *Server) describeTransitGatewayConnectPeers(form url.Values) (interface{}, error) {
+	ids := list(form, "TransitGatewayConnectPeerIds")
+	resp := describeTransitGatewayConnectPeersResponse{RequestID: requestID}
+	for _, tgw := range s.topology.Tgws {
+		for _, att := range tgw.Attachments {
+			for _, peer := range att.Peers {
+				fields := map[string]string{
+					"transit-gateway-attachment-id":   att.ID,
+					"transit-gateway-connect-peer-id": peer.ID,
+					"state":                           "available",
+				}
+				ok, err := match(form, ids, peer.ID, fields)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+				config := connectPeerConfigurationXML{PeerAddress: peer.PeerAddress, TgwAddress: peer.TgwAddress, Protocol: "gre"}
+				if peer.InsideCidr != "" {
+					config.InsideCidrBlocks = []string{peer.InsideCidr}
+				}
+				for i, status := range peer.BgpStatus {
+					// The BGP addresses are the first ones of the inside CIDR, the peer then the Transit Gateway.
+					config.BgpConfigurations = append(config.BgpConfigurations, bgpConfigurationXML{
+						PeerAddress: insideAddress(peer.InsideCidr, 1),
+						PeerAsn:     peer.PeerAsn,
+						TgwAddress:  insideAddress(peer.InsideCidr, 2+i),
+						TgwAsn:      64512,
+						Status:      status,
+					})
+				}
+				resp.Peers = append(resp.Peers, connectPeerXML{ID: peer.ID, AttachmentID: att.ID, State: "available", Configuration: config})
+			}
+		}
+	}
+	return resp, nil
+}
+
+// insideAddress returns the address n of the IPv4 CIDR cidr, or an empty string if it is not valid.
+func insideAddress(cidr string, n int) string {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil || network.IP.To4() == nil {
+		return ""
+	}
+	ip := network.IP.To4()
+	return net.IPv4(ip[0], ip[1], ip[2], ip[3]+byte(n)).String()
+}
+
+// serveDirectConnect answers a request of the Direct Connect JSON API, target is the operation in the X-Amz-Target header.
+func (s *Server) describeTransitGatewayPolicyTables(form url.Values) (interface{}, error) {
+	ids := list(form, "TransitGatewayPolicyTableIds")
+	resp := describeTransitGatewayPolicyTablesResponse{RequestID: requestID}
+	for _, tgw := range s.topology.Tgws {
+		for _, pt := range tgw.PolicyTables {
+			fields := map[string]string{"transit-gateway-id": tgw.ID, "transit-gateway-policy-table-id": pt.ID, "state": "available"}
+			ok, err := match(form, ids, pt.ID, fields)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			resp.PolicyTables = append(resp.PolicyTables, policyTableXML{ID: pt.ID, TgwID: tgw.ID, State: "available", Tags: nameTags(pt.Name)})
+		}
+	}
+	return resp, nil
+}
+
+func (s *Server) getTransitGatewayPolicyTableAssociations(form url.Values) (interface{}, error) {
+	id := form.Get("TransitGatewayPolicyTableId")
+	if id == "" {
+		return nil, &apiError{Code: "MissingParameter", Message: "the request must contain the parameter TransitGatewayPolicyTableId"}
+	}
+	for _, tgw := range s.topology.Tgws {
+		if tgw.policyTable(id) == nil {
+			continue
+		}
+		resp := getTransitGatewayPolicyTableAssociationsResponse{RequestID: requestID}
+		for _, att := range tgw.Attachments {
+			if att.PolicyTable != id {
+				continue
+			}
+			fields := map[string]string{"transit-gateway-attachment-id": att.ID, "resource-id": att.ResourceID, "resource-type": att.Type}
+			ok, err := match(form, nil, "", fields)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			resp.Associations = append(resp.Associations, policyTableAssociationXML{
+				PolicyTableID: id,
+				ID:            att.ID,
+				ResourceID:    att.ResourceID,
+				ResourceType:  att.Type,
+				State:         "associated",
+			})
+		}
+		return resp, nil
+	}
+	return nil, &apiError{Code: "InvalidTransitGatewayPolicyTableId.NotFound", Message: fmt.Sprintf("the policy table ID '%s' does not exist", id)}
+}
+
+func (s *Server) serveDirectConnect(w http.ResponseWriter, r *http.Request, target string) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	if target != "OvertureService.DescribeDirectConnectGatewayAssociations" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"__type": "UnknownOperationException", "message": fmt.Sprintf("the operation %s is not supported", target)})
+		return
+	}
+	var input struct {
+		AssociatedGatewayID string `json:"associatedGatewayId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"__type": "SerializationException", "message": err.Error()})
+		return
+	}
+	resp := describeDirectConnectGatewayAssociationsResponse{Associations: []dxAssociationJSON{}}
+	for _, tgw := range s.topology.Tgws {
+		if input.AssociatedGatewayID != "" && input.AssociatedGatewayID != tgw.ID {
+			continue
+		}
+		for _, att := range tgw.Attachments {
+			if att.Type != "direct-connect-gateway" {
+				continue
+			}
+			association := dxAssociationJSON{
+				DxGatewayID:       att.ResourceID,
+				State:             "associated",
+				AssociatedGateway: dxAssociatedGatewayJSON{ID: tgw.ID, Type: "transitGateway"},
+				AllowedPrefixes:   []dxPrefixJSON{},
+			}
+			for _, prefix := range att.AllowedPrefixes {
+				association.AllowedPrefixes = append(association.AllowedPrefixes, dxPrefixJSON{Cidr: prefix})
+			}
+			resp.Associations = append(resp.Associations, association)
+		}
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// routeTable returns the route table with the ID id and its Transit Gateway.
+func (s *Server) routeTable(id string) (*Tgw, *RouteTable, error) {
+	if id == "" {
+		return nil, nil, &apiError{Code: "MissingParameter", Message: "the request must contain the parameter TransitGatewayRouteTableId"}
+	}
+	for i := range s.topology.Tgws {
+		tgw := &s.topology.Tgws[i]
+		for j := range tgw.RouteTables {
+			if tgw.RouteTables[j].ID == id {
+				return tgw, &tgw.RouteTables[j], nil
+			}
+		}
+	}
+	return nil, nil, &apiError{Code: "InvalidRouteTableID.NotFound", Message: fmt.Sprintf("the routeTable ID '%s' does not exist", id)}
+}
+
+// list returns the values of the list parameter key, sent as key.1, key.2...
+func list(form url.Values, key string) []string {
+	var result []string
+	for i := 1; ; i++ {
+		v, ok := form[fmt.Sprintf("%s.%d", key, i)]
+		if !ok {
+			return result
+		}
+		result = append(result, v...)
+	}
+}
+
+// filters returns the values of the filters of the request by name, the values of the filters with the same name are merged.
+func filters(form url.Values) map[string][]string {
+	result := make(map[string][]string)
+	for i := 1; ; i++ {
+		name := form.Get(fmt.Sprintf("Filter.%d.Name", i))
+		if name == "" {
+			return result
+		}
+		result[name] = append(result[name], list(form, fmt.Sprintf("Filter.%d.Value", i))...)
+	}
+}
+
+// match reports if a resource with the ID id and the filter fields is selected by the IDs and the filters of the request.
+// An empty ids selects all the resources.
+func match(form url.Values, ids []string, id string, fields map[string]string) (bool, error) {
+	if len(ids) > 0 && !contains(ids, id) {
+		return false, nil
+	}
+	for name, values := range filters(form) {
+		field, ok := fields[name]
+		if !ok {
+			return false, &apiError{Code: "InvalidParameterValue", Message: fmt.Sprintf("the filter '%s' is invalid", name)}
+		}
+		if !contains(values, field) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// writeError writes err as an EC2 error response.
+func writeError(w http.ResponseWriter, err error) {
+	e, ok := err.(*apiError)
+	if !ok {
+		e = &apiError{Code: "InternalError", Message: err.Error()}
+	}
+	status := http.StatusBadRequest
+	if e.Code == "InternalError" {
+		status = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "text/xml;charset=UTF-8")
+	w.WriteHeader(status)
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(errorResponse{Errors: []errorXML{{Code: e.Code, Message: e.Message}}, RequestID: requestID})
+}
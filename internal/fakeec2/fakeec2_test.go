@@ -0,0 +1,262 @@
+package fakeec2
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/go-cmp/cmp"
+	"github.com/rogerscuall/aws-router/internal/application"
+	"github.com/rogerscuall/aws-router/internal/output"
+	"github.com/rogerscuall/aws-router/ports"
+)
+
+// newApplication returns an Application with a real EC2 client that sends the requests to a fake of the topology in fileName.
+func newApplication(t *testing.T, fileName string) *application.Application {
+	t.Helper()
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_CONFIG_FILE", "testdata/missing")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "testdata/missing")
+	topology, err := LoadTopology(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := Start(topology)
+	t.Cleanup(srv.Close)
+	app := &application.Application{InfoLog: log.New(io.Discard, "", 0), ErrorLog: log.New(io.Discard, "", 0)}
+	if err := app.Init(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+func TestUpdateRouting(t *testing.T) {
+	app := newApplication(t, "testdata/topology.yaml")
+	tgws, err := app.UpdateRouting(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	vpcA := output.Attachment{ID: "tgw-attach-0a", Name: "vpc-a", ResourceID: "vpc-0a", Type: "vpc"}
+	vpcB := output.Attachment{ID: "tgw-attach-0b", Name: "vpc-b", ResourceID: "vpc-0b", Type: "vpc"}
+	// The next hops of the routes only have a name if they are associated to the same route table.
+	nextHopA := output.Attachment{ID: "tgw-attach-0a", ResourceID: "vpc-0a", Type: "vpc"}
+	nextHopB := output.Attachment{ID: "tgw-attach-0b", ResourceID: "vpc-0b", Type: "vpc"}
+	want := []output.Tgw{
+		{
+			ID:      "tgw-0a",
+			Name:    "core",
+			State:   "available",
+			OwnerID: "111111111111",
+			RouteTables: []output.RouteTable{
+				{
+					ID:                 "tgw-rtb-0a",
+					Name:               "spokes",
+					TgwID:              "tgw-0a",
+					DefaultAssociation: true,
+					Attachments:        []output.Attachment{vpcA},
+					Propagations:       []output.Attachment{vpcA, vpcB},
+					Routes: []output.Route{
+						{Destination: "10.0.0.0/16", State: "active", Type: "propagated", Attachments: []output.Attachment{vpcA}},
+						{Destination: "10.1.0.0/16", State: "active", Type: "propagated", Attachments: []output.Attachment{nextHopB}},
+						{Destination: "10.1.2.0/24", State: "blackhole", Type: "static", Attachments: []output.Attachment{}},
+					},
+				},
+				{
+					ID:                 "tgw-rtb-0b",
+					Name:               "shared",
+					TgwID:              "tgw-0a",
+					DefaultPropagation: true,
+					Attachments:        []output.Attachment{vpcB},
+					Propagations:       []output.Attachment{},
+					Routes: []output.Route{
+						{Destination: "10.0.0.0/8", State: "active", Type: "static", Attachments: []output.Attachment{nextHopA}},
+					},
+				},
+			},
+			Vpns: []output.Vpn{
+				{
+					ID:             "vpn-0c",
+					State:          "available",
+					TunnelsUp:      1,
+					AcceptedRoutes: 4,
+					Tunnels: []output.VpnTunnel{
+						{OutsideIP: "203.0.113.1", Status: "UP", AcceptedRoutes: 4},
+						{OutsideIP: "203.0.113.2", Status: "DOWN", StatusMessage: "IPSEC IS DOWN"},
+					},
+				},
+			},
+			DxGateways: []output.DxGateway{{ID: "dxgw-0d", State: "associated", AllowedPrefixes: []string{"10.0.0.0/8"}}},
+			Connects: []output.Connect{
+				{
+					ID:                    "tgw-attach-0e",
+					TransportAttachmentID: "tgw-attach-0b",
+					State:                 "available",
+					Protocol:              "gre",
+					PeersUp:               1,
+					Peers: []output.ConnectPeer{
+						{
+							ID:               "tgw-connect-peer-0e",
+							State:            "available",
+							PeerAddress:      "10.1.0.10",
+							TgwAddress:       "192.0.2.1",
+							InsideCidrBlocks: []string{"169.254.6.0/29"},
+							BgpSessions: []output.BgpSession{
+								{PeerAddress: "169.254.6.1", PeerAsn: 65001, TgwAddress: "169.254.6.2", TgwAsn: 64512, Status: "up"},
+								{PeerAddress: "169.254.6.1", PeerAsn: 65001, TgwAddress: "169.254.6.3", TgwAsn: 64512, Status: "down"},
+							},
+						},
+					},
+				},
+			},
+			PolicyTables: []output.PolicyTable{
+				{
+					ID:          "tgw-ptb-0f",
+					Name:        "cloudwan",
+					State:       "available",
+					Attachments: []output.Attachment{{ID: "tgw-attach-0f", ResourceID: "core-network-0f", Type: "peering"}},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, output.NewTgws(tgws)); diff != "" {
+		t.Errorf("UpdateRouting() mismatch (-want +got):\n%s", diff)
+	}
+	if got := tgws[0].RouteTables[1].Attachments[0].OwnerID; got != "222222222222" {
+		t.Errorf("attachment owner = %v, want 222222222222", got)
+	}
+}
+
+func TestRequests(t *testing.T) {
+	app := newApplication(t, "testdata/topology.yaml")
+	api := app.RouterClient
+	ctx := context.TODO()
+
+	atts, err := api.DescribeTransitGatewayAttachments(ctx, &ec2.DescribeTransitGatewayAttachmentsInput{
+		Filters: []types.Filter{{Name: aws.String("resource-id"), Values: []string{"vpn-0c", "vpc-0b"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, att := range atts.TransitGatewayAttachments {
+		ids = append(ids, aws.StringValue(att.TransitGatewayAttachmentId))
+	}
+	if want := []string{"tgw-attach-0b", "tgw-attach-0c"}; !cmp.Equal(want, ids) {
+		t.Errorf("DescribeTransitGatewayAttachments() = %v, want %v", ids, want)
+	}
+	if atts.TransitGatewayAttachments[1].Association != nil {
+		t.Errorf("DescribeTransitGatewayAttachments() association of tgw-attach-0c = %+v, want nil", atts.TransitGatewayAttachments[1].Association)
+	}
+
+	routes, err := api.SearchTransitGatewayRoutes(ctx, &ec2.SearchTransitGatewayRoutesInput{
+		TransitGatewayRouteTableId: aws.String("tgw-rtb-0a"),
+		Filters:                    []types.Filter{{Name: aws.String("state"), Values: []string{"blackhole"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes.Routes) != 1 || aws.StringValue(routes.Routes[0].DestinationCidrBlock) != "10.1.2.0/24" {
+		t.Errorf("SearchTransitGatewayRoutes() = %+v, want the blackhole 10.1.2.0/24", routes.Routes)
+	}
+
+	pls, err := api.DescribeManagedPrefixLists(ctx, ports.PrefixListInputFilter([]string{"pl-0a"}, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pls.PrefixLists) != 1 || aws.StringValue(pls.PrefixLists[0].PrefixListName) != "offices" || *pls.PrefixLists[0].MaxEntries != 20 {
+		t.Errorf("DescribeManagedPrefixLists() = %+v, want offices with 20 entries", pls.PrefixLists)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want string
+	}{
+		{
+			name: "Unknown Route Table",
+			call: func() error {
+				_, err := api.SearchTransitGatewayRoutes(ctx, ports.TgwSearchRoutesInputFilter("tgw-rtb-0z"))
+				return err
+			},
+			want: "InvalidRouteTableID.NotFound",
+		},
+		{
+			name: "Unknown Policy Table",
+			call: func() error {
+				_, err := api.GetTransitGatewayPolicyTableAssociations(ctx, ports.TgwPolicyTableAssociationInputFilter("tgw-ptb-0z", ""))
+				return err
+			},
+			want: "InvalidTransitGatewayPolicyTableId.NotFound",
+		},
+		{
+			name: "Unknown Filter",
+			call: func() error {
+				_, err := api.DescribeTransitGateways(ctx, &ec2.DescribeTransitGatewaysInput{Filters: []types.Filter{{Name: aws.String("options.amazon-side-asn"), Values: []string{"64512"}}}})
+				return err
+			},
+			want: "InvalidParameterValue",
+		},
+		{
+			// Like AWS, the Connect peers can not be filtered by Transit Gateway.
+			name: "Connect Peers By Transit Gateway",
+			call: func() error {
+				_, err := api.DescribeTransitGatewayConnectPeers(ctx, &ec2.DescribeTransitGatewayConnectPeersInput{Filters: []types.Filter{{Name: aws.String("transit-gateway-id"), Values: []string{"tgw-0a"}}}})
+				return err
+			},
+			want: "InvalidParameterValue",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadTopology(t *testing.T) {
+	_, err := ReadTopology(strings.NewReader(`
+transit_gateways:
+  - id: tgw-0a
+    route_tables:
+      - id: tgw-rtb-0a
+        routes:
+          - destination: 10.0.0.0/16
+            attachments: [tgw-attach-0z]
+`))
+	if err == nil || !strings.Contains(err.Error(), "unknown attachment tgw-attach-0z") {
+		t.Errorf("ReadTopology() error = %v, want unknown attachment", err)
+	}
+	_, err = ReadTopology(strings.NewReader(`
+transit_gateways:
+  - id: tgw-0a
+    attachments:
+      - id: tgw-attach-0c
+        type: connect
+        transport: tgw-attach-0z
+`))
+	if err == nil || !strings.Contains(err.Error(), "over unknown attachment tgw-attach-0z") {
+		t.Errorf("ReadTopology() error = %v, want unknown transport attachment", err)
+	}
+	_, err = ReadTopology(strings.NewReader(`
+transit_gateways:
+  - id: tgw-0a
+    attachments:
+      - id: tgw-attach-0f
+        type: peering
+        resource_id: core-network-0f
+        policy_table: tgw-ptb-0z
+`))
+	if err == nil || !strings.Contains(err.Error(), "unknown policy table tgw-ptb-0z") {
+		t.Errorf("ReadTopology() error = %v, want unknown policy table", err)
+	}
+}
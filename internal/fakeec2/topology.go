@@ -0,0 +1,191 @@
+package fakeec2
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Topology is the content of a YAML topology file, the Transit Gateways served by a Server.
+type Topology struct {
+	AccountID   string       `yaml:"account_id"`
+	Region      string       `yaml:"region"`
+	Tgws        []Tgw        `yaml:"transit_gateways"`
+	PrefixLists []PrefixList `yaml:"prefix_lists"`
+}
+
+// PrefixList is a managed prefix list of the topology, the routes refer to it by ID.
+type PrefixList struct {
+	ID         string `yaml:"id"`
+	Name       string `yaml:"name"`
+	MaxEntries int    `yaml:"max_entries"`
+}
+
+// Tgw is a Transit Gateway of the topology.
+// PolicyTables are the policy tables used by the peerings with a Cloud WAN core network.
+type Tgw struct {
+	ID           string        `yaml:"id"`
+	Name         string        `yaml:"name"`
+	RouteTables  []RouteTable  `yaml:"route_tables"`
+	PolicyTables []PolicyTable `yaml:"policy_tables"`
+	Attachments  []Attachment  `yaml:"attachments"`
+}
+
+// PolicyTable is a Transit Gateway policy table of the topology.
+type PolicyTable struct {
+	ID   string `yaml:"id"`
+	Name string `yaml:"name"`
+}
+
+// RouteTable is a Transit Gateway Route Table of the topology.
+type RouteTable struct {
+	ID                 string  `yaml:"id"`
+	Name               string  `yaml:"name"`
+	DefaultAssociation bool    `yaml:"default_association"`
+	DefaultPropagation bool    `yaml:"default_propagation"`
+	Routes             []Route `yaml:"routes"`
+}
+
+// Route is a route of a Transit Gateway Route Table.
+// Type is static by default. State is active by default, or blackhole for a route without attachments.
+type Route struct {
+	Destination  string   `yaml:"destination"`
+	PrefixListID string   `yaml:"prefix_list_id"`
+	Type         string   `yaml:"type"`
+	State        string   `yaml:"state"`
+	Attachments  []string `yaml:"attachments"`
+}
+
+// Attachment is a Transit Gateway Attachment of the topology.
+// RouteTable is the ID of the associated route table, empty if the attachment is not associated.
+// Propagations are the IDs of the route tables where the attachment has an enabled propagation.
+// OwnerID is the account ID of the topology by default.
+// Tunnels are the tunnels of a vpn attachment, AllowedPrefixes are the allowed prefixes of a direct-connect-gateway attachment.
+// Transport is the ID of the attachment a connect attachment runs over, its ResourceID by default, and Peers are its peers.
+// PolicyTable is the ID of the associated policy table, like a peering with a Cloud WAN core network.
+type Attachment struct {
+	ID              string        `yaml:"id"`
+	Name            string        `yaml:"name"`
+	Type            string        `yaml:"type"`
+	ResourceID      string        `yaml:"resource_id"`
+	OwnerID         string        `yaml:"owner_id"`
+	RouteTable      string        `yaml:"route_table"`
+	Propagations    []string      `yaml:"propagations"`
+	Tunnels         []Tunnel      `yaml:"tunnels"`
+	AllowedPrefixes []string      `yaml:"allowed_prefixes"`
+	Transport       string        `yaml:"transport"`
+	Peers           []ConnectPeer `yaml:"peers"`
+	PolicyTable     string        `yaml:"policy_table"`
+}
+
+// Tunnel is a tunnel of a VPN attachment. Status is UP or DOWN, AcceptedRoutes are the routes learned with BGP.
+type Tunnel struct {
+	OutsideIP      string `yaml:"outside_ip"`
+	Status         string `yaml:"status"`
+	StatusMessage  string `yaml:"status_message"`
+	AcceptedRoutes int    `yaml:"accepted_routes"`
+}
+
+// ConnectPeer is a peer of a Connect attachment. BgpStatus has the status of each BGP session, up or down.
+type ConnectPeer struct {
+	ID          string   `yaml:"id"`
+	PeerAddress string   `yaml:"peer_address"`
+	TgwAddress  string   `yaml:"tgw_address"`
+	InsideCidr  string   `yaml:"inside_cidr"`
+	PeerAsn     int64    `yaml:"peer_asn"`
+	BgpStatus   []string `yaml:"bgp_status"`
+}
+
+// ReadTopology reads a YAML topology and fills the default values.
+func ReadTopology(r io.Reader) (*Topology, error) {
+	var t Topology
+	if err := yaml.NewDecoder(r).Decode(&t); err != nil {
+		return nil, fmt.Errorf("error reading the topology: %w", err)
+	}
+	if t.AccountID == "" {
+		t.AccountID = "123456789012"
+	}
+	if t.Region == "" {
+		t.Region = "us-east-1"
+	}
+	for i := range t.Tgws {
+		tgw := &t.Tgws[i]
+		for j := range tgw.Attachments {
+			att := &tgw.Attachments[j]
+			if att.OwnerID == "" {
+				att.OwnerID = t.AccountID
+			}
+			if att.Type == "" {
+				att.Type = "vpc"
+			}
+			if att.Type == "connect" {
+				if att.Transport == "" {
+					att.Transport = att.ResourceID
+				}
+				if att.ResourceID == "" {
+					att.ResourceID = att.Transport
+				}
+			}
+		}
+		for _, att := range tgw.Attachments {
+			if att.Type == "connect" && tgw.attachment(att.Transport) == nil {
+				return nil, fmt.Errorf("error reading the topology: connect %s over unknown attachment %s", att.ID, att.Transport)
+			}
+			if att.PolicyTable != "" && tgw.policyTable(att.PolicyTable) == nil {
+				return nil, fmt.Errorf("error reading the topology: attachment %s associated to unknown policy table %s", att.ID, att.PolicyTable)
+			}
+		}
+		for j := range tgw.RouteTables {
+			for k := range tgw.RouteTables[j].Routes {
+				route := &tgw.RouteTables[j].Routes[k]
+				if route.Type == "" {
+					route.Type = "static"
+				}
+				if route.State == "" {
+					route.State = "active"
+					if len(route.Attachments) == 0 {
+						route.State = "blackhole"
+					}
+				}
+				for _, id := range route.Attachments {
+					if tgw.attachment(id) == nil {
+						return nil, fmt.Errorf("error reading the topology: route %s to unknown attachment %s", route.Destination, id)
+					}
+				}
+			}
+		}
+	}
+	return &t, nil
+}
+
+// LoadTopology reads the YAML topology in the file fileName.
+func LoadTopology(fileName string) (*Topology, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("error opening the topology: %w", err)
+	}
+	defer f.Close()
+	return ReadTopology(f)
+}
+
+// attachment returns the attachment with the ID id, or nil if the Tgw has no such attachment.
+func (t *Tgw) attachment(id string) *Attachment {
+	for i := range t.Attachments {
+		if t.Attachments[i].ID == id {
+			return &t.Attachments[i]
+		}
+	}
+	return nil
+}
+
+// policyTable returns the policy table with the ID id, or nil if the Tgw has no such policy table.
+func (t *Tgw) policyTable(id string) *PolicyTable {
+	for i := range t.PolicyTables {
+		if t.PolicyTables[i].ID == id {
+			return &t.PolicyTables[i]
+		}
+	}
+	return nil
+}
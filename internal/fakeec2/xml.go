@@ -0,0 +1,265 @@
+package fakeec2
+
+import "encoding/xml"
+
+// requestID is the request ID of every response.
+const requestID = "00000000-0000-0000-0000-000000000000"
+
+// The types below are the XML documents of the EC2 responses, only with the fields used by aws-router.
+
+type tagXML struct {
+	Key   string `xml:"key"`
+	Value string `xml:"value"`
+}
+
+// nameTags returns the Name tag, or no tags if name is empty.
+func nameTags(name string) []tagXML {
+	if name == "" {
+		return nil
+	}
+	return []tagXML{{Key: "Name", Value: name}}
+}
+
+type tgwXML struct {
+	ID      string   `xml:"transitGatewayId"`
+	Arn     string   `xml:"transitGatewayArn"`
+	State   string   `xml:"state"`
+	OwnerID string   `xml:"ownerId"`
+	Tags    []tagXML `xml:"tagSet>item"`
+}
+
+type describeTransitGatewaysResponse struct {
+	XMLName   xml.Name `xml:"http://ec2.amazonaws.com/doc/2016-11-15/ DescribeTransitGatewaysResponse"`
+	RequestID string   `xml:"requestId"`
+	Tgws      []tgwXML `xml:"transitGatewaySet>item"`
+}
+
+type routeTableXML struct {
+	ID                 string   `xml:"transitGatewayRouteTableId"`
+	TgwID              string   `xml:"transitGatewayId"`
+	State              string   `xml:"state"`
+	DefaultAssociation bool     `xml:"defaultAssociationRouteTable"`
+	DefaultPropagation bool     `xml:"defaultPropagationRouteTable"`
+	Tags               []tagXML `xml:"tagSet>item"`
+}
+
+type describeTransitGatewayRouteTablesResponse struct {
+	XMLName     xml.Name        `xml:"http://ec2.amazonaws.com/doc/2016-11-15/ DescribeTransitGatewayRouteTablesResponse"`
+	RequestID   string          `xml:"requestId"`
+	RouteTables []routeTableXML `xml:"transitGatewayRouteTables>item"`
+}
+
+type routeAttachmentXML struct {
+	ID           string `xml:"transitGatewayAttachmentId"`
+	ResourceID   string `xml:"resourceId"`
+	ResourceType string `xml:"resourceType"`
+}
+
+type routeXML struct {
+	Destination  string               `xml:"destinationCidrBlock,omitempty"`
+	PrefixListID string               `xml:"prefixListId,omitempty"`
+	Type         string               `xml:"type"`
+	State        string               `xml:"state"`
+	Attachments  []routeAttachmentXML `xml:"transitGatewayAttachments>item"`
+}
+
+type searchTransitGatewayRoutesResponse struct {
+	XMLName   xml.Name   `xml:"http://ec2.amazonaws.com/doc/2016-11-15/ SearchTransitGatewayRoutesResponse"`
+	RequestID string     `xml:"requestId"`
+	Routes    []routeXML `xml:"routeSet>item"`
+	// AdditionalRoutesAvailable is always false, all the routes are returned.
+	AdditionalRoutesAvailable bool `xml:"additionalRoutesAvailable"`
+}
+
+type associationXML struct {
+	ID           string `xml:"transitGatewayAttachmentId"`
+	ResourceID   string `xml:"resourceId"`
+	ResourceType string `xml:"resourceType"`
+	State        string `xml:"state"`
+}
+
+type getTransitGatewayRouteTableAssociationsResponse struct {
+	XMLName      xml.Name         `xml:"http://ec2.amazonaws.com/doc/2016-11-15/ GetTransitGatewayRouteTableAssociationsResponse"`
+	RequestID    string           `xml:"requestId"`
+	Associations []associationXML `xml:"associations>item"`
+}
+
+type propagationXML struct {
+	ID           string `xml:"transitGatewayAttachmentId"`
+	ResourceID   string `xml:"resourceId"`
+	ResourceType string `xml:"resourceType"`
+	State        string `xml:"state"`
+}
+
+type getTransitGatewayRouteTablePropagationsResponse struct {
+	XMLName      xml.Name         `xml:"http://ec2.amazonaws.com/doc/2016-11-15/ GetTransitGatewayRouteTablePropagationsResponse"`
+	RequestID    string           `xml:"requestId"`
+	Propagations []propagationXML `xml:"transitGatewayRouteTablePropagations>item"`
+}
+
+type attachmentAssociationXML struct {
+	RouteTableID string `xml:"transitGatewayRouteTableId"`
+	State        string `xml:"state"`
+}
+
+type attachmentXML struct {
+	ID            string                    `xml:"transitGatewayAttachmentId"`
+	TgwID         string                    `xml:"transitGatewayId"`
+	TgwOwnerID    string                    `xml:"transitGatewayOwnerId"`
+	ResourceOwner string                    `xml:"resourceOwnerId"`
+	ResourceType  string                    `xml:"resourceType"`
+	ResourceID    string                    `xml:"resourceId"`
+	State         string                    `xml:"state"`
+	Association   *attachmentAssociationXML `xml:"association"`
+	Tags          []tagXML                  `xml:"tagSet>item"`
+}
+
+type describeTransitGatewayAttachmentsResponse struct {
+	XMLName     xml.Name        `xml:"http://ec2.amazonaws.com/doc/2016-11-15/ DescribeTransitGatewayAttachmentsResponse"`
+	RequestID   string          `xml:"requestId"`
+	Attachments []attachmentXML `xml:"transitGatewayAttachments>item"`
+}
+
+type prefixListXML struct {
+	ID            string `xml:"prefixListId"`
+	Name          string `xml:"prefixListName"`
+	AddressFamily string `xml:"addressFamily"`
+	MaxEntries    int    `xml:"maxEntries"`
+	OwnerID       string `xml:"ownerId"`
+	State         string `xml:"state"`
+}
+
+type describeManagedPrefixListsResponse struct {
+	XMLName     xml.Name        `xml:"http://ec2.amazonaws.com/doc/2016-11-15/ DescribeManagedPrefixListsResponse"`
+	RequestID   string          `xml:"requestId"`
+	PrefixLists []prefixListXML `xml:"prefixListSet>item"`
+}
+
+type vpnTelemetryXML struct {
+	OutsideIP      string `xml:"outsideIpAddress"`
+	Status         string `xml:"status"`
+	StatusMessage  string `xml:"statusMessage"`
+	AcceptedRoutes int    `xml:"acceptedRouteCount"`
+}
+
+type vpnConnectionXML struct {
+	ID        string            `xml:"vpnConnectionId"`
+	State     string            `xml:"state"`
+	Type      string            `xml:"type"`
+	TgwID     string            `xml:"transitGatewayId"`
+	Telemetry []vpnTelemetryXML `xml:"vgwTelemetry>item"`
+}
+
+type describeVpnConnectionsResponse struct {
+	XMLName        xml.Name           `xml:"http://ec2.amazonaws.com/doc/2016-11-15/ DescribeVpnConnectionsResponse"`
+	RequestID      string             `xml:"requestId"`
+	VpnConnections []vpnConnectionXML `xml:"vpnConnectionSet>item"`
+}
+
+type connectOptionsXML struct {
+	Protocol string `xml:"protocol"`
+}
+
+type connectXML struct {
+	ID          string            `xml:"transitGatewayAttachmentId"`
+	TransportID string            `xml:"transportTransitGatewayAttachmentId"`
+	TgwID       string            `xml:"transitGatewayId"`
+	State       string            `xml:"state"`
+	Options     connectOptionsXML `xml:"options"`
+	Tags        []tagXML          `xml:"tagSet>item"`
+}
+
+type describeTransitGatewayConnectsResponse struct {
+	XMLName   xml.Name     `xml:"http://ec2.amazonaws.com/doc/2016-11-15/ DescribeTransitGatewayConnectsResponse"`
+	RequestID string       `xml:"requestId"`
+	Connects  []connectXML `xml:"transitGatewayConnectSet>item"`
+}
+
+type bgpConfigurationXML struct {
+	PeerAddress string `xml:"peerAddress"`
+	PeerAsn     int64  `xml:"peerAsn"`
+	TgwAddress  string `xml:"transitGatewayAddress"`
+	TgwAsn      int64  `xml:"transitGatewayAsn"`
+	Status      string `xml:"bgpStatus"`
+}
+
+type connectPeerConfigurationXML struct {
+	PeerAddress       string                `xml:"peerAddress"`
+	TgwAddress        string                `xml:"transitGatewayAddress"`
+	Protocol          string                `xml:"protocol"`
+	InsideCidrBlocks  []string              `xml:"insideCidrBlocks>item"`
+	BgpConfigurations []bgpConfigurationXML `xml:"bgpConfigurations>item"`
+}
+
+type connectPeerXML struct {
+	ID            string                      `xml:"transitGatewayConnectPeerId"`
+	AttachmentID  string                      `xml:"transitGatewayAttachmentId"`
+	State         string                      `xml:"state"`
+	Configuration connectPeerConfigurationXML `xml:"connectPeerConfiguration"`
+}
+
+type describeTransitGatewayConnectPeersResponse struct {
+	XMLName   xml.Name         `xml:"http://ec2.amazonaws.com/doc/2016-11-15/ DescribeTransitGatewayConnectPeersResponse"`
+	RequestID string           `xml:"requestId"`
+	Peers     []connectPeerXML `xml:"transitGatewayConnectPeerSet>item"`
+}
+
+type policyTableXML struct {
+	ID    string   `xml:"transitGatewayPolicyTableId"`
+	TgwID string   `xml:"transitGatewayId"`
+	State string   `xml:"state"`
+	Tags  []tagXML `xml:"tagSet>item"`
+}
+
+type describeTransitGatewayPolicyTablesResponse struct {
+	XMLName      xml.Name         `xml:"http://ec2.amazonaws.com/doc/2016-11-15/ DescribeTransitGatewayPolicyTablesResponse"`
+	RequestID    string           `xml:"requestId"`
+	PolicyTables []policyTableXML `xml:"transitGatewayPolicyTables>item"`
+}
+
+type policyTableAssociationXML struct {
+	PolicyTableID string `xml:"transitGatewayPolicyTableId"`
+	ID            string `xml:"transitGatewayAttachmentId"`
+	ResourceID    string `xml:"resourceId"`
+	ResourceType  string `xml:"resourceType"`
+	State         string `xml:"state"`
+}
+
+type getTransitGatewayPolicyTableAssociationsResponse struct {
+	XMLName      xml.Name                    `xml:"http://ec2.amazonaws.com/doc/2016-11-15/ GetTransitGatewayPolicyTableAssociationsResponse"`
+	RequestID    string                      `xml:"requestId"`
+	Associations []policyTableAssociationXML `xml:"associations>item"`
+}
+
+type errorXML struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+type errorResponse struct {
+	XMLName   xml.Name   `xml:"Response"`
+	Errors    []errorXML `xml:"Errors>Error"`
+	RequestID string     `xml:"RequestID"`
+}
+
+// The types below are the JSON documents of the Direct Connect responses.
+
+type dxPrefixJSON struct {
+	Cidr string `json:"cidr"`
+}
+
+type dxAssociatedGatewayJSON struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type dxAssociationJSON struct {
+	DxGatewayID       string                  `json:"directConnectGatewayId"`
+	State             string                  `json:"associationState"`
+	AssociatedGateway dxAssociatedGatewayJSON `json:"associatedGateway"`
+	AllowedPrefixes   []dxPrefixJSON          `json:"allowedPrefixesToDirectConnectGateway"`
+}
+
+type describeDirectConnectGatewayAssociationsResponse struct {
+	Associations []dxAssociationJSON `json:"directConnectGatewayAssociations"`
+}
@@ -0,0 +1,269 @@
+/*
+Package metrics keeps the metrics of the routing and of the AWS API calls and writes them
+in the Prometheus text exposition format.
+
+The routing metrics are gauges replaced on every sync, the AWS API metrics are counters and a histogram
+that grow with every call reported by ports.NewInstrumentedRouter.
+*/
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+)
+
+// ContentType is the content type of the Prometheus text exposition format written by Write.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// durationBuckets are the upper bounds in seconds of the buckets of the AWS API latency histogram.
+var durationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// routeTypes and routeStates are always exported for each route table, even with a count of 0,
+// so an alert can see a route table losing all its propagated routes.
+var (
+	routeTypes  = []types.TransitGatewayRouteType{types.TransitGatewayRouteTypeStatic, types.TransitGatewayRouteTypePropagated}
+	routeStates = []types.TransitGatewayRouteState{types.TransitGatewayRouteStateActive, types.TransitGatewayRouteStateBlackhole}
+)
+
+// label is a name and value of a label of a sample.
+type label struct {
+	name, value string
+}
+
+// sample is a value of a metric with its labels.
+type sample struct {
+	labels []label
+	value  float64
+}
+
+// apiStats are the calls to an AWS API operation.
+type apiStats struct {
+	calls   int
+	errors  int
+	sum     float64
+	buckets []int
+}
+
+// Collector keeps the metrics, it is safe to use from multiple goroutines.
+type Collector struct {
+	mu sync.Mutex
+
+	routes       []sample
+	blackholes   []sample
+	associations []sample
+	attachments  []sample
+	lastSync     time.Time
+	syncErrors   int
+
+	api map[string]*apiStats
+}
+
+// NewCollector returns an empty Collector.
+func NewCollector() *Collector {
+	return &Collector{api: make(map[string]*apiStats)}
+}
+
+// ObserveAPICall records a call to the AWS API, it is a ports.ObserveFunc.
+func (c *Collector) ObserveAPICall(operation string, duration time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.api[operation]
+	if !ok {
+		s = &apiStats{buckets: make([]int, len(durationBuckets))}
+		c.api[operation] = s
+	}
+	s.calls++
+	if err != nil {
+		s.errors++
+	}
+	seconds := duration.Seconds()
+	s.sum += seconds
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			s.buckets[i]++
+		}
+	}
+}
+
+// ObserveSync records a sync of the routing done at t.
+// A successful sync replaces the routing metrics with the ones of tgws, a failed one only counts the error
+// and the metrics of the last successful sync are kept.
+// A sync with a *awsrouter.PartialRoutingError also counts as an error, the metrics of its incomplete route tables
+// and of the attachments of their Transit Gateways are kept from the last sync and the rest are replaced.
+func (c *Collector) ObserveSync(tgws []*awsrouter.Tgw, t time.Time, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var partial *awsrouter.PartialRoutingError
+	if err != nil {
+		c.syncErrors++
+		if !errors.As(err, &partial) {
+			return
+		}
+	}
+	oldRoutes, oldBlackholes, oldAssociations, oldAttachments := c.routes, c.blackholes, c.associations, c.attachments
+	c.routes, c.blackholes, c.associations, c.attachments = nil, nil, nil, nil
+	for _, tgw := range tgws {
+		incomplete := false
+		for _, rt := range tgw.RouteTables {
+			if partial.Incomplete(rt.ID) {
+				incomplete = true
+				c.routes = append(c.routes, samplesWith(oldRoutes, "route_table_id", rt.ID)...)
+				c.blackholes = append(c.blackholes, samplesWith(oldBlackholes, "route_table_id", rt.ID)...)
+				c.associations = append(c.associations, samplesWith(oldAssociations, "route_table_id", rt.ID)...)
+				continue
+			}
+			rtLabels := []label{{"tgw_id", tgw.ID}, {"tgw_name", tgw.Name}, {"route_table_id", rt.ID}, {"route_table_name", rt.Name}}
+			counts := make(map[[2]string]int)
+			for _, routeType := range routeTypes {
+				for _, state := range routeStates {
+					counts[[2]string{string(routeType), string(state)}] = 0
+				}
+			}
+			blackholes := 0
+			for _, route := range rt.Routes {
+				counts[[2]string{string(route.Type), string(route.State)}]++
+				if route.State == types.TransitGatewayRouteStateBlackhole {
+					blackholes++
+				}
+			}
+			for key, count := range counts {
+				c.routes = append(c.routes, sample{labels: withLabels(rtLabels, label{"type", key[0]}, label{"state", key[1]}), value: float64(count)})
+			}
+			c.blackholes = append(c.blackholes, sample{labels: rtLabels, value: float64(blackholes)})
+			c.associations = append(c.associations, sample{labels: rtLabels, value: float64(len(rt.Attachments))})
+		}
+		if incomplete {
+			// The attachments are counted from the route tables, so they are incomplete too.
+			c.attachments = append(c.attachments, samplesWith(oldAttachments, "tgw_id", tgw.ID)...)
+			continue
+		}
+		attachments := make(map[string]int)
+		for _, att := range tgw.Attachments() {
+			attachments[att.Type]++
+		}
+		for attType, count := range attachments {
+			c.attachments = append(c.attachments, sample{labels: []label{{"tgw_id", tgw.ID}, {"tgw_name", tgw.Name}, {"type", attType}}, value: float64(count)})
+		}
+	}
+	if partial == nil {
+		c.lastSync = t
+	}
+}
+
+// samplesWith returns the samples that have the label name with the value value.
+func samplesWith(samples []sample, name, value string) []sample {
+	var result []sample
+	for _, s := range samples {
+		for _, l := range s.labels {
+			if l.name == name && l.value == value {
+				result = append(result, s)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// withLabels returns a copy of labels with extra appended.
+func withLabels(labels []label, extra ...label) []label {
+	result := make([]label, 0, len(labels)+len(extra))
+	result = append(result, labels...)
+	return append(result, extra...)
+}
+
+// Write writes all the metrics in w in the Prometheus text exposition format.
+// The samples of each metric are sorted by their labels, so the output is the same for the same metrics.
+func (c *Collector) Write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bw := bufio.NewWriter(w)
+
+	writeGauge(bw, "awsrouter_route_table_routes", "Number of routes in the route table by type and state.", c.routes)
+	writeGauge(bw, "awsrouter_route_table_blackhole_routes", "Number of blackhole routes in the route table.", c.blackholes)
+	writeGauge(bw, "awsrouter_route_table_associated_attachments", "Number of attachments associated to the route table.", c.associations)
+	writeGauge(bw, "awsrouter_tgw_attachments", "Number of attachments of the Transit Gateway by type.", c.attachments)
+
+	var lastSync []sample
+	if !c.lastSync.IsZero() {
+		lastSync = []sample{{value: float64(c.lastSync.UnixNano()) / 1e9}}
+	}
+	writeGauge(bw, "awsrouter_last_sync_timestamp_seconds", "Unix time of the last successful sync of the routing.", lastSync)
+	writeHeader(bw, "awsrouter_sync_errors_total", "Number of syncs of the routing that failed or left route tables incomplete.", "counter")
+	writeSample(bw, "awsrouter_sync_errors_total", nil, float64(c.syncErrors))
+
+	operations := make([]string, 0, len(c.api))
+	for operation := range c.api {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+	writeHeader(bw, "awsrouter_aws_api_calls_total", "Number of calls to the AWS API.", "counter")
+	for _, operation := range operations {
+		writeSample(bw, "awsrouter_aws_api_calls_total", []label{{"operation", operation}}, float64(c.api[operation].calls))
+	}
+	writeHeader(bw, "awsrouter_aws_api_errors_total", "Number of calls to the AWS API that returned an error.", "counter")
+	for _, operation := range operations {
+		writeSample(bw, "awsrouter_aws_api_errors_total", []label{{"operation", operation}}, float64(c.api[operation].errors))
+	}
+	writeHeader(bw, "awsrouter_aws_api_call_duration_seconds", "Latency of the calls to the AWS API.", "histogram")
+	for _, operation := range operations {
+		s := c.api[operation]
+		for i, bound := range durationBuckets {
+			writeSample(bw, "awsrouter_aws_api_call_duration_seconds_bucket", []label{{"operation", operation}, {"le", formatFloat(bound)}}, float64(s.buckets[i]))
+		}
+		writeSample(bw, "awsrouter_aws_api_call_duration_seconds_bucket", []label{{"operation", operation}, {"le", "+Inf"}}, float64(s.calls))
+		writeSample(bw, "awsrouter_aws_api_call_duration_seconds_sum", []label{{"operation", operation}}, s.sum)
+		writeSample(bw, "awsrouter_aws_api_call_duration_seconds_count", []label{{"operation", operation}}, float64(s.calls))
+	}
+	return bw.Flush()
+}
+
+// writeGauge writes a gauge with its samples sorted by labels.
+func writeGauge(w io.Writer, name, help string, samples []sample) {
+	writeHeader(w, name, help, "gauge")
+	sorted := make([]sample, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return formatLabels(sorted[i].labels) < formatLabels(sorted[j].labels) })
+	for _, s := range sorted {
+		writeSample(w, name, s.labels, s.value)
+	}
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric.
+func writeHeader(w io.Writer, name, help, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// writeSample writes a line with the value of a metric.
+func writeSample(w io.Writer, name string, labels []label, value float64) {
+	fmt.Fprintf(w, "%s%s %s\n", name, formatLabels(labels), formatFloat(value))
+}
+
+// formatLabels returns the labels in the format {name="value",...}, or an empty string without labels.
+func formatLabels(labels []label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(labels))
+	for _, l := range labels {
+		parts = append(parts, fmt.Sprintf("%s=\"%s\"", l.name, labelEscaper.Replace(l.value)))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// labelEscaper escapes the characters not allowed in the value of a label.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatFloat formats a value with the shortest representation.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
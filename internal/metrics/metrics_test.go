@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/go-cmp/cmp"
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+)
+
+var tgws = []*awsrouter.Tgw{
+	{
+		ID:   "tgw-0a",
+		Name: "core",
+		RouteTables: []*awsrouter.TgwRouteTable{
+			{
+				ID:          "tgw-rtb-0a",
+				Name:        `spokes "a"`,
+				Attachments: []*awsrouter.TgwAttachment{{ID: "tgw-attach-0a", ResourceID: "vpc-0a", Type: "vpc"}},
+				Routes: []types.TransitGatewayRoute{
+					{
+						DestinationCidrBlock: aws.String("10.0.0.0/16"),
+						State:                "active",
+						Type:                 "propagated",
+						TransitGatewayAttachments: []types.TransitGatewayRouteAttachment{
+							{TransitGatewayAttachmentId: aws.String("tgw-attach-0b"), ResourceId: aws.String("vpn-0b"), ResourceType: "vpn"},
+						},
+					},
+					{DestinationCidrBlock: aws.String("10.1.0.0/16"), State: "blackhole", Type: "static"},
+				},
+			},
+		},
+	},
+}
+
+const want = `# HELP awsrouter_route_table_routes Number of routes in the route table by type and state.
+# TYPE awsrouter_route_table_routes gauge
+awsrouter_route_table_routes{tgw_id="tgw-0a",tgw_name="core",route_table_id="tgw-rtb-0a",route_table_name="spokes \"a\"",type="propagated",state="active"} 1
+awsrouter_route_table_routes{tgw_id="tgw-0a",tgw_name="core",route_table_id="tgw-rtb-0a",route_table_name="spokes \"a\"",type="propagated",state="blackhole"} 0
+awsrouter_route_table_routes{tgw_id="tgw-0a",tgw_name="core",route_table_id="tgw-rtb-0a",route_table_name="spokes \"a\"",type="static",state="active"} 0
+awsrouter_route_table_routes{tgw_id="tgw-0a",tgw_name="core",route_table_id="tgw-rtb-0a",route_table_name="spokes \"a\"",type="static",state="blackhole"} 1
+# HELP awsrouter_route_table_blackhole_routes Number of blackhole routes in the route table.
+# TYPE awsrouter_route_table_blackhole_routes gauge
+awsrouter_route_table_blackhole_routes{tgw_id="tgw-0a",tgw_name="core",route_table_id="tgw-rtb-0a",route_table_name="spokes \"a\""} 1
+# HELP awsrouter_route_table_associated_attachments Number of attachments associated to the route table.
+# TYPE awsrouter_route_table_associated_attachments gauge
+awsrouter_route_table_associated_attachments{tgw_id="tgw-0a",tgw_name="core",route_table_id="tgw-rtb-0a",route_table_name="spokes \"a\""} 1
+# HELP awsrouter_tgw_attachments Number of attachments of the Transit Gateway by type.
+# TYPE awsrouter_tgw_attachments gauge
+awsrouter_tgw_attachments{tgw_id="tgw-0a",tgw_name="core",type="vpc"} 1
+awsrouter_tgw_attachments{tgw_id="tgw-0a",tgw_name="core",type="vpn"} 1
+# HELP awsrouter_last_sync_timestamp_seconds Unix time of the last successful sync of the routing.
+# TYPE awsrouter_last_sync_timestamp_seconds gauge
+awsrouter_last_sync_timestamp_seconds 1.6e+09
+# HELP awsrouter_sync_errors_total Number of syncs of the routing that failed or left route tables incomplete.
+# TYPE awsrouter_sync_errors_total counter
+awsrouter_sync_errors_total 1
+# HELP awsrouter_aws_api_calls_total Number of calls to the AWS API.
+# TYPE awsrouter_aws_api_calls_total counter
+awsrouter_aws_api_calls_total{operation="DescribeTransitGateways"} 2
+awsrouter_aws_api_calls_total{operation="SearchTransitGatewayRoutes"} 1
+# HELP awsrouter_aws_api_errors_total Number of calls to the AWS API that returned an error.
+# TYPE awsrouter_aws_api_errors_total counter
+awsrouter_aws_api_errors_total{operation="DescribeTransitGateways"} 1
+awsrouter_aws_api_errors_total{operation="SearchTransitGatewayRoutes"} 0
+# HELP awsrouter_aws_api_call_duration_seconds Latency of the calls to the AWS API.
+# TYPE awsrouter_aws_api_call_duration_seconds histogram
+awsrouter_aws_api_call_duration_seconds_bucket{operation="DescribeTransitGateways",le="0.05"} 0
+awsrouter_aws_api_call_duration_seconds_bucket{operation="DescribeTransitGateways",le="0.1"} 0
+awsrouter_aws_api_call_duration_seconds_bucket{operation="DescribeTransitGateways",le="0.25"} 1
+awsrouter_aws_api_call_duration_seconds_bucket{operation="DescribeTransitGateways",le="0.5"} 1
+awsrouter_aws_api_call_duration_seconds_bucket{operation="DescribeTransitGateways",le="1"} 1
+awsrouter_aws_api_call_duration_seconds_bucket{operation="DescribeTransitGateways",le="2.5"} 1
+awsrouter_aws_api_call_duration_seconds_bucket{operation="DescribeTransitGateways",le="5"} 1
+awsrouter_aws_api_call_duration_seconds_bucket{operation="DescribeTransitGateways",le="10"} 1
+awsrouter_aws_api_call_duration_seconds_bucket{operation="DescribeTransitGateways",le="+Inf"} 2
+awsrouter_aws_api_call_duration_seconds_sum{operation="DescribeTransitGateways"} 30.25
+awsrouter_aws_api_call_duration_seconds_count{operation="DescribeTransitGateways"} 2
+awsrouter_aws_api_call_duration_seconds_bucket{operation="SearchTransitGatewayRoutes",le="0.05"} 1
+awsrouter_aws_api_call_duration_seconds_bucket{operation="SearchTransitGatewayRoutes",le="0.1"} 1
+awsrouter_aws_api_call_duration_seconds_bucket{operation="SearchTransitGatewayRoutes",le="0.25"} 1
+awsrouter_aws_api_call_duration_seconds_bucket{operation="SearchTransitGatewayRoutes",le="0.5"} 1
+awsrouter_aws_api_call_duration_seconds_bucket{operation="SearchTransitGatewayRoutes",le="1"} 1
+awsrouter_aws_api_call_duration_seconds_bucket{operation="SearchTransitGatewayRoutes",le="2.5"} 1
+awsrouter_aws_api_call_duration_seconds_bucket{operation="SearchTransitGatewayRoutes",le="5"} 1
+awsrouter_aws_api_call_duration_seconds_bucket{operation="SearchTransitGatewayRoutes",le="10"} 1
+awsrouter_aws_api_call_duration_seconds_bucket{operation="SearchTransitGatewayRoutes",le="+Inf"} 1
+awsrouter_aws_api_call_duration_seconds_sum{operation="SearchTransitGatewayRoutes"} 0.05
+awsrouter_aws_api_call_duration_seconds_count{operation="SearchTransitGatewayRoutes"} 1
+`
+
+func TestCollectorWrite(t *testing.T) {
+	c := NewCollector()
+	c.ObserveAPICall("DescribeTransitGateways", 250*time.Millisecond, nil)
+	c.ObserveAPICall("DescribeTransitGateways", 30*time.Second, errors.New("throttled"))
+	c.ObserveAPICall("SearchTransitGatewayRoutes", 50*time.Millisecond, nil)
+	c.ObserveSync(tgws, time.Unix(1600000000, 0), nil)
+	// A failed sync keeps the routing of the last successful one.
+	c.ObserveSync(nil, time.Unix(1700000000, 0), errors.New("throttled"))
+
+	var b bytes.Buffer
+	if err := c.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("Write() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectorWriteEmpty(t *testing.T) {
+	var b bytes.Buffer
+	if err := NewCollector().Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b.Bytes(), []byte("awsrouter_sync_errors_total 0\n")) || bytes.Contains(b.Bytes(), []byte("\nawsrouter_last_sync_timestamp_seconds ")) {
+		t.Errorf("Write() = %s, want no sync yet", b.String())
+	}
+}
+
+func TestCollectorObserveSyncPartial(t *testing.T) {
+	c := NewCollector()
+	c.ObserveSync(tgws, time.Unix(1600000000, 0), nil)
+	// The second sync fails to get the routes of tgw-rtb-0a, so it has none.
+	incomplete := []*awsrouter.Tgw{
+		{
+			ID:   "tgw-0a",
+			Name: "core",
+			RouteTables: []*awsrouter.TgwRouteTable{
+				{ID: "tgw-rtb-0a", Name: `spokes "a"`},
+				{ID: "tgw-rtb-0b", Name: "shared", Routes: []types.TransitGatewayRoute{{DestinationCidrBlock: aws.String("10.2.0.0/16"), State: "blackhole", Type: "static"}}},
+			},
+		},
+	}
+	partial := &awsrouter.PartialRoutingError{RouteTables: []*awsrouter.RouteTableError{{TgwID: "tgw-0a", RouteTableID: "tgw-rtb-0a", Err: errors.New("throttled")}}}
+	c.ObserveSync(incomplete, time.Unix(1700000000, 0), partial)
+
+	var b bytes.Buffer
+	if err := c.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`awsrouter_route_table_blackhole_routes{tgw_id="tgw-0a",tgw_name="core",route_table_id="tgw-rtb-0a",route_table_name="spokes \"a\""} 1`,
+		`awsrouter_route_table_blackhole_routes{tgw_id="tgw-0a",tgw_name="core",route_table_id="tgw-rtb-0b",route_table_name="shared"} 1`,
+		`awsrouter_route_table_associated_attachments{tgw_id="tgw-0a",tgw_name="core",route_table_id="tgw-rtb-0a",route_table_name="spokes \"a\""} 1`,
+		`awsrouter_tgw_attachments{tgw_id="tgw-0a",tgw_name="core",type="vpn"} 1`,
+		"awsrouter_last_sync_timestamp_seconds 1.6e+09",
+		"awsrouter_sync_errors_total 1",
+	} {
+		if !bytes.Contains(b.Bytes(), []byte(line+"\n")) {
+			t.Errorf("Write() = %s, want %s", b.String(), line)
+		}
+	}
+}
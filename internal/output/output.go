@@ -0,0 +1,68 @@
+/*
+Package output defines the machine-readable schemas printed by the commands and the writers for each format.
+The schemas are part of the CLI contract, fields can be added but existing fields are not renamed or removed.
+*/
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the format used to print the result of a command.
+type Format string
+
+const (
+	// FormatTable prints human-readable tables and messages, this is the default.
+	FormatTable Format = "table"
+	// FormatJSON prints the result as indented JSON.
+	FormatJSON Format = "json"
+	// FormatYAML prints the result as YAML.
+	FormatYAML Format = "yaml"
+)
+
+var (
+	ErrUnknownFormat = errors.New("output: unknown output format")
+	ErrTableFormat   = errors.New("output: table format is printed by each command")
+)
+
+// Formats returns the list of supported formats.
+func Formats() []Format {
+	return []Format{FormatTable, FormatJSON, FormatYAML}
+}
+
+// ParseFormat returns the Format for s, the match is case insensitive.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats() {
+		if strings.EqualFold(s, string(f)) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q, valid values are %v", ErrUnknownFormat, s, Formats())
+}
+
+// Write encodes v into w using format.
+// The table format is not handled by Write because every command has its own table layout.
+func Write(w io.Writer, format Format, v interface{}) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	case FormatTable:
+		return ErrTableFormat
+	}
+	return fmt.Errorf("%w %q", ErrUnknownFormat, format)
+}
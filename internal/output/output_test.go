@@ -0,0 +1,195 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/go-cmp/cmp"
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+)
+
+var tgw = &awsrouter.Tgw{
+	ID:   "tgw-0d7f9b0a",
+	Name: "testA",
+	Data: types.TransitGateway{
+		TransitGatewayId: aws.String("tgw-0d7f9b0a"),
+		OwnerId:          aws.String("123456789012"),
+		State:            "available",
+	},
+	RouteTables: []*awsrouter.TgwRouteTable{
+		{
+			ID:   "tgw-rtb-0d7f9b0a",
+			Name: "rtb1",
+			Data: types.TransitGatewayRouteTable{
+				TransitGatewayId:             aws.String("tgw-0d7f9b0a"),
+				DefaultAssociationRouteTable: aws.Bool(true),
+			},
+			Attachments: []*awsrouter.TgwAttachment{
+				{ID: "tgw-attach-0a", Name: "vpc-a", ResourceID: "vpc-0a", Type: "vpc"},
+			},
+			Routes: []types.TransitGatewayRoute{
+				{
+					DestinationCidrBlock: aws.String("10.0.0.0/16"),
+					State:                "active",
+					Type:                 "propagated",
+					TransitGatewayAttachments: []types.TransitGatewayRouteAttachment{
+						{
+							TransitGatewayAttachmentId: aws.String("tgw-attach-0a"),
+							ResourceId:                 aws.String("vpc-0a"),
+							ResourceType:               "vpc",
+						},
+					},
+				},
+				{
+					PrefixListId: aws.String("pl-0a"),
+					State:        "blackhole",
+					Type:         "static",
+				},
+			},
+		},
+	},
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Format
+		wantErr bool
+	}{
+		{name: "Table", s: "table", want: FormatTable},
+		{name: "JSON Upper Case", s: "JSON", want: FormatJSON},
+		{name: "YAML", s: "yaml", want: FormatYAML},
+		{name: "Unknown", s: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormat(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFormat() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTgw(t *testing.T) {
+	want := Tgw{
+		ID:      "tgw-0d7f9b0a",
+		Name:    "testA",
+		State:   "available",
+		OwnerID: "123456789012",
+		RouteTables: []RouteTable{
+			{
+				ID:                 "tgw-rtb-0d7f9b0a",
+				Name:               "rtb1",
+				TgwID:              "tgw-0d7f9b0a",
+				DefaultAssociation: true,
+				Attachments: []Attachment{
+					{ID: "tgw-attach-0a", Name: "vpc-a", ResourceID: "vpc-0a", Type: "vpc"},
+				},
+				Routes: []Route{
+					{
+						Destination: "10.0.0.0/16",
+						State:       "active",
+						Type:        "propagated",
+						Attachments: []Attachment{
+							{ID: "tgw-attach-0a", Name: "vpc-a", ResourceID: "vpc-0a", Type: "vpc"},
+						},
+					},
+					{
+						PrefixListID: "pl-0a",
+						State:        "blackhole",
+						Type:         "static",
+						Attachments:  []Attachment{},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, NewTgw(tgw)); diff != "" {
+		t.Errorf("NewTgw() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewPath(t *testing.T) {
+	vpnTgw := &awsrouter.Tgw{
+		ID:   "tgw-0a",
+		Name: "core",
+		VpnConnections: []*awsrouter.VpnConnection{
+			{ID: "vpn-0b", State: "available", Tunnels: []awsrouter.VpnTunnel{{OutsideIP: "203.0.113.1", Status: "DOWN", StatusMessage: "IPSEC IS DOWN"}}},
+		},
+	}
+	attPath := awsrouter.NewAttPath()
+	attPath.Tgw = vpnTgw
+	attPath.Path = []*awsrouter.TgwAttachment{
+		{ID: "tgw-attach-0a", Name: "vpc-a", ResourceID: "vpc-0a", Type: "vpc"},
+		{ID: "tgw-attach-0b", ResourceID: "vpn-0b", Type: "vpn"},
+	}
+	want := Path{
+		TgwID:       "tgw-0a",
+		TgwName:     "core",
+		Source:      "10.0.0.10",
+		Destination: "192.168.0.10",
+		Hops: []Attachment{
+			{ID: "tgw-attach-0a", Name: "vpc-a", ResourceID: "vpc-0a", Type: "vpc"},
+			{
+				ID:         "tgw-attach-0b",
+				ResourceID: "vpn-0b",
+				Type:       "vpn",
+				Vpn: &Vpn{
+					ID:      "vpn-0b",
+					State:   "available",
+					Down:    true,
+					Tunnels: []VpnTunnel{{OutsideIP: "203.0.113.1", Status: "DOWN", StatusMessage: "IPSEC IS DOWN"}},
+				},
+			},
+		},
+		Error: awsrouter.ErrTgwVpnDown.Error(),
+	}
+	if diff := cmp.Diff(want, NewPath(attPath, "10.0.0.10", "192.168.0.10", awsrouter.ErrTgwVpnDown)); diff != "" {
+		t.Errorf("NewPath() mismatch (-want +got):\n%s", diff)
+	}
+
+	// A walk that leaves to Cloud WAN is not an error.
+	cloudWanErr := fmt.Errorf("%w core-network-0b via policy table cloudwan", awsrouter.ErrTgwCloudWan)
+	got := NewPath(attPath, "10.0.0.10", "192.168.0.10", cloudWanErr)
+	if got.Error != "" || got.Exit != "leaves to Cloud WAN core network core-network-0b via policy table cloudwan" {
+		t.Errorf("NewPath() error = %q, exit = %q, want the Cloud WAN exit", got.Error, got.Exit)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	v := Version{Version: "0.1.0"}
+	tests := []struct {
+		name    string
+		format  Format
+		want    string
+		wantErr error
+	}{
+		{name: "JSON", format: FormatJSON, want: "{\n  \"version\": \"0.1.0\"\n}\n"},
+		{name: "YAML", format: FormatYAML, want: "version: 0.1.0\n"},
+		{name: "Table", format: FormatTable, wantErr: ErrTableFormat},
+		{name: "Unknown", format: Format("xml"), wantErr: ErrUnknownFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Write(&buf, tt.format, v)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Write() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Write() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,666 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+)
+
+// Tgw is the schema of a Transit Gateway.
+// Vpns and DxGateways are the VPN connections and Direct Connect gateways of the attachments,
+// Connects are the Connect attachments with their peers and PolicyTables the policy tables used by the peerings
+// with Cloud WAN, all omitted when there are none.
+type Tgw struct {
+	ID           string        `json:"id" yaml:"id"`
+	Name         string        `json:"name" yaml:"name"`
+	State        string        `json:"state" yaml:"state"`
+	OwnerID      string        `json:"owner_id" yaml:"owner_id"`
+	RouteTables  []RouteTable  `json:"route_tables" yaml:"route_tables"`
+	Vpns         []Vpn         `json:"vpns,omitempty" yaml:"vpns,omitempty"`
+	DxGateways   []DxGateway   `json:"dx_gateways,omitempty" yaml:"dx_gateways,omitempty"`
+	Connects     []Connect     `json:"connects,omitempty" yaml:"connects,omitempty"`
+	PolicyTables []PolicyTable `json:"policy_tables,omitempty" yaml:"policy_tables,omitempty"`
+}
+
+// RouteTable is the schema of a Transit Gateway Route Table.
+// Attachments are the attachments associated to the route table.
+// Propagations are the attachments that propagate routes to the route table, null when they are unknown.
+type RouteTable struct {
+	ID                 string       `json:"id" yaml:"id"`
+	Name               string       `json:"name" yaml:"name"`
+	TgwID              string       `json:"tgw_id" yaml:"tgw_id"`
+	DefaultAssociation bool         `json:"default_association" yaml:"default_association"`
+	DefaultPropagation bool         `json:"default_propagation" yaml:"default_propagation"`
+	Attachments        []Attachment `json:"attachments" yaml:"attachments"`
+	Propagations       []Attachment `json:"propagations" yaml:"propagations"`
+	Routes             []Route      `json:"routes" yaml:"routes"`
+}
+
+// Route is the schema of a route in a Transit Gateway Route Table.
+// Destination is empty for routes to a prefix list, in that case PrefixListID is set.
+// Attachments are the next hops of the route, more than one is ECMP.
+type Route struct {
+	Destination  string       `json:"destination" yaml:"destination"`
+	PrefixListID string       `json:"prefix_list_id,omitempty" yaml:"prefix_list_id,omitempty"`
+	State        string       `json:"state" yaml:"state"`
+	Type         string       `json:"type" yaml:"type"`
+	Attachments  []Attachment `json:"attachments" yaml:"attachments"`
+}
+
+// Attachment is the schema of a Transit Gateway Attachment.
+// Vpn, DxGateway and Connect are only set in a path, for the attachment of a VPN, a Direct Connect gateway or a Connect.
+type Attachment struct {
+	ID         string     `json:"id" yaml:"id"`
+	Name       string     `json:"name,omitempty" yaml:"name,omitempty"`
+	ResourceID string     `json:"resource_id" yaml:"resource_id"`
+	Type       string     `json:"type" yaml:"type"`
+	Vpn        *Vpn       `json:"vpn,omitempty" yaml:"vpn,omitempty"`
+	DxGateway  *DxGateway `json:"dx_gateway,omitempty" yaml:"dx_gateway,omitempty"`
+	Connect    *Connect   `json:"connect,omitempty" yaml:"connect,omitempty"`
+}
+
+// Vpn is the schema of a Site-to-Site VPN connection, Down is true when the traffic sent to it is dropped.
+// AcceptedRoutes are the routes learned with BGP.
+type Vpn struct {
+	ID             string      `json:"id" yaml:"id"`
+	State          string      `json:"state" yaml:"state"`
+	Down           bool        `json:"down" yaml:"down"`
+	TunnelsUp      int         `json:"tunnels_up" yaml:"tunnels_up"`
+	AcceptedRoutes int         `json:"accepted_routes" yaml:"accepted_routes"`
+	Tunnels        []VpnTunnel `json:"tunnels" yaml:"tunnels"`
+}
+
+// VpnTunnel is the schema of the status of a tunnel of a VPN connection.
+type VpnTunnel struct {
+	OutsideIP      string `json:"outside_ip" yaml:"outside_ip"`
+	Status         string `json:"status" yaml:"status"`
+	StatusMessage  string `json:"status_message,omitempty" yaml:"status_message,omitempty"`
+	AcceptedRoutes int    `json:"accepted_routes" yaml:"accepted_routes"`
+}
+
+// DxGateway is the schema of a Direct Connect gateway, AllowedPrefixes are advertised from the Transit Gateway to it.
+type DxGateway struct {
+	ID              string   `json:"id" yaml:"id"`
+	State           string   `json:"state" yaml:"state"`
+	AllowedPrefixes []string `json:"allowed_prefixes" yaml:"allowed_prefixes"`
+}
+
+// Connect is the schema of a Connect attachment, TransportAttachmentID is the ID of the attachment it runs over.
+// PeersUp are the peers with a BGP session up.
+type Connect struct {
+	ID                    string        `json:"id" yaml:"id"`
+	TransportAttachmentID string        `json:"transport_attachment_id" yaml:"transport_attachment_id"`
+	State                 string        `json:"state" yaml:"state"`
+	Protocol              string        `json:"protocol" yaml:"protocol"`
+	PeersUp               int           `json:"peers_up" yaml:"peers_up"`
+	Peers                 []ConnectPeer `json:"peers" yaml:"peers"`
+}
+
+// ConnectPeer is the schema of a peer of a Connect attachment, the GRE tunnel between TgwAddress and PeerAddress.
+type ConnectPeer struct {
+	ID               string       `json:"id" yaml:"id"`
+	State            string       `json:"state" yaml:"state"`
+	PeerAddress      string       `json:"peer_address" yaml:"peer_address"`
+	TgwAddress       string       `json:"tgw_address" yaml:"tgw_address"`
+	InsideCidrBlocks []string     `json:"inside_cidr_blocks" yaml:"inside_cidr_blocks"`
+	BgpSessions      []BgpSession `json:"bgp_sessions" yaml:"bgp_sessions"`
+}
+
+// BgpSession is the schema of a BGP session of a Connect peer.
+type BgpSession struct {
+	PeerAddress string `json:"peer_address" yaml:"peer_address"`
+	PeerAsn     int64  `json:"peer_asn" yaml:"peer_asn"`
+	TgwAddress  string `json:"tgw_address" yaml:"tgw_address"`
+	TgwAsn      int64  `json:"tgw_asn" yaml:"tgw_asn"`
+	Status      string `json:"status" yaml:"status"`
+}
+
+// PolicyTable is the schema of a Transit Gateway policy table, Attachments are the attachments associated to it.
+type PolicyTable struct {
+	ID          string       `json:"id" yaml:"id"`
+	Name        string       `json:"name" yaml:"name"`
+	State       string       `json:"state" yaml:"state"`
+	Attachments []Attachment `json:"attachments" yaml:"attachments"`
+}
+
+// Path is the schema of a path walk between two IP addresses inside a Transit Gateway.
+// Hops is the list of attachments from source to destination.
+// Error is set when the walk could not be completed, Hops has the attachments found until then.
+// Exit is set instead of Error when the traffic leaves the Transit Gateway to a Cloud WAN core network, where the walk stops.
+// Drawing is the file with the drawing of the path, if one was requested.
+type Path struct {
+	TgwID       string       `json:"tgw_id" yaml:"tgw_id"`
+	TgwName     string       `json:"tgw_name" yaml:"tgw_name"`
+	Source      string       `json:"source" yaml:"source"`
+	Destination string       `json:"destination" yaml:"destination"`
+	Hops        []Attachment `json:"hops" yaml:"hops"`
+	Error       string       `json:"error,omitempty" yaml:"error,omitempty"`
+	Exit        string       `json:"exit,omitempty" yaml:"exit,omitempty"`
+	Drawing     string       `json:"drawing,omitempty" yaml:"drawing,omitempty"`
+}
+
+// Lookup is the schema of the longest prefix match of an IP address in a Transit Gateway Route Table.
+// Route is nil when the route table has no route to the IP address.
+type Lookup struct {
+	TgwID          string `json:"tgw_id" yaml:"tgw_id"`
+	RouteTableID   string `json:"route_table_id" yaml:"route_table_id"`
+	RouteTableName string `json:"route_table_name" yaml:"route_table_name"`
+	IP             string `json:"ip" yaml:"ip"`
+	Route          *Route `json:"route" yaml:"route"`
+}
+
+// Explanation is the schema of how a Transit Gateway Route Table routes a prefix.
+// Route is nil when no route covers the prefix, Exact is true when Route is to exactly the prefix.
+// Candidates are the routes that cover the prefix or are inside it, the most specific first.
+// Owners are the attachments that propagate the prefix, or part of it, to any route table of the Transit Gateway.
+type Explanation struct {
+	TgwID          string       `json:"tgw_id" yaml:"tgw_id"`
+	TgwName        string       `json:"tgw_name" yaml:"tgw_name"`
+	RouteTableID   string       `json:"route_table_id" yaml:"route_table_id"`
+	RouteTableName string       `json:"route_table_name" yaml:"route_table_name"`
+	Prefix         string       `json:"prefix" yaml:"prefix"`
+	Route          *Route       `json:"route" yaml:"route"`
+	Exact          bool         `json:"exact" yaml:"exact"`
+	Candidates     []Route      `json:"candidates" yaml:"candidates"`
+	Owners         []Attachment `json:"owners" yaml:"owners"`
+	Reasons        []string     `json:"reasons" yaml:"reasons"`
+}
+
+// Summarization is the schema of the static route summaries proposed for a Transit Gateway.
+// Routes is the number of routes in all the route tables and RoutesAfter the number once the summaries are applied.
+// Headroom and HeadroomAfter are the routes left before reaching Quota.
+type Summarization struct {
+	TgwID         string         `json:"tgw_id" yaml:"tgw_id"`
+	TgwName       string         `json:"tgw_name" yaml:"tgw_name"`
+	Routes        int            `json:"routes" yaml:"routes"`
+	RoutesAfter   int            `json:"routes_after" yaml:"routes_after"`
+	Quota         int            `json:"quota" yaml:"quota"`
+	Headroom      int            `json:"headroom" yaml:"headroom"`
+	HeadroomAfter int            `json:"headroom_after" yaml:"headroom_after"`
+	Summaries     []RouteSummary `json:"summaries" yaml:"summaries"`
+}
+
+// RouteSummary is the schema of a prefix that replaces static routes with the same next hops.
+// Routes are the destinations of the routes replaced and Saved how many routes are removed.
+type RouteSummary struct {
+	RouteTableID   string   `json:"route_table_id" yaml:"route_table_id"`
+	RouteTableName string   `json:"route_table_name" yaml:"route_table_name"`
+	Prefix         string   `json:"prefix" yaml:"prefix"`
+	Attachments    []string `json:"attachments" yaml:"attachments"`
+	Routes         []string `json:"routes" yaml:"routes"`
+	Saved          int      `json:"saved" yaml:"saved"`
+}
+
+// QuotaReport is the schema of the use of the quotas of a Transit Gateway.
+// Level is the highest level of the usages, PrefixLists are the routes added by each prefix list referenced.
+type QuotaReport struct {
+	TgwID       string            `json:"tgw_id" yaml:"tgw_id"`
+	TgwName     string            `json:"tgw_name" yaml:"tgw_name"`
+	Level       string            `json:"level" yaml:"level"`
+	Usages      []QuotaUsage      `json:"usages" yaml:"usages"`
+	PrefixLists []PrefixListUsage `json:"prefix_lists" yaml:"prefix_lists"`
+}
+
+// QuotaUsage is the schema of the use of a quota, the route table is only set for the quotas of a route table.
+type QuotaUsage struct {
+	Quota          string  `json:"quota" yaml:"quota"`
+	RouteTableID   string  `json:"route_table_id,omitempty" yaml:"route_table_id,omitempty"`
+	RouteTableName string  `json:"route_table_name,omitempty" yaml:"route_table_name,omitempty"`
+	Used           int     `json:"used" yaml:"used"`
+	Limit          int     `json:"limit" yaml:"limit"`
+	Percent        float64 `json:"percent" yaml:"percent"`
+	Level          string  `json:"level" yaml:"level"`
+}
+
+// PrefixListUsage is the schema of the routes added to the quotas by a prefix list.
+type PrefixListUsage struct {
+	ID         string `json:"id" yaml:"id"`
+	Name       string `json:"name,omitempty" yaml:"name,omitempty"`
+	MaxEntries int    `json:"max_entries" yaml:"max_entries"`
+	References int    `json:"references" yaml:"references"`
+	Entries    int    `json:"entries" yaml:"entries"`
+}
+
+// Change is the schema of a change in the routing found by the watch command.
+// Old and New are the values before and after the change, they are omitted when they do not apply.
+type Change struct {
+	Time         time.Time `json:"time" yaml:"time"`
+	Kind         string    `json:"kind" yaml:"kind"`
+	TgwID        string    `json:"tgw_id" yaml:"tgw_id"`
+	TgwName      string    `json:"tgw_name" yaml:"tgw_name"`
+	RouteTableID string    `json:"route_table_id,omitempty" yaml:"route_table_id,omitempty"`
+	AttachmentID string    `json:"attachment_id,omitempty" yaml:"attachment_id,omitempty"`
+	Prefix       string    `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	Old          string    `json:"old,omitempty" yaml:"old,omitempty"`
+	New          string    `json:"new,omitempty" yaml:"new,omitempty"`
+	Message      string    `json:"message" yaml:"message"`
+}
+
+// Drift is the schema of a difference between the routing and the declared intent found by the drift command.
+// Declared and Actual are the next hops of a static route, they are omitted when they do not apply.
+type Drift struct {
+	Kind           string `json:"kind" yaml:"kind"`
+	Resource       string `json:"resource" yaml:"resource"`
+	TgwID          string `json:"tgw_id" yaml:"tgw_id"`
+	TgwName        string `json:"tgw_name" yaml:"tgw_name"`
+	RouteTableID   string `json:"route_table_id" yaml:"route_table_id"`
+	RouteTableName string `json:"route_table_name" yaml:"route_table_name"`
+	Key            string `json:"key" yaml:"key"`
+	Declared       string `json:"declared,omitempty" yaml:"declared,omitempty"`
+	Actual         string `json:"actual,omitempty" yaml:"actual,omitempty"`
+	Message        string `json:"message" yaml:"message"`
+}
+
+// FlowChange is the schema of the verdict of a flow before and after the changes of a what-if simulation.
+// The paths are the IDs of the attachments from source to destination.
+type FlowChange struct {
+	TgwID       string   `json:"tgw_id" yaml:"tgw_id"`
+	TgwName     string   `json:"tgw_name" yaml:"tgw_name"`
+	Source      string   `json:"source" yaml:"source"`
+	Destination string   `json:"destination" yaml:"destination"`
+	Before      string   `json:"before" yaml:"before"`
+	After       string   `json:"after" yaml:"after"`
+	BeforePath  []string `json:"before_path" yaml:"before_path"`
+	AfterPath   []string `json:"after_path" yaml:"after_path"`
+	Changed     bool     `json:"changed" yaml:"changed"`
+}
+
+// Export is the schema of something written by a command, like an Excel file, a drawing or a DB entry.
+// Sheets is only set for Excel files.
+type Export struct {
+	TgwID    string        `json:"tgw_id" yaml:"tgw_id"`
+	TgwName  string        `json:"tgw_name" yaml:"tgw_name"`
+	Kind     string        `json:"kind" yaml:"kind"`
+	Location string        `json:"location" yaml:"location"`
+	Sheets   []ExportSheet `json:"sheets,omitempty" yaml:"sheets,omitempty"`
+}
+
+// ExportSheet is the schema of the sheet used for a route table in an Excel file.
+type ExportSheet struct {
+	RouteTableID   string `json:"route_table_id" yaml:"route_table_id"`
+	RouteTableName string `json:"route_table_name" yaml:"route_table_name"`
+	Sheet          string `json:"sheet" yaml:"sheet"`
+}
+
+// Version is the schema of the version command.
+type Version struct {
+	Version string `json:"version" yaml:"version"`
+}
+
+// NewTgws builds the schema for a list of Tgw.
+func NewTgws(tgws []*awsrouter.Tgw) []Tgw {
+	result := make([]Tgw, 0, len(tgws))
+	for _, tgw := range tgws {
+		result = append(result, NewTgw(tgw))
+	}
+	return result
+}
+
+// NewTgw builds the schema for a Tgw, including its route tables.
+func NewTgw(tgw *awsrouter.Tgw) Tgw {
+	t := Tgw{
+		ID:          tgw.ID,
+		Name:        tgw.Name,
+		State:       fmt.Sprint(tgw.Data.State),
+		OwnerID:     aws.StringValue(tgw.Data.OwnerId),
+		RouteTables: make([]RouteTable, 0, len(tgw.RouteTables)),
+	}
+	for _, rt := range tgw.RouteTables {
+		t.RouteTables = append(t.RouteTables, NewRouteTable(rt))
+	}
+	for _, vpn := range tgw.VpnConnections {
+		t.Vpns = append(t.Vpns, *NewVpn(vpn))
+	}
+	for _, dx := range tgw.DxGateways {
+		t.DxGateways = append(t.DxGateways, *NewDxGateway(dx))
+	}
+	for _, c := range tgw.Connects {
+		t.Connects = append(t.Connects, *NewConnect(c))
+	}
+	for _, pt := range tgw.PolicyTables {
+		t.PolicyTables = append(t.PolicyTables, NewPolicyTable(pt))
+	}
+	return t
+}
+
+// NewRouteTable builds the schema for a TgwRouteTable, including its attachments and routes.
+func NewRouteTable(rt *awsrouter.TgwRouteTable) RouteTable {
+	r := RouteTable{
+		ID:                 rt.ID,
+		Name:               rt.Name,
+		TgwID:              aws.StringValue(rt.Data.TransitGatewayId),
+		DefaultAssociation: aws.BoolValue(rt.Data.DefaultAssociationRouteTable),
+		DefaultPropagation: aws.BoolValue(rt.Data.DefaultPropagationRouteTable),
+		Attachments:        make([]Attachment, 0, len(rt.Attachments)),
+		Routes:             make([]Route, 0, len(rt.Routes)),
+	}
+	for _, att := range rt.Attachments {
+		r.Attachments = append(r.Attachments, NewAttachment(att))
+	}
+	if rt.Propagations != nil {
+		r.Propagations = make([]Attachment, 0, len(rt.Propagations))
+		for _, att := range rt.Propagations {
+			r.Propagations = append(r.Propagations, NewAttachment(att))
+		}
+	}
+	for _, route := range rt.Routes {
+		r.Routes = append(r.Routes, NewRoute(rt, route))
+	}
+	return r
+}
+
+// NewRoute builds the schema for a route of the route table rt.
+// The names of the next hop attachments are taken from the attachments of rt.
+func NewRoute(rt *awsrouter.TgwRouteTable, route types.TransitGatewayRoute) Route {
+	r := Route{
+		Destination:  aws.StringValue(route.DestinationCidrBlock),
+		PrefixListID: aws.StringValue(route.PrefixListId),
+		State:        fmt.Sprint(route.State),
+		Type:         fmt.Sprint(route.Type),
+		Attachments:  make([]Attachment, 0, len(route.TransitGatewayAttachments)),
+	}
+	for _, att := range route.TransitGatewayAttachments {
+		id := aws.StringValue(att.TransitGatewayAttachmentId)
+		r.Attachments = append(r.Attachments, Attachment{
+			ID:         id,
+			Name:       rt.GetAttachmentName(id),
+			ResourceID: aws.StringValue(att.ResourceId),
+			Type:       fmt.Sprint(att.ResourceType),
+		})
+	}
+	return r
+}
+
+// NewAttachment builds the schema for a TgwAttachment.
+func NewAttachment(att *awsrouter.TgwAttachment) Attachment {
+	return Attachment{
+		ID:         att.ID,
+		Name:       att.Name,
+		ResourceID: att.ResourceID,
+		Type:       att.Type,
+	}
+}
+
+// NewTgwAttachment builds the schema for a TgwAttachment of the tgw, with its VPN connection, Direct Connect gateway or Connect.
+func NewTgwAttachment(tgw *awsrouter.Tgw, att *awsrouter.TgwAttachment) Attachment {
+	a := NewAttachment(att)
+	if vpn := tgw.VpnConnection(att); vpn != nil {
+		a.Vpn = NewVpn(vpn)
+	}
+	if dx := tgw.DxGateway(att); dx != nil {
+		a.DxGateway = NewDxGateway(dx)
+	}
+	if c := tgw.Connect(att); c != nil {
+		a.Connect = NewConnect(c)
+	}
+	return a
+}
+
+// NewVpn builds the schema for a VpnConnection.
+func NewVpn(vpn *awsrouter.VpnConnection) *Vpn {
+	v := &Vpn{
+		ID:             vpn.ID,
+		State:          vpn.State,
+		Down:           vpn.Down(),
+		TunnelsUp:      vpn.TunnelsUp(),
+		AcceptedRoutes: vpn.AcceptedRoutes(),
+		Tunnels:        make([]VpnTunnel, 0, len(vpn.Tunnels)),
+	}
+	for _, tunnel := range vpn.Tunnels {
+		v.Tunnels = append(v.Tunnels, VpnTunnel{
+			OutsideIP:      tunnel.OutsideIP,
+			Status:         tunnel.Status,
+			StatusMessage:  tunnel.StatusMessage,
+			AcceptedRoutes: tunnel.AcceptedRoutes,
+		})
+	}
+	return v
+}
+
+// NewDxGateway builds the schema for a DxGateway.
+func NewDxGateway(dx *awsrouter.DxGateway) *DxGateway {
+	prefixes := dx.AllowedPrefixes
+	if prefixes == nil {
+		prefixes = []string{}
+	}
+	return &DxGateway{ID: dx.ID, State: dx.State, AllowedPrefixes: prefixes}
+}
+
+// NewConnect builds the schema for a TgwConnect, including its peers.
+func NewConnect(c *awsrouter.TgwConnect) *Connect {
+	connect := &Connect{
+		ID:                    c.ID,
+		TransportAttachmentID: c.TransportAttachmentID,
+		State:                 c.State,
+		Protocol:              c.Protocol,
+		PeersUp:               c.PeersUp(),
+		Peers:                 make([]ConnectPeer, 0, len(c.Peers)),
+	}
+	for _, peer := range c.Peers {
+		p := ConnectPeer{
+			ID:               peer.ID,
+			State:            peer.State,
+			PeerAddress:      peer.PeerAddress,
+			TgwAddress:       peer.TgwAddress,
+			InsideCidrBlocks: peer.InsideCidrBlocks,
+			BgpSessions:      make([]BgpSession, 0, len(peer.BgpSessions)),
+		}
+		if p.InsideCidrBlocks == nil {
+			p.InsideCidrBlocks = []string{}
+		}
+		for _, session := range peer.BgpSessions {
+			p.BgpSessions = append(p.BgpSessions, BgpSession(session))
+		}
+		connect.Peers = append(connect.Peers, p)
+	}
+	return connect
+}
+
+// NewPolicyTable builds the schema for a TgwPolicyTable, including its attachments.
+func NewPolicyTable(pt *awsrouter.TgwPolicyTable) PolicyTable {
+	p := PolicyTable{ID: pt.ID, Name: pt.Name, State: pt.State, Attachments: make([]Attachment, 0, len(pt.Attachments))}
+	for _, att := range pt.Attachments {
+		p.Attachments = append(p.Attachments, NewAttachment(att))
+	}
+	return p
+}
+
+// NewLookup builds the schema for the best route to ip in the route table rt, as returned by BestRouteToIP.
+func NewLookup(rt *awsrouter.TgwRouteTable, ip string, route types.TransitGatewayRoute) Lookup {
+	l := Lookup{
+		TgwID:          aws.StringValue(rt.Data.TransitGatewayId),
+		RouteTableID:   rt.ID,
+		RouteTableName: rt.Name,
+		IP:             ip,
+	}
+	if route.DestinationCidrBlock != nil || route.PrefixListId != nil {
+		r := NewRoute(rt, route)
+		l.Route = &r
+	}
+	return l
+}
+
+// NewExplanation builds the schema for an explanation returned by Tgw.ExplainRoute.
+func NewExplanation(e awsrouter.RouteExplanation) Explanation {
+	x := Explanation{
+		TgwID:          e.Tgw.ID,
+		TgwName:        e.Tgw.Name,
+		RouteTableID:   e.RouteTable.ID,
+		RouteTableName: e.RouteTable.Name,
+		Prefix:         e.Prefix.String(),
+		Exact:          e.Exact,
+		Candidates:     make([]Route, 0, len(e.Candidates)),
+		Owners:         make([]Attachment, 0, len(e.Owners)),
+		Reasons:        e.Reasons,
+	}
+	if e.Route != nil {
+		r := NewRoute(e.RouteTable, *e.Route)
+		x.Route = &r
+	}
+	for _, route := range e.Candidates {
+		x.Candidates = append(x.Candidates, NewRoute(e.RouteTable, route))
+	}
+	for _, att := range e.Owners {
+		x.Owners = append(x.Owners, NewAttachment(att))
+	}
+	return x
+}
+
+// NewSummarization builds the schema for the summaries of the static routes of tgw, compared with the routes quota.
+func NewSummarization(tgw *awsrouter.Tgw, summaries []awsrouter.RouteSummary, quota int) Summarization {
+	s := Summarization{
+		TgwID:     tgw.ID,
+		TgwName:   tgw.Name,
+		Routes:    tgw.RouteCount(),
+		Quota:     quota,
+		Summaries: make([]RouteSummary, 0, len(summaries)),
+	}
+	s.RoutesAfter = s.Routes
+	for _, summary := range summaries {
+		s.RoutesAfter -= summary.Saved()
+		s.Summaries = append(s.Summaries, RouteSummary{
+			RouteTableID:   summary.RouteTableID,
+			RouteTableName: summary.RouteTableName,
+			Prefix:         summary.Prefix,
+			Attachments:    summary.Attachments,
+			Routes:         summary.Routes,
+			Saved:          summary.Saved(),
+		})
+	}
+	s.Headroom = quota - s.Routes
+	s.HeadroomAfter = quota - s.RoutesAfter
+	return s
+}
+
+// NewQuotaReport builds the schema for the use of the quotas of tgw with limits.
+func NewQuotaReport(tgw *awsrouter.Tgw, limits awsrouter.Limits) QuotaReport {
+	r := QuotaReport{
+		TgwID:       tgw.ID,
+		TgwName:     tgw.Name,
+		Level:       string(awsrouter.QuotaOK),
+		PrefixLists: []PrefixListUsage{},
+	}
+	for _, u := range tgw.QuotaUsages(limits) {
+		r.Usages = append(r.Usages, QuotaUsage{
+			Quota:          string(u.Quota),
+			RouteTableID:   u.RouteTableID,
+			RouteTableName: u.RouteTableName,
+			Used:           u.Used,
+			Limit:          u.Limit,
+			Percent:        math.Round(u.Percent()*10) / 10,
+			Level:          string(u.Level),
+		})
+		if u.Level == awsrouter.QuotaCritical || (u.Level == awsrouter.QuotaWarning && r.Level == string(awsrouter.QuotaOK)) {
+			r.Level = string(u.Level)
+		}
+	}
+	for _, pl := range tgw.PrefixListUsages() {
+		r.PrefixLists = append(r.PrefixLists, PrefixListUsage{
+			ID:         pl.ID,
+			Name:       pl.Name,
+			MaxEntries: pl.MaxEntries,
+			References: pl.References,
+			Entries:    pl.Entries,
+		})
+	}
+	return r
+}
+
+// NewChange builds the schema for a change found at t.
+func NewChange(change awsrouter.Change, t time.Time) Change {
+	return Change{
+		Time:         t,
+		Kind:         string(change.Kind),
+		TgwID:        change.TgwID,
+		TgwName:      change.TgwName,
+		RouteTableID: change.RouteTableID,
+		AttachmentID: change.AttachmentID,
+		Prefix:       change.Prefix,
+		Old:          change.Old,
+		New:          change.New,
+		Message:      change.Message,
+	}
+}
+
+// NewDrift builds the schema for a drift.
+func NewDrift(d awsrouter.Drift) Drift {
+	return Drift{
+		Kind:           string(d.Kind),
+		Resource:       string(d.Resource),
+		TgwID:          d.TgwID,
+		TgwName:        d.TgwName,
+		RouteTableID:   d.RouteTableID,
+		RouteTableName: d.RouteTableName,
+		Key:            d.Key,
+		Declared:       d.Declared,
+		Actual:         d.Actual,
+		Message:        d.Message,
+	}
+}
+
+// NewFlowChange builds the schema for the result of a flow in a what-if simulation.
+func NewFlowChange(r awsrouter.FlowResult) FlowChange {
+	return FlowChange{
+		TgwID:       r.TgwID,
+		TgwName:     r.TgwName,
+		Source:      r.Source,
+		Destination: r.Destination,
+		Before:      string(r.Before),
+		After:       string(r.After),
+		BeforePath:  r.BeforePath,
+		AfterPath:   r.AfterPath,
+		Changed:     r.Changed(),
+	}
+}
+
+// NewExcelExport builds the schema for a workbook created by awsrouter.ExportTgwRoutesExcel.
+func NewExcelExport(workbook awsrouter.ExcelWorkbook) Export {
+	e := Export{
+		TgwID:    workbook.TgwID,
+		TgwName:  workbook.TgwName,
+		Kind:     "excel",
+		Location: workbook.Path,
+	}
+	for _, sheet := range workbook.Sheets {
+		e.Sheets = append(e.Sheets, ExportSheet{
+			RouteTableID:   sheet.RouteTableID,
+			RouteTableName: sheet.RouteTableName,
+			Sheet:          sheet.Sheet,
+		})
+	}
+	return e
+}
+
+// NewPath builds the schema for an AttPath between src and dst.
+// walkErr is the error returned by the walk, if any.
+func NewPath(attPath *awsrouter.AttPath, src, dst string, walkErr error) Path {
+	p := Path{
+		Source:      src,
+		Destination: dst,
+		Hops:        make([]Attachment, 0, len(attPath.Path)),
+	}
+	if attPath.Tgw != nil {
+		p.TgwID = attPath.Tgw.ID
+		p.TgwName = attPath.Tgw.Name
+	}
+	for _, att := range attPath.Path {
+		if attPath.Tgw != nil {
+			p.Hops = append(p.Hops, NewTgwAttachment(attPath.Tgw, att))
+		} else {
+			p.Hops = append(p.Hops, NewAttachment(att))
+		}
+	}
+	switch {
+	case errors.Is(walkErr, awsrouter.ErrTgwCloudWan):
+		p.Exit = strings.TrimPrefix(walkErr.Error(), "awsrouter: ")
+	case walkErr != nil:
+		p.Error = walkErr.Error()
+	}
+	return p
+}
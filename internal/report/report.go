@@ -0,0 +1,95 @@
+// Package report builds a single HTML file with the routing of all the Transit Gateways.
+// The file has no external dependencies, so it can be shared with people without AWS access or the CLI.
+package report
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/aws/draw"
+	"github.com/rogerscuall/aws-router/internal/output"
+)
+
+//go:embed report.html
+var reportTemplate string
+
+var tmpl = template.Must(template.New("report").Parse(reportTemplate))
+
+// data is the input of the template.
+type data struct {
+	Title     string
+	Generated string
+	Tgws      []tgwSection
+}
+
+// tgwSection is the part of the report of one Transit Gateway.
+type tgwSection struct {
+	output.Tgw
+	// Diagram is the topology of the Transit Gateway as an inline SVG.
+	Diagram     template.HTML
+	Attachments []attachmentRow
+	Findings    []awsrouter.Finding
+	Warnings    int
+}
+
+// attachmentRow is a row of the attachment inventory.
+type attachmentRow struct {
+	output.Attachment
+	Owner       string
+	Associated  string
+	Propagating string
+}
+
+// newTgwSection builds the section of a Tgw.
+func newTgwSection(tgw *awsrouter.Tgw) (tgwSection, error) {
+	s := tgwSection{Tgw: output.NewTgw(tgw), Findings: tgw.Lint()}
+	var svg bytes.Buffer
+	if err := draw.WriteSVG(&svg, draw.TgwDiagram(*tgw)); err != nil {
+		return s, fmt.Errorf("error drawing the topology of %s: %w", tgw.Name, err)
+	}
+	// The SVG is built by WriteSVG, that escapes all the names from AWS.
+	s.Diagram = template.HTML(svg.String())
+	for _, att := range tgw.Attachments() {
+		row := attachmentRow{Attachment: output.NewAttachment(att), Owner: att.OwnerID}
+		if rt := tgw.AssociatedRouteTable(att.ID); rt != nil {
+			row.Associated = rt.Name
+		}
+		var propagating []string
+		for _, rt := range tgw.PropagatingRouteTables(att.ID) {
+			propagating = append(propagating, rt.Name)
+		}
+		row.Propagating = strings.Join(propagating, ", ")
+		s.Attachments = append(s.Attachments, row)
+	}
+	for _, f := range s.Findings {
+		if f.Severity == awsrouter.SeverityWarning {
+			s.Warnings++
+		}
+	}
+	return s, nil
+}
+
+// Write writes the HTML report of the tgws, generated is the time shown as the creation of the report.
+func Write(w io.Writer, tgws []*awsrouter.Tgw, generated time.Time) error {
+	d := data{
+		Title:     "AWS Router report",
+		Generated: generated.UTC().Format(time.RFC3339),
+	}
+	for _, tgw := range tgws {
+		s, err := newTgwSection(tgw)
+		if err != nil {
+			return err
+		}
+		d.Tgws = append(d.Tgws, s)
+	}
+	if err := tmpl.Execute(w, d); err != nil {
+		return fmt.Errorf("error writing the report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+)
+
+var tgw = &awsrouter.Tgw{
+	ID:   "tgw-0d7f9b0a",
+	Name: "core",
+	Data: types.TransitGateway{State: "available", OwnerId: aws.String("123456789012")},
+	RouteTables: []*awsrouter.TgwRouteTable{
+		{
+			ID:   "tgw-rtb-0d7f9b0a",
+			Name: "<prod>",
+			Attachments: []*awsrouter.TgwAttachment{
+				{ID: "tgw-attach-0a", Name: "vpc-a", ResourceID: "vpc-0a", Type: "vpc"},
+			},
+			Routes: []types.TransitGatewayRoute{
+				{
+					DestinationCidrBlock: aws.String("10.0.0.0/16"),
+					State:                "active",
+					Type:                 "propagated",
+					TransitGatewayAttachments: []types.TransitGatewayRouteAttachment{
+						{
+							TransitGatewayAttachmentId: aws.String("tgw-attach-0a"),
+							ResourceId:                 aws.String("vpc-0a"),
+							ResourceType:               "vpc",
+						},
+					},
+				},
+				{
+					DestinationCidrBlock: aws.String("10.9.0.0/16"),
+					State:                "blackhole",
+					Type:                 "static",
+				},
+			},
+		},
+	},
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	generated := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	if err := Write(&buf, []*awsrouter.Tgw{tgw}, generated); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"Generated 2022-10-01T12:00:00Z",
+		`<section id="tgw-0d7f9b0a">`,
+		`<h3 id="tgw-0d7f9b0a-tgw-rtb-0d7f9b0a">Route Table &lt;prod&gt; (tgw-rtb-0d7f9b0a)</h3>`,
+		`<tr id="tgw-0d7f9b0a-tgw-attach-0a"><td>tgw-attach-0a</td><td>vpc-a</td><td>vpc</td><td>vpc-0a</td><td></td><td>&lt;prod&gt;</td><td>&lt;prod&gt;</td></tr>`,
+		`<a href="#tgw-0d7f9b0a-tgw-attach-0a">vpc-a</a>`,
+		`<tr class="blackhole"><td>10.9.0.0/16</td>`,
+		"route to 10.9.0.0/16 in route table &lt;prod&gt; is a blackhole",
+		"<svg ",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Write() does not contain %q", want)
+		}
+	}
+	if strings.Contains(got, "<prod>") {
+		t.Errorf("Write() has the route table name without escaping")
+	}
+	// No external resources, the report must work offline.
+	for _, external := range []string{"src=\"http", "href=\"http", "@import"} {
+		if strings.Contains(got, external) {
+			t.Errorf("Write() has an external resource %q", external)
+		}
+	}
+}
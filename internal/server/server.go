@@ -0,0 +1,397 @@
+/*
+Package server exposes the routing of the Transit Gateways over a local HTTP JSON API.
+
+The routing is kept in memory and refreshed from AWS with Application.UpdateRouting,
+the requests are answered from the cache so they never wait for AWS.
+The responses use the schemas of the package output, the same printed by the commands with --output json.
+*/
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/aws/draw"
+	"github.com/rogerscuall/aws-router/internal/application"
+	"github.com/rogerscuall/aws-router/internal/metrics"
+	"github.com/rogerscuall/aws-router/internal/output"
+)
+
+var (
+	ErrNotLoaded  = errors.New("server: the routing is not loaded yet")
+	ErrNotFound   = errors.New("server: not found")
+	ErrInvalidIP  = errors.New("server: invalid IP address")
+	ErrBadRequest = errors.New("server: bad request")
+)
+
+// Status is the schema of the state of the cache.
+// Error is the error of the last refresh, the routing of the previous refresh is still served.
+type Status struct {
+	Updated time.Time `json:"updated"`
+	Error   string    `json:"error,omitempty"`
+	Tgws    int       `json:"tgws"`
+}
+
+// Server answers the API requests from the TGWs in its cache.
+type Server struct {
+	app     *application.Application
+	metrics *metrics.Collector
+
+	mu      sync.RWMutex
+	tgws    []*awsrouter.Tgw
+	updated time.Time
+	lastErr error
+}
+
+// New returns a Server that refreshes its cache with app, the cache is empty until Refresh is called.
+// If m is not nil the refreshes are recorded in m and the metrics are served in /metrics.
+func New(app *application.Application, m *metrics.Collector) *Server {
+	return &Server{app: app, metrics: m}
+}
+
+// Refresh downloads the routing from AWS and replaces the cache.
+// If there is an error the cache is kept and the error is reported by the status endpoint.
+// If some route tables are incomplete the cache is replaced and the *awsrouter.PartialRoutingError is returned
+// and reported by the status endpoint.
+func (s *Server) Refresh(ctx context.Context) error {
+	tgws, partial, err := s.app.SyncRouting(ctx)
+	if err == nil && partial != nil {
+		err = partial
+	}
+	if s.metrics != nil {
+		s.metrics.ObserveSync(tgws, time.Now(), err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil && partial == nil {
+		s.lastErr = err
+		return err
+	}
+	s.tgws = tgws
+	s.updated = time.Now()
+	s.lastErr = err
+	return err
+}
+
+// Run refreshes the cache every interval until ctx is done. The errors are logged with the ErrorLog of the application.
+func (s *Server) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil {
+				s.app.ErrorLog.Println("error refreshing the routing:", err)
+			}
+		}
+	}
+}
+
+// Status returns the state of the cache.
+func (s *Server) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	st := Status{Updated: s.updated, Tgws: len(s.tgws)}
+	if s.lastErr != nil {
+		st.Error = s.lastErr.Error()
+	}
+	return st
+}
+
+// cache returns the TGWs in the cache, the TGWs are not modified after a refresh so they can be read without the lock.
+func (s *Server) cache() ([]*awsrouter.Tgw, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.updated.IsZero() {
+		return nil, ErrNotLoaded
+	}
+	return s.tgws, nil
+}
+
+// Handler returns the handler of the API:
+//
+//	GET  /api/v1/status
+//	POST /api/v1/refresh
+//	GET  /api/v1/tgws
+//	GET  /api/v1/tgws/{tgw}
+//	GET  /api/v1/tgws/{tgw}/route-tables
+//	GET  /api/v1/tgws/{tgw}/route-tables/{route table}
+//	GET  /api/v1/tgws/{tgw}/route-tables/{route table}/routes
+//	GET  /api/v1/tgws/{tgw}/attachments
+//	GET  /api/v1/tgws/{tgw}/lookup?ip=
+//	GET  /api/v1/tgws/{tgw}/path?src=&dst=
+//	GET  /api/v1/tgws/{tgw}/diagram?format=&src=&dst=
+//	GET  /metrics
+//
+// The TGWs and route tables are found by ID or name. The diagram is the topology of the TGW,
+// or the path between src and dst if both are set. The format of the diagram is svg by default.
+// /metrics is only served when the Server has a metrics.Collector.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/status", s.get(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.Status())
+	}))
+	mux.HandleFunc("/api/v1/refresh", s.handleRefresh)
+	mux.HandleFunc("/api/v1/tgws", s.get(s.handleTgws))
+	mux.HandleFunc("/api/v1/tgws/", s.get(s.handleTgw))
+	if s.metrics != nil {
+		mux.HandleFunc("/metrics", s.get(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", metrics.ContentType)
+			s.metrics.Write(w)
+		}))
+	}
+	return mux
+}
+
+// get rejects the requests that are not GET.
+func (s *Server) get(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%w: method %s not allowed", ErrBadRequest, r.Method))
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%w: method %s not allowed", ErrBadRequest, r.Method))
+		return
+	}
+	if err := s.Refresh(r.Context()); err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Status())
+}
+
+func (s *Server) handleTgws(w http.ResponseWriter, r *http.Request) {
+	tgws, err := s.cache()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, output.NewTgws(tgws))
+}
+
+// handleTgw answers all the requests under a TGW.
+func (s *Server) handleTgw(w http.ResponseWriter, r *http.Request) {
+	tgws, err := s.cache()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/tgws/"), "/"), "/")
+	tgw := findTgw(tgws, parts[0])
+	if tgw == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%w: transit gateway %q", ErrNotFound, parts[0]))
+		return
+	}
+	if len(parts) == 1 {
+		writeJSON(w, http.StatusOK, output.NewTgw(tgw))
+		return
+	}
+	switch parts[1] {
+	case "route-tables":
+		s.handleRouteTables(w, tgw, parts[2:])
+		return
+	case "attachments":
+		if len(parts) == 2 {
+			attachments := make([]output.Attachment, 0)
+			for _, att := range tgw.Attachments() {
+				attachments = append(attachments, output.NewAttachment(att))
+			}
+			writeJSON(w, http.StatusOK, attachments)
+			return
+		}
+	case "lookup":
+		if len(parts) == 2 {
+			s.handleLookup(w, r, tgw)
+			return
+		}
+	case "path":
+		if len(parts) == 2 {
+			s.handlePath(w, r, tgw)
+			return
+		}
+	case "diagram":
+		if len(parts) == 2 {
+			s.handleDiagram(w, r, tgw)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Errorf("%w: %s", ErrNotFound, r.URL.Path))
+}
+
+// handleRouteTables answers the requests under route-tables, parts is the path after it.
+func (s *Server) handleRouteTables(w http.ResponseWriter, tgw *awsrouter.Tgw, parts []string) {
+	if len(parts) == 0 {
+		rts := make([]output.RouteTable, 0, len(tgw.RouteTables))
+		for _, rt := range tgw.RouteTables {
+			rts = append(rts, output.NewRouteTable(rt))
+		}
+		writeJSON(w, http.StatusOK, rts)
+		return
+	}
+	rt := findRouteTable(tgw, parts[0])
+	if rt == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%w: route table %q", ErrNotFound, parts[0]))
+		return
+	}
+	switch {
+	case len(parts) == 1:
+		writeJSON(w, http.StatusOK, output.NewRouteTable(rt))
+	case len(parts) == 2 && parts[1] == "routes":
+		writeJSON(w, http.StatusOK, output.NewRouteTable(rt).Routes)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("%w: %s", ErrNotFound, strings.Join(parts, "/")))
+	}
+}
+
+// handleLookup returns the best route to the IP in every route table of the TGW.
+func (s *Server) handleLookup(w http.ResponseWriter, r *http.Request, tgw *awsrouter.Tgw) {
+	ip, err := queryIP(r, "ip")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	lookups := make([]output.Lookup, 0, len(tgw.RouteTables))
+	for _, rt := range tgw.RouteTables {
+		route, err := rt.BestRouteToIP(ip)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Errorf("error looking up %s in %s: %w", ip, rt.ID, err))
+			return
+		}
+		lookups = append(lookups, output.NewLookup(rt, ip.String(), route))
+	}
+	writeJSON(w, http.StatusOK, lookups)
+}
+
+// pathWalk is the result of a path walk, err is the error returned by AttPath.Walk.
+type pathWalk struct {
+	attPath  *awsrouter.AttPath
+	src, dst string
+	err      error
+}
+
+// walk does the path walk between the IPs src and dst of the request.
+// The walk uses the associations in the cache, so it does not call AWS.
+func walk(r *http.Request, tgw *awsrouter.Tgw) (pathWalk, error) {
+	src, err := queryIP(r, "src")
+	if err != nil {
+		return pathWalk{}, err
+	}
+	dst, err := queryIP(r, "dst")
+	if err != nil {
+		return pathWalk{}, err
+	}
+	p := pathWalk{attPath: awsrouter.NewAttPath(), src: src.String(), dst: dst.String()}
+	p.attPath.Tgw = tgw
+	p.err = p.attPath.Walk(r.Context(), nil, src, dst)
+	return p, nil
+}
+
+// handlePath returns the path walk between src and dst, a walk that does not finish is reported in the field error.
+func (s *Server) handlePath(w http.ResponseWriter, r *http.Request, tgw *awsrouter.Tgw) {
+	p, err := walk(r, tgw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, output.NewPath(p.attPath, p.src, p.dst, p.err))
+}
+
+// handleDiagram draws the topology of the TGW, or the path if src and dst are set.
+func (s *Server) handleDiagram(w http.ResponseWriter, r *http.Request, tgw *awsrouter.Tgw) {
+	formatName := r.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = string(draw.FormatSVG)
+	}
+	format, err := draw.ParseFormat(formatName)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	d := draw.TgwDiagram(*tgw)
+	if r.URL.Query().Get("src") != "" || r.URL.Query().Get("dst") != "" {
+		p, err := walk(r, tgw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		d = draw.PathDiagram(p.attPath, p.src, p.dst, p.err)
+	}
+	// The drawing is done in memory so an error can still be returned as JSON.
+	var b bytes.Buffer
+	if err := draw.Write(&b, d, format); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("error drawing %s: %w", tgw.ID, err))
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Write(b.Bytes())
+}
+
+// queryIP returns the IP address in the query parameter name.
+func queryIP(r *http.Request, name string) (net.IP, error) {
+	value := r.URL.Query().Get(name)
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("%w %q in parameter %s", ErrInvalidIP, value, name)
+	}
+	return ip, nil
+}
+
+// findTgw returns the TGW with the ID or name, or nil if there is none.
+func findTgw(tgws []*awsrouter.Tgw, idOrName string) *awsrouter.Tgw {
+	for _, tgw := range tgws {
+		if tgw.ID == idOrName {
+			return tgw
+		}
+	}
+	for _, tgw := range tgws {
+		if tgw.Name == idOrName {
+			return tgw
+		}
+	}
+	return nil
+}
+
+// findRouteTable returns the route table of tgw with the ID or name, or nil if there is none.
+func findRouteTable(tgw *awsrouter.Tgw, idOrName string) *awsrouter.TgwRouteTable {
+	if rt, err := tgw.GetTgwRouteTableByID(idOrName); err == nil {
+		return rt
+	}
+	for _, rt := range tgw.RouteTables {
+		if rt.Name == idOrName {
+			return rt
+		}
+	}
+	return nil
+}
+
+// writeJSON writes v as the JSON body of the response.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	output.Write(w, output.FormatJSON, v)
+}
+
+// writeError writes err as a JSON object with the field error.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
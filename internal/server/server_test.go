@@ -0,0 +1,374 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/directconnect"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/go-cmp/cmp"
+	"github.com/rogerscuall/aws-router/internal/application"
+	"github.com/rogerscuall/aws-router/internal/metrics"
+	"github.com/rogerscuall/aws-router/internal/output"
+	"github.com/rogerscuall/aws-router/ports"
+)
+
+// TgwDescriberImpl is a fake ports.AWSRouter with one TGW, two route tables and two VPC attachments.
+// If err is set DescribeTransitGateways fails with it.
+type TgwDescriberImpl struct {
+	err error
+}
+
+func attachment(id, vpc string) types.TransitGatewayRouteAttachment {
+	return types.TransitGatewayRouteAttachment{
+		TransitGatewayAttachmentId: aws.String(id),
+		ResourceId:                 aws.String(vpc),
+		ResourceType:               "vpc",
+	}
+}
+
+var (
+	attachmentA  = attachment("tgw-attach-0a", "vpc-0a")
+	attachmentB  = attachment("tgw-attach-0b", "vpc-0b")
+	sharedRoutes = []types.TransitGatewayRoute{
+		{DestinationCidrBlock: aws.String("10.0.0.0/16"), State: "active", Type: "propagated", TransitGatewayAttachments: []types.TransitGatewayRouteAttachment{attachmentA}},
+		{DestinationCidrBlock: aws.String("10.1.0.0/16"), State: "active", Type: "propagated", TransitGatewayAttachments: []types.TransitGatewayRouteAttachment{attachmentB}},
+	}
+	routes = map[string][]types.TransitGatewayRoute{
+		"tgw-rtb-0a": append([]types.TransitGatewayRoute{
+			{DestinationCidrBlock: aws.String("10.1.2.0/24"), State: "blackhole", Type: "static"},
+		}, sharedRoutes...),
+		"tgw-rtb-0b": sharedRoutes,
+	}
+	associations = map[string]types.TransitGatewayRouteAttachment{
+		"tgw-rtb-0a": attachmentA,
+		"tgw-rtb-0b": attachmentB,
+	}
+	names = map[string]string{"tgw-attach-0a": "vpc-a", "tgw-attach-0b": "vpc-b"}
+)
+
+func nameTag(name string) []types.Tag {
+	return []types.Tag{{Key: aws.String("Name"), Value: aws.String(name)}}
+}
+
+func (t TgwDescriberImpl) DescribeTransitGateways(ctx context.Context, params *ec2.DescribeTransitGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewaysOutput, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	return &ec2.DescribeTransitGatewaysOutput{
+		TransitGateways: []types.TransitGateway{
+			{TransitGatewayId: aws.String("tgw-0a"), Tags: nameTag("core"), State: "available"},
+		},
+	}, nil
+}
+
+func (t TgwDescriberImpl) DescribeTransitGatewayRouteTables(ctx context.Context, params *ec2.DescribeTransitGatewayRouteTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayRouteTablesOutput, error) {
+	return &ec2.DescribeTransitGatewayRouteTablesOutput{
+		TransitGatewayRouteTables: []types.TransitGatewayRouteTable{
+			{TransitGatewayRouteTableId: aws.String("tgw-rtb-0a"), TransitGatewayId: aws.String("tgw-0a"), Tags: nameTag("spokes")},
+			{TransitGatewayRouteTableId: aws.String("tgw-rtb-0b"), TransitGatewayId: aws.String("tgw-0a"), Tags: nameTag("shared")},
+		},
+	}, nil
+}
+
+func (t TgwDescriberImpl) SearchTransitGatewayRoutes(ctx context.Context, params *ec2.SearchTransitGatewayRoutesInput, optFns ...func(*ec2.Options)) (*ec2.SearchTransitGatewayRoutesOutput, error) {
+	return &ec2.SearchTransitGatewayRoutesOutput{Routes: routes[aws.StringValue(params.TransitGatewayRouteTableId)]}, nil
+}
+
+func (t TgwDescriberImpl) GetTransitGatewayRouteTableAssociations(ctx context.Context, params *ec2.GetTransitGatewayRouteTableAssociationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayRouteTableAssociationsOutput, error) {
+	att := associations[aws.StringValue(params.TransitGatewayRouteTableId)]
+	return &ec2.GetTransitGatewayRouteTableAssociationsOutput{
+		Associations: []types.TransitGatewayRouteTableAssociation{
+			{TransitGatewayAttachmentId: att.TransitGatewayAttachmentId, ResourceId: att.ResourceId, ResourceType: att.ResourceType},
+		},
+	}, nil
+}
+
+func (t TgwDescriberImpl) DescribeTransitGatewayAttachments(ctx context.Context, params *ec2.DescribeTransitGatewayAttachmentsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayAttachmentsOutput, error) {
+	var atts []types.TransitGatewayAttachment
+	for _, id := range params.TransitGatewayAttachmentIds {
+		atts = append(atts, types.TransitGatewayAttachment{TransitGatewayAttachmentId: aws.String(id), Tags: nameTag(names[id])})
+	}
+	return &ec2.DescribeTransitGatewayAttachmentsOutput{TransitGatewayAttachments: atts}, nil
+}
+
+// GetTransitGatewayRouteTablePropagations returns both attachments propagating to every route table.
+func (t TgwDescriberImpl) GetTransitGatewayRouteTablePropagations(ctx context.Context, params *ec2.GetTransitGatewayRouteTablePropagationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayRouteTablePropagationsOutput, error) {
+	var propagations []types.TransitGatewayRouteTablePropagation
+	for _, att := range []types.TransitGatewayRouteAttachment{attachmentA, attachmentB} {
+		propagations = append(propagations, types.TransitGatewayRouteTablePropagation{
+			TransitGatewayAttachmentId: att.TransitGatewayAttachmentId,
+			ResourceId:                 att.ResourceId,
+			ResourceType:               att.ResourceType,
+			State:                      types.TransitGatewayPropagationStateEnabled,
+		})
+	}
+	return &ec2.GetTransitGatewayRouteTablePropagationsOutput{TransitGatewayRouteTablePropagations: propagations}, nil
+}
+
+func (t TgwDescriberImpl) DescribeManagedPrefixLists(ctx context.Context, params *ec2.DescribeManagedPrefixListsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeManagedPrefixListsOutput, error) {
+	return &ec2.DescribeManagedPrefixListsOutput{}, nil
+}
+
+func (t TgwDescriberImpl) DescribeVpnConnections(ctx context.Context, params *ec2.DescribeVpnConnectionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpnConnectionsOutput, error) {
+	return &ec2.DescribeVpnConnectionsOutput{}, nil
+}
+
+func (t TgwDescriberImpl) DescribeDirectConnectGatewayAssociations(ctx context.Context, params *directconnect.DescribeDirectConnectGatewayAssociationsInput, optFns ...func(*directconnect.Options)) (*directconnect.DescribeDirectConnectGatewayAssociationsOutput, error) {
+	return &directconnect.DescribeDirectConnectGatewayAssociationsOutput{}, nil
+}
+
+func (t TgwDescriberImpl) DescribeTransitGatewayConnects(ctx context.Context, params *ec2.DescribeTransitGatewayConnectsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayConnectsOutput, error) {
+	return &ec2.DescribeTransitGatewayConnectsOutput{}, nil
+}
+
+func (t TgwDescriberImpl) DescribeTransitGatewayConnectPeers(ctx context.Context, params *ec2.DescribeTransitGatewayConnectPeersInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayConnectPeersOutput, error) {
+	return &ec2.DescribeTransitGatewayConnectPeersOutput{}, nil
+}
+
+func (t TgwDescriberImpl) DescribeTransitGatewayPolicyTables(ctx context.Context, params *ec2.DescribeTransitGatewayPolicyTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayPolicyTablesOutput, error) {
+	return &ec2.DescribeTransitGatewayPolicyTablesOutput{}, nil
+}
+
+func (t TgwDescriberImpl) GetTransitGatewayPolicyTableAssociations(ctx context.Context, params *ec2.GetTransitGatewayPolicyTableAssociationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayPolicyTableAssociationsOutput, error) {
+	return &ec2.GetTransitGatewayPolicyTableAssociationsOutput{}, nil
+}
+
+func newTestServer(t *testing.T, api *TgwDescriberImpl) *httptest.Server {
+	t.Helper()
+	app := &application.Application{
+		RouterClient: api,
+		InfoLog:      log.New(io.Discard, "", 0),
+		ErrorLog:     log.New(io.Discard, "", 0),
+	}
+	s := New(app, nil)
+	if err := s.Refresh(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// get returns the status and body of a GET to the path in ts, the body is decoded in v if it is not nil.
+func get(t *testing.T, ts *httptest.Server, path string, v interface{}) (int, string) {
+	t.Helper()
+	resp, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != nil {
+		if err := json.Unmarshal(b, v); err != nil {
+			t.Fatalf("GET %s: error decoding %s: %v", path, b, err)
+		}
+	}
+	return resp.StatusCode, string(b)
+}
+
+func TestServerInventory(t *testing.T) {
+	ts := newTestServer(t, &TgwDescriberImpl{})
+
+	var tgws []output.Tgw
+	get(t, ts, "/api/v1/tgws", &tgws)
+	if len(tgws) != 1 || tgws[0].Name != "core" || len(tgws[0].RouteTables) != 2 {
+		t.Fatalf("tgws = %+v", tgws)
+	}
+
+	var rts []output.RouteTable
+	get(t, ts, "/api/v1/tgws/core/route-tables", &rts)
+	if len(rts) != 2 || rts[0].ID != "tgw-rtb-0a" {
+		t.Errorf("route tables = %+v", rts)
+	}
+
+	var rt output.RouteTable
+	get(t, ts, "/api/v1/tgws/tgw-0a/route-tables/shared", &rt)
+	if rt.ID != "tgw-rtb-0b" {
+		t.Errorf("route table = %+v, want tgw-rtb-0b", rt)
+	}
+
+	var routes []output.Route
+	get(t, ts, "/api/v1/tgws/core/route-tables/tgw-rtb-0a/routes", &routes)
+	if len(routes) != 3 || routes[0].State != "blackhole" {
+		t.Errorf("routes = %+v", routes)
+	}
+
+	var attachments []output.Attachment
+	get(t, ts, "/api/v1/tgws/core/attachments", &attachments)
+	want := []output.Attachment{
+		{ID: "tgw-attach-0a", Name: "vpc-a", ResourceID: "vpc-0a", Type: "vpc"},
+		{ID: "tgw-attach-0b", Name: "vpc-b", ResourceID: "vpc-0b", Type: "vpc"},
+	}
+	if diff := cmp.Diff(want, attachments); diff != "" {
+		t.Errorf("attachments mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestServerLookupAndPath(t *testing.T) {
+	ts := newTestServer(t, &TgwDescriberImpl{})
+
+	var lookups []output.Lookup
+	get(t, ts, "/api/v1/tgws/core/lookup?ip=10.1.2.3", &lookups)
+	if len(lookups) != 2 {
+		t.Fatalf("lookups = %+v", lookups)
+	}
+	if r := lookups[0].Route; r == nil || r.Destination != "10.1.2.0/24" || r.State != "blackhole" {
+		t.Errorf("lookup in tgw-rtb-0a = %+v, want the blackhole", r)
+	}
+	if r := lookups[1].Route; r == nil || r.Destination != "10.1.0.0/16" || r.Attachments[0].Name != "vpc-b" {
+		t.Errorf("lookup in tgw-rtb-0b = %+v, want 10.1.0.0/16 to vpc-b", r)
+	}
+	get(t, ts, "/api/v1/tgws/core/lookup?ip=192.168.0.1", &lookups)
+	if lookups[0].Route != nil {
+		t.Errorf("lookup = %+v, want no route", lookups[0].Route)
+	}
+
+	var path output.Path
+	get(t, ts, "/api/v1/tgws/core/path?src=10.0.0.1&dst=10.1.0.1", &path)
+	if path.Error != "" || len(path.Hops) != 2 || path.Hops[1].Name != "vpc-b" {
+		t.Errorf("path = %+v, want vpc-a -> vpc-b", path)
+	}
+	get(t, ts, "/api/v1/tgws/core/path?src=10.0.0.1&dst=10.1.2.1", &path)
+	if !strings.Contains(path.Error, "blackhole") {
+		t.Errorf("path error = %q, want a blackhole", path.Error)
+	}
+}
+
+func TestServerDiagram(t *testing.T) {
+	ts := newTestServer(t, &TgwDescriberImpl{})
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		contains    string
+	}{
+		{name: "Default SVG", path: "/api/v1/tgws/core/diagram", contentType: "image/svg+xml", contains: "<svg"},
+		{name: "Mermaid", path: "/api/v1/tgws/core/diagram?format=mermaid", contentType: "text/plain; charset=utf-8", contains: "flowchart"},
+		{name: "Path DOT", path: "/api/v1/tgws/core/diagram?format=dot&src=10.0.0.1&dst=10.1.2.1", contentType: "text/vnd.graphviz; charset=utf-8", contains: "blackhole route"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(ts.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			b, _ := io.ReadAll(resp.Body)
+			if got := resp.Header.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %v, want %v", got, tt.contentType)
+			}
+			if !strings.Contains(string(b), tt.contains) {
+				t.Errorf("body does not contain %q:\n%s", tt.contains, b)
+			}
+		})
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	ts := newTestServer(t, &TgwDescriberImpl{})
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "Unknown TGW", path: "/api/v1/tgws/edge", status: http.StatusNotFound},
+		{name: "Unknown Route Table", path: "/api/v1/tgws/core/route-tables/other", status: http.StatusNotFound},
+		{name: "Unknown Resource", path: "/api/v1/tgws/core/vpns", status: http.StatusNotFound},
+		{name: "Invalid IP", path: "/api/v1/tgws/core/lookup?ip=10.0.0", status: http.StatusBadRequest},
+		{name: "Missing Destination", path: "/api/v1/tgws/core/path?src=10.0.0.1", status: http.StatusBadRequest},
+		{name: "Unknown Format", path: "/api/v1/tgws/core/diagram?format=gif", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body struct{ Error string }
+			status, _ := get(t, ts, tt.path, &body)
+			if status != tt.status || body.Error == "" {
+				t.Errorf("GET %s = %v %q, want %v with an error", tt.path, status, body.Error, tt.status)
+			}
+		})
+	}
+}
+
+func TestServerRefresh(t *testing.T) {
+	api := &TgwDescriberImpl{}
+	app := &application.Application{RouterClient: api, ErrorLog: log.New(io.Discard, "", 0)}
+	s := New(app, nil)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	if status, _ := get(t, ts, "/api/v1/tgws", nil); status != http.StatusServiceUnavailable {
+		t.Errorf("status before the first refresh = %v, want %v", status, http.StatusServiceUnavailable)
+	}
+	resp, err := http.Post(ts.URL+"/api/v1/refresh", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("refresh = %v, want %v", resp.StatusCode, http.StatusOK)
+	}
+
+	// A failed refresh keeps serving the previous routing and reports the error.
+	api.err = errors.New("throttled")
+	if err := s.Refresh(context.TODO()); err == nil {
+		t.Fatal("Refresh() error = nil, want an error")
+	}
+	var status Status
+	get(t, ts, "/api/v1/status", &status)
+	if status.Tgws != 1 || status.Updated.IsZero() || !strings.Contains(status.Error, "throttled") {
+		t.Errorf("status = %+v, want the previous TGW and the error", status)
+	}
+	if code, _ := get(t, ts, "/api/v1/tgws/core", nil); code != http.StatusOK {
+		t.Errorf("status = %v, want %v", code, http.StatusOK)
+	}
+}
+
+func TestServerMetrics(t *testing.T) {
+	m := metrics.NewCollector()
+	app := &application.Application{
+		RouterClient: ports.NewInstrumentedRouter(TgwDescriberImpl{}, m.ObserveAPICall),
+		ErrorLog:     log.New(io.Discard, "", 0),
+	}
+	s := New(app, m)
+	if err := s.Refresh(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	status, body := get(t, ts, "/metrics", nil)
+	if status != http.StatusOK {
+		t.Fatalf("status = %v, want %v", status, http.StatusOK)
+	}
+	for _, want := range []string{
+		`awsrouter_route_table_routes{tgw_id="tgw-0a",tgw_name="core",route_table_id="tgw-rtb-0a",route_table_name="spokes",type="propagated",state="active"} 2`,
+		`awsrouter_route_table_blackhole_routes{tgw_id="tgw-0a",tgw_name="core",route_table_id="tgw-rtb-0a",route_table_name="spokes"} 1`,
+		`awsrouter_tgw_attachments{tgw_id="tgw-0a",tgw_name="core",type="vpc"} 2`,
+		`awsrouter_aws_api_calls_total{operation="SearchTransitGatewayRoutes"} 2`,
+		`awsrouter_aws_api_errors_total{operation="DescribeTransitGateways"} 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+
+	// Without a collector there is no /metrics.
+	ts2 := httptest.NewServer(New(app, nil).Handler())
+	defer ts2.Close()
+	if status, _ := get(t, ts2, "/metrics", nil); status != http.StatusNotFound {
+		t.Errorf("status = %v, want %v", status, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,498 @@
+// Package tui is an interactive terminal UI to browse the routing of the Transit Gateways.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/ports"
+)
+
+// screen is one of the views of the UI.
+type screen int
+
+const (
+	screenTgws screen = iota
+	screenRouteTables
+	screenRoutes
+	screenAttachments
+	screenAttachment
+	screenPath
+)
+
+var (
+	titleStyle     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("63"))
+	selectedStyle  = lipgloss.NewStyle().Reverse(true)
+	blackholeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("160"))
+	helpStyle      = lipgloss.NewStyle().Faint(true)
+)
+
+// help is the list of keys of each screen.
+var help = map[screen]string{
+	screenTgws:        "enter: route tables • q: quit",
+	screenRouteTables: "enter: routes • a: attachments • p: path lookup • esc: back",
+	screenRoutes:      "/: filter by prefix or IP • enter: attachment • esc: back",
+	screenAttachments: "enter: attachment • esc: back",
+	screenAttachment:  "r: routes of the associated route table • esc: back",
+	screenPath:        "tab: next field • enter: lookup • esc: back",
+}
+
+// Model is the bubbletea model of the UI.
+// It only reads the TGWs given to New, path lookups use api to read the associations from AWS,
+// if api is nil they use the associations already in the TGWs.
+type Model struct {
+	ctx  context.Context
+	api  ports.AWSRouter
+	tgws []*awsrouter.Tgw
+
+	screen screen
+	back   []screen
+	cursor map[screen]int
+	height int
+
+	tgw *awsrouter.Tgw
+	rt  *awsrouter.TgwRouteTable
+	att *awsrouter.TgwAttachment
+
+	filter    textinput.Model
+	filtering bool
+
+	src, dst textinput.Model
+	result   string
+}
+
+// New returns the Model to browse tgws.
+func New(ctx context.Context, tgws []*awsrouter.Tgw, api ports.AWSRouter) Model {
+	m := Model{
+		ctx:    ctx,
+		api:    api,
+		tgws:   tgws,
+		cursor: make(map[screen]int),
+		filter: textinput.New(),
+		src:    textinput.New(),
+		dst:    textinput.New(),
+	}
+	m.filter.Prompt = "Filter: "
+	m.filter.Placeholder = "10.0.0.0/16 or 10.0.1.1"
+	m.src.Prompt = "Source IP:      "
+	m.dst.Prompt = "Destination IP: "
+	return m
+}
+
+// Init is called by bubbletea when the program starts.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// open moves to the screen s, esc goes back to the current one.
+func (m *Model) open(s screen) {
+	m.back = append(m.back, m.screen)
+	m.screen = s
+	m.cursor[s] = 0
+}
+
+// routes returns the routes of the selected route table that match the filter.
+func (m Model) routes() []types.TransitGatewayRoute {
+	if m.rt == nil {
+		return nil
+	}
+	return filterRoutes(m.rt.Routes, m.filter.Value())
+}
+
+// rows returns the lines of the list of the current screen, the cursor selects one of them.
+func (m Model) rows() []string {
+	var rows []string
+	switch m.screen {
+	case screenTgws:
+		for _, tgw := range m.tgws {
+			rows = append(rows, fmt.Sprintf("%-30s %s (%d route tables)", tgw.Name, tgw.ID, len(tgw.RouteTables)))
+		}
+	case screenRouteTables:
+		for _, rt := range m.tgw.RouteTables {
+			rows = append(rows, fmt.Sprintf("%-30s %s (%d routes)", rt.Name, rt.ID, len(rt.Routes)))
+		}
+	case screenRoutes:
+		for _, route := range m.routes() {
+			rows = append(rows, routeRow(m.tgw, route))
+		}
+	case screenAttachments:
+		for _, att := range m.tgw.Attachments() {
+			rows = append(rows, fmt.Sprintf("%-30s %-25s %-25s %s", att.Name, att.ID, att.ResourceID, att.Type))
+		}
+	}
+	return rows
+}
+
+// Update handles the keys and the size of the terminal.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		return m, nil
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+		if m.screen == screenPath {
+			return m.updatePath(msg)
+		}
+		return m.updateList(msg)
+	}
+	return m, nil
+}
+
+// updateFilter sends the keys to the filter of the routes until enter or esc.
+func (m Model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter, tea.KeyEsc:
+		m.filtering = false
+		m.filter.Blur()
+		m.cursor[screenRoutes] = 0
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	m.cursor[screenRoutes] = 0
+	return m, cmd
+}
+
+// updatePath sends the keys to the IP fields of the path lookup.
+func (m Model) updatePath(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.goBack()
+		return m, nil
+	case tea.KeyTab, tea.KeyShiftTab:
+		if m.src.Focused() {
+			m.src.Blur()
+			return m, m.dst.Focus()
+		}
+		m.dst.Blur()
+		return m, m.src.Focus()
+	case tea.KeyEnter:
+		m.result = lookupPath(m.ctx, m.api, m.tgw, m.src.Value(), m.dst.Value())
+		return m, nil
+	}
+	var cmd tea.Cmd
+	if m.src.Focused() {
+		m.src, cmd = m.src.Update(msg)
+	} else {
+		m.dst, cmd = m.dst.Update(msg)
+	}
+	return m, cmd
+}
+
+// goBack returns to the previous screen.
+func (m *Model) goBack() {
+	if len(m.back) == 0 {
+		return
+	}
+	m.screen = m.back[len(m.back)-1]
+	m.back = m.back[:len(m.back)-1]
+	if m.screen != screenRoutes {
+		m.filter.SetValue("")
+	}
+}
+
+// updateList moves the cursor and opens the selected element.
+func (m Model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rows := m.rows()
+	cursor := m.cursor[m.screen]
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "esc":
+		m.goBack()
+	case "up", "k":
+		if cursor > 0 {
+			m.cursor[m.screen] = cursor - 1
+		}
+	case "down", "j":
+		if cursor < len(rows)-1 {
+			m.cursor[m.screen] = cursor + 1
+		}
+	case "enter":
+		if cursor >= len(rows) {
+			return m, nil
+		}
+		switch m.screen {
+		case screenTgws:
+			m.tgw = m.tgws[cursor]
+			m.open(screenRouteTables)
+		case screenRouteTables:
+			m.rt = m.tgw.RouteTables[cursor]
+			m.filter.SetValue("")
+			m.open(screenRoutes)
+		case screenRoutes:
+			route := m.routes()[cursor]
+			if len(route.TransitGatewayAttachments) == 0 {
+				return m, nil
+			}
+			m.att = m.attachment(aws.StringValue(route.TransitGatewayAttachments[0].TransitGatewayAttachmentId))
+			m.open(screenAttachment)
+		case screenAttachments:
+			m.att = m.tgw.Attachments()[cursor]
+			m.open(screenAttachment)
+		}
+	case "/":
+		if m.screen == screenRoutes {
+			m.filtering = true
+			return m, m.filter.Focus()
+		}
+	case "a":
+		if m.screen == screenRouteTables {
+			m.open(screenAttachments)
+		}
+	case "p":
+		if m.screen == screenRouteTables {
+			m.open(screenPath)
+			m.result = ""
+			m.src.SetValue("")
+			m.dst.SetValue("")
+			m.dst.Blur()
+			return m, m.src.Focus()
+		}
+	case "r":
+		if m.screen == screenAttachment {
+			if rt := m.tgw.AssociatedRouteTable(m.att.ID); rt != nil {
+				m.rt = rt
+				m.filter.SetValue("")
+				m.open(screenRoutes)
+			}
+		}
+	}
+	return m, nil
+}
+
+// attachment returns the attachment of the selected TGW with the ID, with all the information available.
+func (m Model) attachment(id string) *awsrouter.TgwAttachment {
+	for _, att := range m.tgw.Attachments() {
+		if att.ID == id {
+			return att
+		}
+	}
+	return &awsrouter.TgwAttachment{ID: id}
+}
+
+// View renders the current screen.
+func (m Model) View() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(m.breadcrumb()) + "\n\n")
+	switch m.screen {
+	case screenAttachment:
+		b.WriteString(attachmentDetail(m.tgw, m.att))
+	case screenPath:
+		b.WriteString(m.src.View() + "\n" + m.dst.View() + "\n\n" + m.result)
+	default:
+		if m.screen == screenRoutes {
+			b.WriteString(m.filter.View() + "\n\n")
+		}
+		b.WriteString(m.listView())
+	}
+	b.WriteString("\n" + helpStyle.Render(help[m.screen]) + "\n")
+	return b.String()
+}
+
+// breadcrumb returns the title of the screen with the selected TGW and route table.
+func (m Model) breadcrumb() string {
+	parts := []string{"Transit Gateways"}
+	if m.screen != screenTgws && m.tgw != nil {
+		parts = append(parts, m.tgw.Name)
+	}
+	switch m.screen {
+	case screenRoutes:
+		parts = append(parts, m.rt.Name)
+	case screenAttachments:
+		parts = append(parts, "Attachments")
+	case screenAttachment:
+		parts = append(parts, m.att.ID)
+	case screenPath:
+		parts = append(parts, "Path lookup")
+	}
+	return strings.Join(parts, " > ")
+}
+
+// listView renders the rows around the cursor that fit in the terminal.
+func (m Model) listView() string {
+	rows := m.rows()
+	if len(rows) == 0 {
+		return "Nothing to show.\n"
+	}
+	visible := m.height - 8
+	if visible < 5 {
+		visible = 20
+	}
+	cursor := m.cursor[m.screen]
+	start := 0
+	if cursor >= visible {
+		start = cursor - visible + 1
+	}
+	end := start + visible
+	if end > len(rows) {
+		end = len(rows)
+	}
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		row := rows[i]
+		switch {
+		case i == cursor:
+			row = selectedStyle.Render(row)
+		case m.screen == screenRoutes && strings.Contains(row, string(types.TransitGatewayRouteStateBlackhole)):
+			row = blackholeStyle.Render(row)
+		}
+		b.WriteString(row + "\n")
+	}
+	if len(rows) > visible {
+		b.WriteString(helpStyle.Render(fmt.Sprintf("%d/%d", cursor+1, len(rows))) + "\n")
+	}
+	return b.String()
+}
+
+// routeRow returns a route as a line of the list of routes.
+func routeRow(tgw *awsrouter.Tgw, route types.TransitGatewayRoute) string {
+	destination := aws.StringValue(route.DestinationCidrBlock)
+	if destination == "" {
+		destination = aws.StringValue(route.PrefixListId)
+	}
+	var nextHops []string
+	for _, att := range route.TransitGatewayAttachments {
+		id := aws.StringValue(att.TransitGatewayAttachmentId)
+		if name := tgw.GetAttachmentName(id); name != "" {
+			id = name
+		}
+		nextHops = append(nextHops, id)
+	}
+	return fmt.Sprintf("%-20s %-11s %-9s %s", destination, route.Type, route.State, strings.Join(nextHops, ", "))
+}
+
+// attachmentDetail returns the details of an attachment and how it is used by the route tables of the tgw.
+func attachmentDetail(tgw *awsrouter.Tgw, att *awsrouter.TgwAttachment) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ID:           %s\n", att.ID)
+	fmt.Fprintf(&b, "Name:         %s\n", att.Name)
+	fmt.Fprintf(&b, "Type:         %s\n", att.Type)
+	fmt.Fprintf(&b, "Resource:     %s\n", att.ResourceID)
+	fmt.Fprintf(&b, "Owner:        %s\n", att.OwnerID)
+	associated := "none"
+	if rt := tgw.AssociatedRouteTable(att.ID); rt != nil {
+		associated = fmt.Sprintf("%s (%s)", rt.Name, rt.ID)
+	}
+	fmt.Fprintf(&b, "Associated:   %s\n", associated)
+	var propagating []string
+	for _, rt := range tgw.PropagatingRouteTables(att.ID) {
+		propagating = append(propagating, rt.Name)
+	}
+	fmt.Fprintf(&b, "Propagates:   %s\n", strings.Join(propagating, ", "))
+	var routes int
+	for _, rt := range tgw.RouteTables {
+		for _, route := range rt.Routes {
+			for _, a := range route.TransitGatewayAttachments {
+				if aws.StringValue(a.TransitGatewayAttachmentId) == att.ID {
+					routes++
+				}
+			}
+		}
+	}
+	fmt.Fprintf(&b, "Next hop of:  %d routes\n", routes)
+	return b.String()
+}
+
+// filterRoutes returns the routes that match the query.
+// A prefix matches the routes that contain it or are inside it, an IP matches the routes that contain it,
+// anything else matches the routes where the destination, the prefix list or a next hop contains the text.
+func filterRoutes(routes []types.TransitGatewayRoute, query string) []types.TransitGatewayRoute {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return routes
+	}
+	var match func(types.TransitGatewayRoute) bool
+	_, queryNet, err := net.ParseCIDR(query)
+	queryIP := net.ParseIP(query)
+	switch {
+	case err == nil:
+		match = func(route types.TransitGatewayRoute) bool {
+			_, routeNet, err := net.ParseCIDR(aws.StringValue(route.DestinationCidrBlock))
+			return err == nil && (routeNet.Contains(queryNet.IP) || queryNet.Contains(routeNet.IP))
+		}
+	case queryIP != nil:
+		match = func(route types.TransitGatewayRoute) bool {
+			_, routeNet, err := net.ParseCIDR(aws.StringValue(route.DestinationCidrBlock))
+			return err == nil && routeNet.Contains(queryIP)
+		}
+	default:
+		match = func(route types.TransitGatewayRoute) bool {
+			text := []string{aws.StringValue(route.DestinationCidrBlock), aws.StringValue(route.PrefixListId)}
+			for _, att := range route.TransitGatewayAttachments {
+				text = append(text, aws.StringValue(att.TransitGatewayAttachmentId), aws.StringValue(att.ResourceId))
+			}
+			return strings.Contains(strings.Join(text, " "), query)
+		}
+	}
+	var result []types.TransitGatewayRoute
+	for _, route := range routes {
+		if match(route) {
+			result = append(result, route)
+		}
+	}
+	return result
+}
+
+// lookupPath walks the path from src to dst in the tgw and returns the result as text.
+func lookupPath(ctx context.Context, api ports.AWSRouter, tgw *awsrouter.Tgw, src, dst string) string {
+	srcIP, dstIP := net.ParseIP(strings.TrimSpace(src)), net.ParseIP(strings.TrimSpace(dst))
+	if srcIP == nil || dstIP == nil {
+		return "Invalid IP address."
+	}
+	attPath := awsrouter.NewAttPath()
+	attPath.Tgw = tgw
+	err := attPath.Walk(ctx, api, srcIP, dstIP)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Path: %s\n\n", attPath.String())
+	for _, hop := range attPath.Hops {
+		name := hop.RouteTableName
+		if name == "" {
+			name = hop.RouteTableID
+		}
+		switch {
+		case hop.Prefix == "":
+			fmt.Fprintf(&b, "%s: no route\n", name)
+		case hop.VpnDown:
+			vpn := hop.NextHops[0].Name
+			if vpn == "" {
+				vpn = hop.NextHops[0].ID
+			}
+			fmt.Fprintf(&b, "%s: %s -> %s %s\n", name, hop.Prefix, vpn, blackholeStyle.Render("VPN tunnels down"))
+		case hop.Dropped():
+			fmt.Fprintf(&b, "%s: %s %s\n", name, hop.Prefix, blackholeStyle.Render("blackhole"))
+		default:
+			var nextHops []string
+			for _, att := range hop.NextHops {
+				if att.Name != "" {
+					nextHops = append(nextHops, att.Name)
+				} else {
+					nextHops = append(nextHops, att.ID)
+				}
+			}
+			line := fmt.Sprintf("%s: %s -> %s", name, hop.Prefix, nextHops[0])
+			if len(nextHops) > 1 {
+				line += fmt.Sprintf(" (ECMP with %s)", strings.Join(nextHops[1:], ", "))
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(&b, "\n%s\n", blackholeStyle.Render(err.Error()))
+	}
+	return b.String()
+}
@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+)
+
+func route(cidr, state string, ids ...string) types.TransitGatewayRoute {
+	r := types.TransitGatewayRoute{
+		DestinationCidrBlock: aws.String(cidr),
+		State:                types.TransitGatewayRouteState(state),
+		Type:                 "propagated",
+	}
+	for _, id := range ids {
+		r.TransitGatewayAttachments = append(r.TransitGatewayAttachments, types.TransitGatewayRouteAttachment{
+			TransitGatewayAttachmentId: aws.String(id),
+			ResourceId:                 aws.String(strings.Replace(id, "tgw-attach", "vpc", 1)),
+			ResourceType:               "vpc",
+		})
+	}
+	return r
+}
+
+var routes = []types.TransitGatewayRoute{
+	route("10.0.0.0/16", "active", "tgw-attach-0a"),
+	route("10.1.0.0/16", "active", "tgw-attach-0b"),
+	route("10.1.2.0/24", "blackhole"),
+}
+
+func testTgws() []*awsrouter.Tgw {
+	return []*awsrouter.Tgw{
+		{ID: "tgw-0z", Name: "other"},
+		{
+			ID:   "tgw-0a",
+			Name: "core",
+			RouteTables: []*awsrouter.TgwRouteTable{
+				{ID: "tgw-rtb-0a", Name: "a", Attachments: []*awsrouter.TgwAttachment{{ID: "tgw-attach-0a", Name: "vpc-a", ResourceID: "vpc-0a", Type: "vpc"}}, Routes: routes},
+				{ID: "tgw-rtb-0b", Name: "b", Attachments: []*awsrouter.TgwAttachment{{ID: "tgw-attach-0b", Name: "vpc-b", ResourceID: "vpc-0b", Type: "vpc"}}, Routes: routes},
+			},
+		},
+	}
+}
+
+// keys sends the keys to the model, each string is a special key like "enter" or text typed.
+func keys(m tea.Model, keys ...string) tea.Model {
+	special := map[string]tea.KeyType{"enter": tea.KeyEnter, "esc": tea.KeyEsc, "down": tea.KeyDown, "up": tea.KeyUp, "tab": tea.KeyTab}
+	for _, k := range keys {
+		if t, ok := special[k]; ok {
+			m, _ = m.Update(tea.KeyMsg{Type: t})
+			continue
+		}
+		for _, r := range k {
+			m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+	return m
+}
+
+func TestFilterRoutes(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "Empty", query: "", want: []string{"10.0.0.0/16", "10.1.0.0/16", "10.1.2.0/24"}},
+		{name: "Prefix Inside", query: "10.1.2.128/25", want: []string{"10.1.0.0/16", "10.1.2.0/24"}},
+		{name: "Prefix Covering", query: "10.0.0.0/8", want: []string{"10.0.0.0/16", "10.1.0.0/16", "10.1.2.0/24"}},
+		{name: "IP", query: "10.1.3.1", want: []string{"10.1.0.0/16"}},
+		{name: "Text", query: "attach-0a", want: []string{"10.0.0.0/16"}},
+		{name: "No Match", query: "192.168.0.1", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range filterRoutes(routes, tt.query) {
+				got = append(got, aws.StringValue(r.DestinationCidrBlock))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterRoutes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelNavigation(t *testing.T) {
+	m := keys(New(context.TODO(), testTgws(), nil), "down", "enter", "down", "enter").(Model)
+	if m.screen != screenRoutes || m.rt.ID != "tgw-rtb-0b" {
+		t.Fatalf("screen = %v, route table = %v, want the routes of tgw-rtb-0b", m.screen, m.rt.ID)
+	}
+	if got := m.View(); !strings.Contains(got, "Transit Gateways > core > b") || !strings.Contains(got, "vpc-a") {
+		t.Errorf("View() = %v", got)
+	}
+
+	// Filter the routes and jump to the attachment of the only route left.
+	m = keys(m, "/", "10.1.3.1", "enter").(Model)
+	if got := len(m.routes()); got != 1 {
+		t.Fatalf("filtered routes = %d, want 1", got)
+	}
+	m = keys(m, "enter").(Model)
+	if m.screen != screenAttachment || m.att.Name != "vpc-b" {
+		t.Fatalf("screen = %v, attachment = %v, want the attachment vpc-b", m.screen, m.att)
+	}
+	if got := m.View(); !strings.Contains(got, "Associated:   b (tgw-rtb-0b)") {
+		t.Errorf("View() = %v", got)
+	}
+
+	// Back to the routes the filter is kept, back to the route tables it is cleared.
+	m = keys(m, "esc").(Model)
+	if m.screen != screenRoutes || m.filter.Value() != "10.1.3.1" {
+		t.Errorf("screen = %v, filter = %q, want the filtered routes", m.screen, m.filter.Value())
+	}
+	m = keys(m, "esc").(Model)
+	if m.screen != screenRouteTables || m.filter.Value() != "" {
+		t.Errorf("screen = %v, filter = %q, want the route tables without filter", m.screen, m.filter.Value())
+	}
+}
+
+func TestModelPath(t *testing.T) {
+	m := keys(New(context.TODO(), testTgws(), nil), "down", "enter", "p", "10.0.0.1", "tab", "10.1.0.1", "enter").(Model)
+	if m.screen != screenPath {
+		t.Fatalf("screen = %v, want %v", m.screen, screenPath)
+	}
+	for _, want := range []string{"Path: tgw-attach-0a -> tgw-attach-0b", "a: 10.1.0.0/16 -> vpc-b"} {
+		if !strings.Contains(m.result, want) {
+			t.Errorf("result = %v, want it to contain %q", m.result, want)
+		}
+	}
+	m = keys(m, "esc", "esc").(Model)
+	if m.screen != screenTgws {
+		t.Errorf("screen = %v, want %v", m.screen, screenTgws)
+	}
+	m = keys(m, "down", "enter", "p", "10.0.0.1", "tab", "10.1.2.1", "enter").(Model)
+	if !strings.Contains(m.result, "blackhole") {
+		t.Errorf("result = %v, want a blackhole", m.result)
+	}
+}
+
+func TestModelPathVpnDown(t *testing.T) {
+	tgws := testTgws()
+	vpn := route("10.1.0.0/16", "active", "tgw-attach-0b")
+	vpn.TransitGatewayAttachments[0].ResourceId = aws.String("vpn-0b")
+	vpn.TransitGatewayAttachments[0].ResourceType = "vpn"
+	for _, rt := range tgws[1].RouteTables {
+		rt.Routes = []types.TransitGatewayRoute{routes[0], vpn}
+	}
+	tgws[1].VpnConnections = []*awsrouter.VpnConnection{{ID: "vpn-0b", State: "available", Tunnels: []awsrouter.VpnTunnel{{Status: "DOWN"}}}}
+	m := keys(New(context.TODO(), tgws, nil), "down", "enter", "p", "10.0.0.1", "tab", "10.1.0.1", "enter").(Model)
+	if !strings.Contains(m.result, "a: 10.1.0.0/16 -> vpc-b VPN tunnels down") || strings.Contains(m.result, "blackhole") {
+		t.Errorf("result = %v, want the VPN tunnels down", m.result)
+	}
+}
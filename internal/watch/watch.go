@@ -0,0 +1,159 @@
+/*
+Package watch polls the routing of the Transit Gateways and reports what changed since the last poll.
+
+The routing seen in the last poll is stored in a ports.DbPort with application.SaveSnapshot,
+so a restart compares against it and does not report again the changes already reported.
+*/
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/internal/application"
+	"github.com/rogerscuall/aws-router/internal/output"
+	"github.com/rogerscuall/aws-router/ports"
+)
+
+// Watcher compares the routing of each poll with the previous one and writes the changes.
+type Watcher struct {
+	app     *application.Application
+	db      ports.DbPort
+	out     io.Writer
+	webhook string
+	client  *http.Client
+}
+
+// New returns a Watcher that reads the routing with app, stores the last seen routing in db
+// and writes the changes to out as JSON lines. If webhook is not empty each change is also sent to it with a POST.
+func New(app *application.Application, db ports.DbPort, out io.Writer, webhook string) *Watcher {
+	return &Watcher{
+		app:     app,
+		db:      db,
+		out:     out,
+		webhook: webhook,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Run polls the routing now and then every interval until ctx is done. The errors are logged with the ErrorLog of the application.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Poll(ctx); err != nil {
+			w.app.ErrorLog.Println(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll downloads the routing from AWS and reports the changes with Check.
+// A poll where some route tables are incomplete is not checked, the missing routes and associations
+// would be reported as removed and then as added again by the next poll.
+func (w *Watcher) Poll(ctx context.Context) error {
+	tgws, partial, err := w.app.SyncRouting(ctx)
+	if err != nil {
+		return err
+	}
+	if partial != nil {
+		return fmt.Errorf("error polling the routing, skipping the check: %w", partial)
+	}
+	_, err = w.Check(tgws, time.Now())
+	return err
+}
+
+// Check reports the changes from the last seen routing to tgws, found at t, and stores tgws as the last seen routing.
+// If there is no last seen routing, tgws is stored as the baseline and no change is reported.
+// Any other error reading the last seen routing is returned, and tgws is not stored.
+// After the webhook fails the changes not sent are kept in the db and sent first by the next Check.
+// The routing is stored even if the webhook fails, so the changes are not written twice to out.
+func (w *Watcher) Check(tgws []*awsrouter.Tgw, t time.Time) ([]output.Change, error) {
+	old, err := application.LoadSnapshot(w.db)
+	if errors.Is(err, application.ErrNoSnapshot) {
+		w.app.InfoLog.Println("no previous routing found, saving the current routing as baseline")
+		return nil, application.SaveSnapshot(w.db, tgws)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading the previous routing: %w", err)
+	}
+	changes := make([]output.Change, 0)
+	for _, change := range awsrouter.DiffTgws(old, tgws) {
+		changes = append(changes, output.NewChange(change, t))
+	}
+	enc := json.NewEncoder(w.out)
+	for _, change := range changes {
+		if err := enc.Encode(change); err != nil {
+			return changes, fmt.Errorf("error writing the change: %w", err)
+		}
+	}
+	var sendErr error
+	if w.webhook != "" {
+		sendErr = w.deliver(changes)
+	}
+	if err := application.SaveSnapshot(w.db, tgws); err != nil {
+		return changes, err
+	}
+	return changes, sendErr
+}
+
+// pendingKey is the key in the db of the changes not sent to the webhook yet.
+const pendingKey = "watch-pending"
+
+// deliver sends to the webhook the changes pending from a previous Check and then changes, in order.
+// The changes not sent after an error are stored as pending.
+func (w *Watcher) deliver(changes []output.Change) error {
+	var queue []output.Change
+	if b, err := w.db.GetVal(pendingKey); err == nil && len(b) > 0 {
+		if err := json.Unmarshal(b, &queue); err != nil {
+			return fmt.Errorf("error decoding the pending changes: %w", err)
+		}
+	}
+	queue = append(queue, changes...)
+	if len(queue) == 0 {
+		return nil
+	}
+	var sendErr error
+	sent := 0
+	for ; sent < len(queue); sent++ {
+		if sendErr = w.send(queue[sent]); sendErr != nil {
+			break
+		}
+	}
+	b, err := json.Marshal(queue[sent:])
+	if err != nil {
+		return err
+	}
+	if err := w.db.SetVal(pendingKey, b); err != nil {
+		return fmt.Errorf("error saving the pending changes: %w", err)
+	}
+	return sendErr
+}
+
+// send posts the change as JSON to the webhook.
+func (w *Watcher) send(change output.Change) error {
+	b, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Post(w.webhook, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("error sending the change to the webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("error sending the change to the webhook: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/go-cmp/cmp"
+	"github.com/rogerscuall/aws-router/aws/awsrouter"
+	"github.com/rogerscuall/aws-router/internal/application"
+	"github.com/rogerscuall/aws-router/internal/fakeec2"
+	"github.com/rogerscuall/aws-router/internal/output"
+	"github.com/rogerscuall/aws-router/ports"
+)
+
+// memDb is a ports.DbPort in memory.
+type memDb map[string][]byte
+
+func (m memDb) CloseDbConnection() {}
+
+func (m memDb) GetVal(key string) ([]byte, error) {
+	v, ok := m[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ports.ErrKeyNotFound, key)
+	}
+	return v, nil
+}
+
+func (m memDb) SetVal(key string, val []byte) error {
+	m[key] = val
+	return nil
+}
+
+func (m memDb) Sync() {}
+
+// routing returns a TGW with a route table that has a route to 10.0.0.0/16 in state.
+func routing(state types.TransitGatewayRouteState) []*awsrouter.Tgw {
+	return []*awsrouter.Tgw{
+		{
+			ID:   "tgw-0a",
+			Name: "core",
+			RouteTables: []*awsrouter.TgwRouteTable{
+				{
+					ID:   "tgw-rtb-0a",
+					Name: "spokes",
+					Routes: []types.TransitGatewayRoute{
+						{DestinationCidrBlock: aws.String("10.0.0.0/16"), State: state, Type: "static"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestWatcherCheck(t *testing.T) {
+	var sent []output.Change
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var c output.Change
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			t.Errorf("error decoding the webhook body: %v", err)
+		}
+		sent = append(sent, c)
+	}))
+	defer webhook.Close()
+
+	app := &application.Application{InfoLog: log.New(io.Discard, "", 0), ErrorLog: log.New(io.Discard, "", 0)}
+	db := memDb{}
+	var out bytes.Buffer
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	// The first check saves the baseline without changes.
+	changes, err := New(app, db, &out, webhook.URL).Check(routing("active"), now)
+	if err != nil || len(changes) != 0 || out.Len() != 0 {
+		t.Fatalf("Check() = %v, %v, output %q, want the baseline without changes", changes, err, out.String())
+	}
+
+	// A new Watcher with the same DB, like after a restart, only reports what changed since the baseline.
+	changes, err = New(app, db, &out, webhook.URL).Check(routing("blackhole"), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []output.Change{
+		{
+			Time:         now,
+			Kind:         "route-blackhole",
+			TgwID:        "tgw-0a",
+			TgwName:      "core",
+			RouteTableID: "tgw-rtb-0a",
+			Prefix:       "10.0.0.0/16",
+			Old:          "active",
+			New:          "blackhole",
+			Message:      "route 10.0.0.0/16 in spokes is now a blackhole",
+		},
+	}
+	if diff := cmp.Diff(want, changes); diff != "" {
+		t.Errorf("Check() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want, sent); diff != "" {
+		t.Errorf("webhook mismatch (-want +got):\n%s", diff)
+	}
+	wantLine := `{"time":"2022-08-01T10:00:00Z","kind":"route-blackhole","tgw_id":"tgw-0a","tgw_name":"core","route_table_id":"tgw-rtb-0a","prefix":"10.0.0.0/16","old":"active","new":"blackhole","message":"route 10.0.0.0/16 in spokes is now a blackhole"}` + "\n"
+	if out.String() != wantLine {
+		t.Errorf("output = %q, want %q", out.String(), wantLine)
+	}
+
+	// Nothing changed since the last check.
+	out.Reset()
+	changes, err = New(app, db, &out, "").Check(routing("blackhole"), now)
+	if err != nil || len(changes) != 0 || out.Len() != 0 {
+		t.Errorf("Check() = %v, %v, output %q, want no changes", changes, err, out.String())
+	}
+}
+
+// brokenDb is a ports.DbPort that can not be read.
+type brokenDb struct{ memDb }
+
+func (b brokenDb) GetVal(key string) ([]byte, error) {
+	return nil, errors.New("database is locked")
+}
+
+func TestWatcherCheckDbError(t *testing.T) {
+	app := &application.Application{InfoLog: log.New(io.Discard, "", 0), ErrorLog: log.New(io.Discard, "", 0)}
+	db := brokenDb{memDb{}}
+	var out bytes.Buffer
+	// The routing is not saved as a new baseline, it would hide the changes since the last check.
+	_, err := New(app, db, &out, "").Check(routing("active"), time.Now())
+	if err == nil || !strings.Contains(err.Error(), "database is locked") || len(db.memDb) != 0 {
+		t.Errorf("Check() error = %v, saved %d keys, want the db error and nothing saved", err, len(db.memDb))
+	}
+}
+
+func TestWatcherCheckWebhookError(t *testing.T) {
+	down := true
+	var sent []output.Change
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		var c output.Change
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			t.Errorf("error decoding the webhook body: %v", err)
+		}
+		sent = append(sent, c)
+	}))
+	defer webhook.Close()
+
+	app := &application.Application{InfoLog: log.New(io.Discard, "", 0), ErrorLog: log.New(io.Discard, "", 0)}
+	db := memDb{}
+	w := New(app, db, io.Discard, webhook.URL)
+	if _, err := w.Check(routing("active"), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	_, err := w.Check(routing("blackhole"), time.Now())
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("Check() error = %v, want the error of the webhook", err)
+	}
+	// The routing is saved anyway, so the change is not reported again, but it is still pending for the webhook.
+	changes, err := w.Check(routing("blackhole"), time.Now())
+	if err == nil || len(changes) != 0 {
+		t.Errorf("Check() = %v, %v, want no changes and the error of the webhook", changes, err)
+	}
+	// When the webhook is back the pending change is sent before the new one, by a new Watcher like after a restart.
+	down = false
+	changes, err = New(app, db, io.Discard, webhook.URL).Check(routing("active"), time.Now())
+	if err != nil || len(changes) != 1 {
+		t.Fatalf("Check() = %v, %v, want one change", changes, err)
+	}
+	var kinds []string
+	for _, c := range sent {
+		kinds = append(kinds, c.Kind)
+	}
+	if want := []string{"route-blackhole", "route-active"}; !cmp.Equal(kinds, want) {
+		t.Errorf("webhook kinds = %v, want %v", kinds, want)
+	}
+	// Nothing is pending anymore.
+	sent = nil
+	if _, err := w.Check(routing("active"), time.Now()); err != nil || len(sent) != 0 {
+		t.Errorf("Check() error = %v, sent %v, want nothing sent", err, sent)
+	}
+}
+
+// throttledRoutes is a ports.AWSRouter where the searches of routes fail.
+type throttledRoutes struct {
+	ports.AWSRouter
+}
+
+func (throttledRoutes) SearchTransitGatewayRoutes(ctx context.Context, params *ec2.SearchTransitGatewayRoutesInput, optFns ...func(*ec2.Options)) (*ec2.SearchTransitGatewayRoutesOutput, error) {
+	return nil, errors.New("Throttling: Rate exceeded")
+}
+
+func TestWatcherPollPartial(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_CONFIG_FILE", "testdata/missing")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "testdata/missing")
+	topology, err := fakeec2.LoadTopology("../fakeec2/testdata/topology.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := fakeec2.Start(topology)
+	defer srv.Close()
+	app := &application.Application{InfoLog: log.New(io.Discard, "", 0), ErrorLog: log.New(io.Discard, "", 0)}
+	if err := app.Init(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	db := memDb{}
+	var out bytes.Buffer
+	w := New(app, db, &out, "")
+	if err := w.Poll(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	baseline := string(db["tgw-0a"])
+
+	// The route tables without routes are not compared nor saved.
+	app.RouterClient = throttledRoutes{app.RouterClient}
+	err = w.Poll(context.TODO())
+	var partial *awsrouter.PartialRoutingError
+	if !errors.As(err, &partial) || !partial.Incomplete("tgw-rtb-0a") || !partial.Incomplete("tgw-rtb-0b") {
+		t.Errorf("Poll() error = %v, want the incomplete route tables", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("output = %q, want no changes", out.String())
+	}
+	if string(db["tgw-0a"]) != baseline {
+		t.Errorf("the incomplete routing replaced the baseline")
+	}
+}
@@ -7,6 +7,7 @@ This library is a collection of calls to work with routing information on AWS.
 import (
 	"context"
 
+	"github.com/aws/aws-sdk-go-v2/service/directconnect"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go/aws"
@@ -19,6 +20,14 @@ type AWSRouter interface {
 	SearchTransitGatewayRoutes(ctx context.Context, params *ec2.SearchTransitGatewayRoutesInput, optFns ...func(*ec2.Options)) (*ec2.SearchTransitGatewayRoutesOutput, error)
 	GetTransitGatewayRouteTableAssociations(ctx context.Context, params *ec2.GetTransitGatewayRouteTableAssociationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayRouteTableAssociationsOutput, error)
 	DescribeTransitGatewayAttachments(ctx context.Context, params *ec2.DescribeTransitGatewayAttachmentsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayAttachmentsOutput, error)
+	GetTransitGatewayRouteTablePropagations(ctx context.Context, params *ec2.GetTransitGatewayRouteTablePropagationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayRouteTablePropagationsOutput, error)
+	DescribeManagedPrefixLists(ctx context.Context, params *ec2.DescribeManagedPrefixListsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeManagedPrefixListsOutput, error)
+	DescribeVpnConnections(ctx context.Context, params *ec2.DescribeVpnConnectionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpnConnectionsOutput, error)
+	DescribeDirectConnectGatewayAssociations(ctx context.Context, params *directconnect.DescribeDirectConnectGatewayAssociationsInput, optFns ...func(*directconnect.Options)) (*directconnect.DescribeDirectConnectGatewayAssociationsOutput, error)
+	DescribeTransitGatewayConnects(ctx context.Context, params *ec2.DescribeTransitGatewayConnectsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayConnectsOutput, error)
+	DescribeTransitGatewayConnectPeers(ctx context.Context, params *ec2.DescribeTransitGatewayConnectPeersInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayConnectPeersOutput, error)
+	DescribeTransitGatewayPolicyTables(ctx context.Context, params *ec2.DescribeTransitGatewayPolicyTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayPolicyTablesOutput, error)
+	GetTransitGatewayPolicyTableAssociations(ctx context.Context, params *ec2.GetTransitGatewayPolicyTableAssociationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayPolicyTableAssociationsOutput, error)
 }
 
 // TgwInputFilter returns a filter for the DescribeTransitGatewaysInput.
@@ -117,6 +126,133 @@ func GetTgwRouteTableAssociations(ctx context.Context, api AWSRouter, input *ec2
 	return api.GetTransitGatewayRouteTableAssociations(ctx, input)
 }
 
+// TgwRouteTablePropagationInputFilter returns the input to get the propagations of the Transit Gateway Route Table tgwRtID.
+// nextToken is the token of the page, empty for the first one.
+func TgwRouteTablePropagationInputFilter(tgwRtID, nextToken string, propagationFilters ...types.Filter) *ec2.GetTransitGatewayRouteTablePropagationsInput {
+	input := &ec2.GetTransitGatewayRouteTablePropagationsInput{
+		Filters:                    propagationFilters,
+		TransitGatewayRouteTableId: aws.String(tgwRtID),
+	}
+	if nextToken != "" {
+		input.NextToken = aws.String(nextToken)
+	}
+	return input
+}
+
+// GetTgwRouteTablePropagations returns the attachments that propagate routes to a Transit Gateway Route Table.
+func GetTgwRouteTablePropagations(ctx context.Context, api AWSRouter, input *ec2.GetTransitGatewayRouteTablePropagationsInput) (*ec2.GetTransitGatewayRouteTablePropagationsOutput, error) {
+	return api.GetTransitGatewayRouteTablePropagations(ctx, input)
+}
+
+// PrefixListInputFilter returns the input to describe the managed prefix lists with the IDs prefixListIDs.
+// nextToken is the token of the page, empty for the first one.
+func PrefixListInputFilter(prefixListIDs []string, nextToken string) *ec2.DescribeManagedPrefixListsInput {
+	input := &ec2.DescribeManagedPrefixListsInput{PrefixListIds: prefixListIDs}
+	if nextToken != "" {
+		input.NextToken = aws.String(nextToken)
+	}
+	return input
+}
+
+// GetPrefixLists returns the managed prefix lists, with their maximum number of entries.
+func GetPrefixLists(ctx context.Context, api AWSRouter, input *ec2.DescribeManagedPrefixListsInput) (*ec2.DescribeManagedPrefixListsOutput, error) {
+	return api.DescribeManagedPrefixLists(ctx, input)
+}
+
+// VpnConnectionInputFilter returns the input to describe the VPN connections attached to the Transit Gateway tgwID.
+func VpnConnectionInputFilter(tgwID string) *ec2.DescribeVpnConnectionsInput {
+	return &ec2.DescribeVpnConnectionsInput{
+		Filters: []types.Filter{{Name: aws.String("transit-gateway-id"), Values: []string{tgwID}}},
+	}
+}
+
+// GetVpnConnections returns the VPN connections, with the status of their tunnels.
+// DescribeVpnConnections is not paginated, all the VPN connections are in one response.
+func GetVpnConnections(ctx context.Context, api AWSRouter, input *ec2.DescribeVpnConnectionsInput) (*ec2.DescribeVpnConnectionsOutput, error) {
+	return api.DescribeVpnConnections(ctx, input)
+}
+
+// DirectConnectGatewayAssociationInputFilter returns the input to describe the associations of the Direct Connect gateways
+// with the Transit Gateway tgwID. nextToken is the token of the page, empty for the first one.
+func DirectConnectGatewayAssociationInputFilter(tgwID, nextToken string) *directconnect.DescribeDirectConnectGatewayAssociationsInput {
+	input := &directconnect.DescribeDirectConnectGatewayAssociationsInput{AssociatedGatewayId: aws.String(tgwID)}
+	if nextToken != "" {
+		input.NextToken = aws.String(nextToken)
+	}
+	return input
+}
+
+// GetDirectConnectGatewayAssociations returns the associations of Direct Connect gateways, with their allowed prefixes.
+func GetDirectConnectGatewayAssociations(ctx context.Context, api AWSRouter, input *directconnect.DescribeDirectConnectGatewayAssociationsInput) (*directconnect.DescribeDirectConnectGatewayAssociationsOutput, error) {
+	return api.DescribeDirectConnectGatewayAssociations(ctx, input)
+}
+
+// TgwConnectInputFilter returns the input to describe the Connect attachments of the Transit Gateway tgwID.
+// nextToken is the token of the page, empty for the first one.
+func TgwConnectInputFilter(tgwID, nextToken string) *ec2.DescribeTransitGatewayConnectsInput {
+	input := &ec2.DescribeTransitGatewayConnectsInput{
+		Filters: []types.Filter{{Name: aws.String("transit-gateway-id"), Values: []string{tgwID}}},
+	}
+	if nextToken != "" {
+		input.NextToken = aws.String(nextToken)
+	}
+	return input
+}
+
+// GetTgwConnects returns the Connect attachments, with the ID of their transport attachment.
+func GetTgwConnects(ctx context.Context, api AWSRouter, input *ec2.DescribeTransitGatewayConnectsInput) (*ec2.DescribeTransitGatewayConnectsOutput, error) {
+	return api.DescribeTransitGatewayConnects(ctx, input)
+}
+
+// TgwConnectPeerInputFilter returns the input to describe the Connect peers of the Connect attachments attachmentIDs,
+// the API can not filter the peers by Transit Gateway. nextToken is the token of the page, empty for the first one.
+func TgwConnectPeerInputFilter(attachmentIDs []string, nextToken string) *ec2.DescribeTransitGatewayConnectPeersInput {
+	input := &ec2.DescribeTransitGatewayConnectPeersInput{
+		Filters: []types.Filter{{Name: aws.String("transit-gateway-attachment-id"), Values: attachmentIDs}},
+	}
+	if nextToken != "" {
+		input.NextToken = aws.String(nextToken)
+	}
+	return input
+}
+
+// GetTgwConnectPeers returns the Connect peers, with the status of their BGP sessions.
+func GetTgwConnectPeers(ctx context.Context, api AWSRouter, input *ec2.DescribeTransitGatewayConnectPeersInput) (*ec2.DescribeTransitGatewayConnectPeersOutput, error) {
+	return api.DescribeTransitGatewayConnectPeers(ctx, input)
+}
+
+// TgwPolicyTableInputFilter returns the input to describe the policy tables of the Transit Gateway tgwID.
+// nextToken is the token of the page, empty for the first one.
+func TgwPolicyTableInputFilter(tgwID, nextToken string) *ec2.DescribeTransitGatewayPolicyTablesInput {
+	input := &ec2.DescribeTransitGatewayPolicyTablesInput{
+		Filters: []types.Filter{{Name: aws.String("transit-gateway-id"), Values: []string{tgwID}}},
+	}
+	if nextToken != "" {
+		input.NextToken = aws.String(nextToken)
+	}
+	return input
+}
+
+// GetTgwPolicyTables returns the policy tables, used by the Transit Gateways peered with AWS Cloud WAN.
+func GetTgwPolicyTables(ctx context.Context, api AWSRouter, input *ec2.DescribeTransitGatewayPolicyTablesInput) (*ec2.DescribeTransitGatewayPolicyTablesOutput, error) {
+	return api.DescribeTransitGatewayPolicyTables(ctx, input)
+}
+
+// TgwPolicyTableAssociationInputFilter returns the input to get the associations of the policy table policyTableID.
+// nextToken is the token of the page, empty for the first one.
+func TgwPolicyTableAssociationInputFilter(policyTableID, nextToken string) *ec2.GetTransitGatewayPolicyTableAssociationsInput {
+	input := &ec2.GetTransitGatewayPolicyTableAssociationsInput{TransitGatewayPolicyTableId: aws.String(policyTableID)}
+	if nextToken != "" {
+		input.NextToken = aws.String(nextToken)
+	}
+	return input
+}
+
+// GetTgwPolicyTableAssociations returns the attachments associated to a policy table.
+func GetTgwPolicyTableAssociations(ctx context.Context, api AWSRouter, input *ec2.GetTransitGatewayPolicyTableAssociationsInput) (*ec2.GetTransitGatewayPolicyTableAssociationsOutput, error) {
+	return api.GetTransitGatewayPolicyTableAssociations(ctx, input)
+}
+
 func TgwAttachmentInputFilter(attachmentFilters ...types.Filter) *ec2.DescribeTransitGatewayAttachmentsInput {
 	var filters []types.Filter
 	//default filter if no filters are provided
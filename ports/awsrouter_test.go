@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/service/directconnect"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go/aws"
@@ -269,6 +270,38 @@ func (t TgwDescriberImpl) DescribeTransitGatewayAttachments(ctx context.Context,
 	return nil, nil
 }
 
+func (t TgwDescriberImpl) GetTransitGatewayRouteTablePropagations(ctx context.Context, params *ec2.GetTransitGatewayRouteTablePropagationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayRouteTablePropagationsOutput, error) {
+	return &ec2.GetTransitGatewayRouteTablePropagationsOutput{}, nil
+}
+
+func (t TgwDescriberImpl) DescribeManagedPrefixLists(ctx context.Context, params *ec2.DescribeManagedPrefixListsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeManagedPrefixListsOutput, error) {
+	return &ec2.DescribeManagedPrefixListsOutput{}, nil
+}
+
+func (t TgwDescriberImpl) DescribeVpnConnections(ctx context.Context, params *ec2.DescribeVpnConnectionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpnConnectionsOutput, error) {
+	return &ec2.DescribeVpnConnectionsOutput{}, nil
+}
+
+func (t TgwDescriberImpl) DescribeDirectConnectGatewayAssociations(ctx context.Context, params *directconnect.DescribeDirectConnectGatewayAssociationsInput, optFns ...func(*directconnect.Options)) (*directconnect.DescribeDirectConnectGatewayAssociationsOutput, error) {
+	return &directconnect.DescribeDirectConnectGatewayAssociationsOutput{}, nil
+}
+
+func (t TgwDescriberImpl) DescribeTransitGatewayConnects(ctx context.Context, params *ec2.DescribeTransitGatewayConnectsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayConnectsOutput, error) {
+	return &ec2.DescribeTransitGatewayConnectsOutput{}, nil
+}
+
+func (t TgwDescriberImpl) DescribeTransitGatewayConnectPeers(ctx context.Context, params *ec2.DescribeTransitGatewayConnectPeersInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayConnectPeersOutput, error) {
+	return &ec2.DescribeTransitGatewayConnectPeersOutput{}, nil
+}
+
+func (t TgwDescriberImpl) DescribeTransitGatewayPolicyTables(ctx context.Context, params *ec2.DescribeTransitGatewayPolicyTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayPolicyTablesOutput, error) {
+	return &ec2.DescribeTransitGatewayPolicyTablesOutput{}, nil
+}
+
+func (t TgwDescriberImpl) GetTransitGatewayPolicyTableAssociations(ctx context.Context, params *ec2.GetTransitGatewayPolicyTableAssociationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayPolicyTableAssociationsOutput, error) {
+	return &ec2.GetTransitGatewayPolicyTableAssociationsOutput{}, nil
+}
+
 func TestGetTgw(t *testing.T) {
 	type args struct {
 		ctx   context.Context
@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/directconnect"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// Client is the AWSRouter of the AWS APIs: the EC2 calls are sent by the EC2 client
+// and the Direct Connect calls by the Direct Connect client.
+type Client struct {
+	*ec2.Client
+	DirectConnect *directconnect.Client
+}
+
+// NewClient returns a Client of the EC2 and Direct Connect clients.
+func NewClient(ec2Client *ec2.Client, dxClient *directconnect.Client) *Client {
+	return &Client{Client: ec2Client, DirectConnect: dxClient}
+}
+
+func (c *Client) DescribeDirectConnectGatewayAssociations(ctx context.Context, params *directconnect.DescribeDirectConnectGatewayAssociationsInput, optFns ...func(*directconnect.Options)) (*directconnect.DescribeDirectConnectGatewayAssociationsOutput, error) {
+	return c.DirectConnect.DescribeDirectConnectGatewayAssociations(ctx, params, optFns...)
+}
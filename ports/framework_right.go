@@ -1,5 +1,10 @@
 package ports
 
+import "errors"
+
+// ErrKeyNotFound is returned, maybe wrapped, by GetVal for a key that is not stored.
+var ErrKeyNotFound = errors.New("key not found")
+
 type DbPort interface {
 	CloseDbConnection()
 	GetVal(key string) ([]byte, error)
@@ -0,0 +1,115 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/directconnect"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// ObserveFunc receives the name of an AWS API call, how long it took and the error it returned.
+type ObserveFunc func(operation string, duration time.Duration, err error)
+
+// instrumentedRouter is an AWSRouter that reports every call of api to observe.
+type instrumentedRouter struct {
+	api     AWSRouter
+	observe ObserveFunc
+}
+
+// NewInstrumentedRouter returns an AWSRouter that calls api and reports each call to observe,
+// this is used to export the errors and latency of the AWS API.
+func NewInstrumentedRouter(api AWSRouter, observe ObserveFunc) AWSRouter {
+	return instrumentedRouter{api: api, observe: observe}
+}
+
+func (r instrumentedRouter) DescribeTransitGateways(ctx context.Context, params *ec2.DescribeTransitGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewaysOutput, error) {
+	start := time.Now()
+	output, err := r.api.DescribeTransitGateways(ctx, params, optFns...)
+	r.observe("DescribeTransitGateways", time.Since(start), err)
+	return output, err
+}
+
+func (r instrumentedRouter) DescribeTransitGatewayRouteTables(ctx context.Context, params *ec2.DescribeTransitGatewayRouteTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayRouteTablesOutput, error) {
+	start := time.Now()
+	output, err := r.api.DescribeTransitGatewayRouteTables(ctx, params, optFns...)
+	r.observe("DescribeTransitGatewayRouteTables", time.Since(start), err)
+	return output, err
+}
+
+func (r instrumentedRouter) SearchTransitGatewayRoutes(ctx context.Context, params *ec2.SearchTransitGatewayRoutesInput, optFns ...func(*ec2.Options)) (*ec2.SearchTransitGatewayRoutesOutput, error) {
+	start := time.Now()
+	output, err := r.api.SearchTransitGatewayRoutes(ctx, params, optFns...)
+	r.observe("SearchTransitGatewayRoutes", time.Since(start), err)
+	return output, err
+}
+
+func (r instrumentedRouter) GetTransitGatewayRouteTableAssociations(ctx context.Context, params *ec2.GetTransitGatewayRouteTableAssociationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayRouteTableAssociationsOutput, error) {
+	start := time.Now()
+	output, err := r.api.GetTransitGatewayRouteTableAssociations(ctx, params, optFns...)
+	r.observe("GetTransitGatewayRouteTableAssociations", time.Since(start), err)
+	return output, err
+}
+
+func (r instrumentedRouter) DescribeTransitGatewayAttachments(ctx context.Context, params *ec2.DescribeTransitGatewayAttachmentsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayAttachmentsOutput, error) {
+	start := time.Now()
+	output, err := r.api.DescribeTransitGatewayAttachments(ctx, params, optFns...)
+	r.observe("DescribeTransitGatewayAttachments", time.Since(start), err)
+	return output, err
+}
+
+func (r instrumentedRouter) GetTransitGatewayRouteTablePropagations(ctx context.Context, params *ec2.GetTransitGatewayRouteTablePropagationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayRouteTablePropagationsOutput, error) {
+	start := time.Now()
+	output, err := r.api.GetTransitGatewayRouteTablePropagations(ctx, params, optFns...)
+	r.observe("GetTransitGatewayRouteTablePropagations", time.Since(start), err)
+	return output, err
+}
+
+func (r instrumentedRouter) DescribeManagedPrefixLists(ctx context.Context, params *ec2.DescribeManagedPrefixListsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeManagedPrefixListsOutput, error) {
+	start := time.Now()
+	output, err := r.api.DescribeManagedPrefixLists(ctx, params, optFns...)
+	r.observe("DescribeManagedPrefixLists", time.Since(start), err)
+	return output, err
+}
+
+func (r instrumentedRouter) DescribeVpnConnections(ctx context.Context, params *ec2.DescribeVpnConnectionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpnConnectionsOutput, error) {
+	start := time.Now()
+	output, err := r.api.DescribeVpnConnections(ctx, params, optFns...)
+	r.observe("DescribeVpnConnections", time.Since(start), err)
+	return output, err
+}
+
+func (r instrumentedRouter) DescribeDirectConnectGatewayAssociations(ctx context.Context, params *directconnect.DescribeDirectConnectGatewayAssociationsInput, optFns ...func(*directconnect.Options)) (*directconnect.DescribeDirectConnectGatewayAssociationsOutput, error) {
+	start := time.Now()
+	output, err := r.api.DescribeDirectConnectGatewayAssociations(ctx, params, optFns...)
+	r.observe("DescribeDirectConnectGatewayAssociations", time.Since(start), err)
+	return output, err
+}
+
+func (r instrumentedRouter) DescribeTransitGatewayConnects(ctx context.Context, params *ec2.DescribeTransitGatewayConnectsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayConnectsOutput, error) {
+	start := time.Now()
+	output, err := r.api.DescribeTransitGatewayConnects(ctx, params, optFns...)
+	r.observe("DescribeTransitGatewayConnects", time.Since(start), err)
+	return output, err
+}
+
+func (r instrumentedRouter) DescribeTransitGatewayConnectPeers(ctx context.Context, params *ec2.DescribeTransitGatewayConnectPeersInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayConnectPeersOutput, error) {
+	start := time.Now()
+	output, err := r.api.DescribeTransitGatewayConnectPeers(ctx, params, optFns...)
+	r.observe("DescribeTransitGatewayConnectPeers", time.Since(start), err)
+	return output, err
+}
+
+func (r instrumentedRouter) DescribeTransitGatewayPolicyTables(ctx context.Context, params *ec2.DescribeTransitGatewayPolicyTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayPolicyTablesOutput, error) {
+	start := time.Now()
+	output, err := r.api.DescribeTransitGatewayPolicyTables(ctx, params, optFns...)
+	r.observe("DescribeTransitGatewayPolicyTables", time.Since(start), err)
+	return output, err
+}
+
+func (r instrumentedRouter) GetTransitGatewayPolicyTableAssociations(ctx context.Context, params *ec2.GetTransitGatewayPolicyTableAssociationsInput, optFns ...func(*ec2.Options)) (*ec2.GetTransitGatewayPolicyTableAssociationsOutput, error) {
+	start := time.Now()
+	output, err := r.api.GetTransitGatewayPolicyTableAssociations(ctx, params, optFns...)
+	r.observe("GetTransitGatewayPolicyTableAssociations", time.Since(start), err)
+	return output, err
+}
@@ -0,0 +1,34 @@
+package ports
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+func TestNewInstrumentedRouter(t *testing.T) {
+	var operations []string
+	api := NewInstrumentedRouter(TgwDescriberImpl{}, func(operation string, duration time.Duration, err error) {
+		if duration < 0 || err != nil {
+			t.Errorf("observe(%s) duration = %v, err = %v", operation, duration, err)
+		}
+		operations = append(operations, operation)
+	})
+	got, err := GetTgw(context.TODO(), api, &ec2.DescribeTransitGatewaysInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, listDescribeTransitGatewaysOutput) {
+		t.Errorf("GetTgw() = %v, want %v", got, listDescribeTransitGatewaysOutput)
+	}
+	if _, err := GetTgwRoutes(context.TODO(), api, TgwSearchRoutesInputFilter("rtb-0d7f9b0a")); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"DescribeTransitGateways", "SearchTransitGatewayRoutes"}
+	if !reflect.DeepEqual(operations, want) {
+		t.Errorf("observed operations = %v, want %v", operations, want)
+	}
+}